@@ -2,10 +2,13 @@ package main
 
 import (
 	"context"
+	"errors"
 	"flag"
 	"fmt"
+	"net/http"
 	"os"
 	"os/signal"
+	"path/filepath"
 	"strings"
 	"syscall"
 	"time"
@@ -13,7 +16,9 @@ import (
 	"notify/internal/app"
 	"notify/internal/config"
 	"notify/internal/logger"
+	"notify/internal/media"
 	"notify/internal/server"
+	"notify/internal/store"
 )
 
 var (
@@ -54,14 +59,45 @@ func main() {
 	// 初始化日志系统
 	logger.Init()
 
+	// 初始化运行数据存储，默认放在配置文件同级的 data 目录
+	dataDir := config.EnvCfg.DATA_DIR
+	if dataDir == "" {
+		dataDir = filepath.Join(filepath.Dir(actualConfigFile), "data")
+	}
+	dataStore, err := store.New(dataDir)
+	if err != nil {
+		logger.Fatal("初始化数据存储失败", "error", err)
+	}
+
+	// 初始化媒体存储，配置了公网地址时启用
+	mediaStore, err := media.New(filepath.Join(dataDir, "media"), config.EnvCfg.PUBLIC_BASE_URL, config.EnvCfg.MEDIA_TTL)
+	if err != nil {
+		logger.Fatal("初始化媒体存储失败", "error", err)
+	}
+	if mediaStore != nil {
+		mediaStore.Start(time.Hour)
+		defer mediaStore.Stop()
+	}
+
 	// 创建通知应用
-	notificationApp := app.NewNotificationApp(configManager)
+	notificationApp, err := app.NewNotificationApp(configManager, dataStore, mediaStore)
+	if err != nil {
+		logger.Fatal("创建通知应用失败", "error", err)
+	}
 
-	// 验证通知应用配置
+	// 验证通知应用配置，验证通过后再启动后台任务
 	if err := notificationApp.ValidateConfig(); err != nil {
 		logger.Fatal("通知应用配置验证失败", "error", err)
 	}
 
+	notificationApp.Start(time.Second)
+	defer notificationApp.Stop()
+
+	// 启动定时通知和定时任务调度，关闭时先停止调度再停止后台任务
+	scheduler := notificationApp.Scheduler()
+	scheduler.Start(time.Second)
+	defer scheduler.Stop()
+
 	// 记录环境变量认证状态
 	username := config.EnvCfg.NOTIFY_USERNAME
 	password := config.EnvCfg.NOTIFY_PASSWORD
@@ -85,7 +121,8 @@ func main() {
 
 	// 启动服务器
 	go func() {
-		if err := httpServer.Start(); err != nil {
+		// 正常关闭时返回 ErrServerClosed，不能退出进程，否则后台任务来不及停止
+		if err := httpServer.Start(); err != nil && !errors.Is(err, http.ErrServerClosed) {
 			logger.Fatal("启动HTTP服务器失败", "error", err)
 		}
 	}()
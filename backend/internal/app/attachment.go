@@ -0,0 +1,110 @@
+package app
+
+import (
+	"encoding/base64"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"notify/internal/notifier"
+	"notify/internal/utils"
+)
+
+// parseAttachments 从请求数据的 attachments 字段解析附件
+// 每一项可以是 URL 字符串、data URI，或包含 name、contentType、url、base64 的对象
+func parseAttachments(data map[string]any) ([]notifier.Attachment, error) {
+	items, ok := data["attachments"].([]any)
+	if !ok {
+		if item, exists := data["attachments"]; exists && item != nil && item != "" {
+			items = []any{item}
+		}
+	}
+
+	attachments := make([]notifier.Attachment, 0, len(items))
+	for i, item := range items {
+		var attachment notifier.Attachment
+		var encoded string
+		switch v := item.(type) {
+		case string:
+			if strings.HasPrefix(v, "data:") {
+				encoded = v
+			} else {
+				attachment.URL = v
+			}
+		case map[string]any:
+			attachment.Name = utils.GetString(v, "name")
+			attachment.ContentType = utils.FirstNonEmpty(utils.GetString(v, "contentType"), utils.GetString(v, "content_type"))
+			attachment.URL = utils.GetString(v, "url")
+			encoded = utils.FirstNonEmpty(utils.GetString(v, "base64"), utils.GetString(v, "data"))
+		default:
+			return nil, fmt.Errorf("附件 %d 格式无效", i+1)
+		}
+
+		if encoded != "" {
+			content, contentType, err := decodeBase64Attachment(encoded)
+			if err != nil {
+				return nil, fmt.Errorf("附件 %d 解码失败: %w", i+1, err)
+			}
+			if len(content) > notifier.MaxAttachmentSize {
+				return nil, fmt.Errorf("附件 %d 超过 %d MB", i+1, notifier.MaxAttachmentSize>>20)
+			}
+			attachment.Data = content
+			attachment.Size = len(content)
+			if attachment.ContentType == "" {
+				attachment.ContentType = contentType
+			}
+		}
+		if attachment.Data == nil && attachment.URL == "" {
+			return nil, fmt.Errorf("附件 %d 缺少 url 或 base64 内容", i+1)
+		}
+		attachments = append(attachments, attachment)
+	}
+	return attachments, nil
+}
+
+// parseActions 从请求数据的 actions 字段解析按钮，每一项包含 label、url 和可选的 style
+func parseActions(data map[string]any) []notifier.Action {
+	items, _ := data["actions"].([]any)
+	actions := make([]notifier.Action, 0, len(items))
+	for _, item := range items {
+		v, ok := item.(map[string]any)
+		if !ok {
+			continue
+		}
+		action := notifier.Action{
+			Label: utils.FirstNonEmpty(utils.GetString(v, "label"), utils.GetString(v, "title")),
+			URL:   utils.GetString(v, "url"),
+			Style: utils.GetString(v, "style"),
+		}
+		if action.Label != "" && action.URL != "" {
+			actions = append(actions, action)
+		}
+	}
+	return actions
+}
+
+// decodeBase64Attachment 解码 base64 或 data URI，返回内容和类型
+func decodeBase64Attachment(encoded string) ([]byte, string, error) {
+	contentType := ""
+	if strings.HasPrefix(encoded, "data:") {
+		meta, payload, found := strings.Cut(strings.TrimPrefix(encoded, "data:"), ",")
+		if !found || !strings.HasSuffix(meta, ";base64") {
+			return nil, "", fmt.Errorf("只支持 base64 编码的 data URI")
+		}
+		contentType = strings.TrimSuffix(meta, ";base64")
+		encoded = payload
+	}
+
+	encoded = strings.TrimSpace(encoded)
+	content, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil {
+		content, err = base64.RawStdEncoding.DecodeString(strings.TrimRight(encoded, "="))
+		if err != nil {
+			return nil, "", err
+		}
+	}
+	if contentType == "" {
+		contentType = http.DetectContentType(content)
+	}
+	return content, contentType, nil
+}
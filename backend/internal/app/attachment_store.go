@@ -0,0 +1,149 @@
+package app
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"time"
+
+	"notify/internal/logger"
+	"notify/internal/notifier"
+)
+
+const (
+	// attachmentDirName 持久化消息的附件内容在数据目录中的子目录
+	attachmentDirName = "attachments"
+	// attachmentCleanupInterval 清理不再被引用的附件文件的间隔
+	attachmentCleanupInterval = time.Hour
+	// attachmentCleanupGrace 附件文件写入后至少保留的时间，避免清理时删除正在保存的消息的附件
+	attachmentCleanupGrace = time.Hour
+)
+
+// attachmentNamePattern 附件文件名格式：内容的 sha256 哈希
+var attachmentNamePattern = regexp.MustCompile(`^[0-9a-f]{64}$`)
+
+// attachmentDir 返回附件目录
+func (app *NotificationApp) attachmentDir() string {
+	return filepath.Join(app.store.Dir(), attachmentDirName)
+}
+
+// persistAttachments 把需要持久化的消息的附件内容保存到数据目录并记录文件名
+// 消息按 JSON 保存时不包含附件内容，重启后由 restoreAttachments 读取
+func (app *NotificationApp) persistAttachments(message *notifier.NotificationMessage) {
+	if message == nil {
+		return
+	}
+	for i := range message.Attachments {
+		attachment := &message.Attachments[i]
+		if attachment.Data == nil || attachment.Stored != "" {
+			continue
+		}
+		name, err := app.writeAttachment(attachment.Data)
+		if err != nil {
+			logger.Error("保存附件失败，重启后附件将丢失", "name", attachment.FileName(), "error", err)
+			continue
+		}
+		attachment.Stored = name
+	}
+}
+
+// writeAttachment 按内容哈希保存附件，相同内容只保存一份并刷新修改时间
+func (app *NotificationApp) writeAttachment(data []byte) (string, error) {
+	dir := app.attachmentDir()
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return "", fmt.Errorf("创建附件目录失败: %w", err)
+	}
+	sum := sha256.Sum256(data)
+	name := hex.EncodeToString(sum[:])
+	path := filepath.Join(dir, name)
+
+	now := time.Now()
+	if _, err := os.Stat(path); err == nil {
+		return name, os.Chtimes(path, now, now)
+	}
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0644); err != nil {
+		return "", fmt.Errorf("写入附件失败: %w", err)
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		return "", fmt.Errorf("写入附件失败: %w", err)
+	}
+	return name, nil
+}
+
+// restoreAttachments 从数据目录读取恢复的消息的附件内容
+func (app *NotificationApp) restoreAttachments(message *notifier.NotificationMessage) {
+	if message == nil {
+		return
+	}
+	for i := range message.Attachments {
+		attachment := &message.Attachments[i]
+		if attachment.Data != nil || !attachmentNamePattern.MatchString(attachment.Stored) {
+			continue
+		}
+		data, err := os.ReadFile(filepath.Join(app.attachmentDir(), attachment.Stored))
+		if err != nil {
+			logger.Error("读取附件失败", "name", attachment.FileName(), "error", err)
+			continue
+		}
+		attachment.Data = data
+	}
+}
+
+// cleanupAttachments 删除去重、升级、分组和暂存消息都不再引用的附件文件
+func (app *NotificationApp) cleanupAttachments(now time.Time) {
+	entries, err := os.ReadDir(app.attachmentDir())
+	if err != nil {
+		if !os.IsNotExist(err) {
+			logger.Error("读取附件目录失败", "error", err)
+		}
+		return
+	}
+
+	referenced := make(map[string]bool)
+	collect := func(message *notifier.NotificationMessage) {
+		if message == nil {
+			return
+		}
+		for _, attachment := range message.Attachments {
+			if attachment.Stored != "" {
+				referenced[attachment.Stored] = true
+			}
+		}
+	}
+	app.dedupMu.Lock()
+	for _, entry := range app.dedup {
+		collect(entry.Message)
+	}
+	app.dedupMu.Unlock()
+	app.escalationMu.Lock()
+	for _, escalation := range app.escalations {
+		collect(escalation.Message)
+	}
+	app.escalationMu.Unlock()
+	app.groupMu.Lock()
+	for _, group := range app.groups {
+		for _, message := range group.Messages {
+			collect(message)
+		}
+	}
+	app.groupMu.Unlock()
+	app.deferredMu.Lock()
+	for _, entry := range app.deferred {
+		collect(entry.Message)
+	}
+	app.deferredMu.Unlock()
+
+	for _, entry := range entries {
+		info, err := entry.Info()
+		if err != nil || entry.IsDir() || referenced[entry.Name()] || now.Sub(info.ModTime()) < attachmentCleanupGrace {
+			continue
+		}
+		if err := os.Remove(filepath.Join(app.attachmentDir(), entry.Name())); err != nil {
+			logger.Error("删除附件失败", "name", entry.Name(), "error", err)
+		}
+	}
+}
@@ -0,0 +1,218 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"notify/internal/config"
+	"notify/internal/logger"
+)
+
+// 熔断器状态
+const (
+	CircuitClosed   = "closed"    // 正常发送
+	CircuitOpen     = "open"      // 失败过多，直接返回错误
+	CircuitHalfOpen = "half-open" // 冷却结束，放行一次试探发送
+)
+
+// errCircuitOpen 熔断期间发送返回的错误
+var errCircuitOpen = errors.New("熔断中，跳过发送")
+
+// CircuitStatus 通知服务的熔断状态和最近的发送结果
+type CircuitStatus struct {
+	State         string     `json:"state"`
+	Failures      int        `json:"failures"`  // 统计窗口内的失败次数
+	Successes     int        `json:"successes"` // 统计窗口内的成功次数
+	LastError     string     `json:"lastError,omitempty"`
+	LastErrorAt   *time.Time `json:"lastErrorAt,omitempty"`
+	LastSuccessAt *time.Time `json:"lastSuccessAt,omitempty"`
+	OpenedAt      *time.Time `json:"openedAt,omitempty"`
+	RetryAt       *time.Time `json:"retryAt,omitempty"` // 熔断后下一次试探的时间
+}
+
+// sendOutcome 一次发送的结果
+type sendOutcome struct {
+	at time.Time
+	ok bool
+}
+
+// circuitBreaker 单个通知服务的熔断器，统计窗口内失败次数达到阈值且多于成功次数时熔断
+// 熔断后经过冷却时间进入半开状态，放行一次发送试探，成功后恢复，失败后重新熔断
+type circuitBreaker struct {
+	mu       sync.Mutex
+	state    string
+	outcomes []sendOutcome // 统计窗口内的发送结果，按时间升序
+	probing  bool          // 半开状态下是否已有试探中的发送
+	status   CircuitStatus
+}
+
+// breakerSettings 返回熔断阈值、统计窗口和冷却时间，阈值为 0 表示不熔断
+func breakerSettings() (int, time.Duration, time.Duration) {
+	return config.EnvCfg.CIRCUIT_BREAKER_THRESHOLD, config.EnvCfg.CIRCUIT_BREAKER_WINDOW, config.EnvCfg.CIRCUIT_BREAKER_COOLDOWN
+}
+
+// breaker 返回通知服务的熔断器，不存在时创建
+func (app *NotificationApp) breaker(name string) *circuitBreaker {
+	app.breakerMu.Lock()
+	defer app.breakerMu.Unlock()
+
+	breaker, exists := app.breakers[name]
+	if !exists {
+		breaker = &circuitBreaker{state: CircuitClosed}
+		app.breakers[name] = breaker
+	}
+	return breaker
+}
+
+// allow 判断是否可以发送，熔断期间返回错误，冷却结束后只放行一次试探
+func (b *circuitBreaker) allow(now time.Time) error {
+	threshold, _, cooldown := breakerSettings()
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if threshold <= 0 {
+		return nil
+	}
+	switch b.state {
+	case CircuitOpen:
+		if b.status.OpenedAt != nil && now.Before(b.status.OpenedAt.Add(cooldown)) {
+			return errCircuitOpen
+		}
+		b.state = CircuitHalfOpen
+		b.probing = true
+		return nil
+	case CircuitHalfOpen:
+		if b.probing {
+			return errCircuitOpen
+		}
+		b.probing = true
+	}
+	return nil
+}
+
+// record 记录一次发送结果，调用方取消的发送只结束试探，不计入统计
+func (b *circuitBreaker) record(ctx context.Context, name string, err error, now time.Time) {
+	threshold, window, cooldown := breakerSettings()
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	wasProbing := b.probing
+	b.probing = false
+	if err != nil && ctx.Err() != nil {
+		return
+	}
+
+	if err == nil {
+		b.status.LastSuccessAt = &now
+	} else {
+		b.status.LastError = err.Error()
+		b.status.LastErrorAt = &now
+	}
+	b.outcomes = append(b.outcomes, sendOutcome{at: now, ok: err == nil})
+	b.trim(now, window)
+
+	switch {
+	case threshold <= 0:
+		b.state = CircuitClosed
+	case b.state == CircuitHalfOpen && wasProbing:
+		if err == nil {
+			logger.Info("通知服务试探发送成功，熔断恢复", "notifier", name)
+			b.state = CircuitClosed
+			b.outcomes = nil
+			b.status.OpenedAt = nil
+			b.status.RetryAt = nil
+		} else {
+			logger.Warn("通知服务试探发送失败，继续熔断", "notifier", name, "error", err)
+			b.open(now, cooldown)
+		}
+	case b.state == CircuitClosed && err != nil:
+		failures, successes := b.counts()
+		if failures >= threshold && failures > successes {
+			logger.Warn("通知服务失败次数过多，开始熔断", "notifier", name, "failures", failures, "cooldown", cooldown)
+			b.open(now, cooldown)
+		}
+	}
+}
+
+// open 进入熔断状态，调用方需要持有 mu
+func (b *circuitBreaker) open(now time.Time, cooldown time.Duration) {
+	retryAt := now.Add(cooldown)
+	b.state = CircuitOpen
+	b.status.OpenedAt = &now
+	b.status.RetryAt = &retryAt
+}
+
+// trim 删除统计窗口之前的发送结果，调用方需要持有 mu
+func (b *circuitBreaker) trim(now time.Time, window time.Duration) {
+	i := 0
+	for i < len(b.outcomes) && now.Sub(b.outcomes[i].at) > window {
+		i++
+	}
+	b.outcomes = b.outcomes[i:]
+}
+
+// counts 返回统计窗口内的失败和成功次数，调用方需要持有 mu
+func (b *circuitBreaker) counts() (int, int) {
+	failures, successes := 0, 0
+	for _, outcome := range b.outcomes {
+		if outcome.ok {
+			successes++
+		} else {
+			failures++
+		}
+	}
+	return failures, successes
+}
+
+// snapshot 返回熔断器当前状态
+func (b *circuitBreaker) snapshot(now time.Time) CircuitStatus {
+	_, window, _ := breakerSettings()
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.trim(now, window)
+	status := b.status
+	status.State = b.state
+	status.Failures, status.Successes = b.counts()
+	return status
+}
+
+// CircuitStatuses 返回所有已配置通知服务的熔断状态，还没有发送过的通知服务为 closed
+func (app *NotificationApp) CircuitStatuses() map[string]CircuitStatus {
+	now := time.Now()
+	notifiers := app.configManager.GetConfig().Notifiers
+	statuses := make(map[string]CircuitStatus, len(notifiers))
+	for name := range notifiers {
+		statuses[name] = app.breaker(name).snapshot(now)
+	}
+	return statuses
+}
+
+// sendWithBreaker 经过熔断器发送，熔断期间直接返回错误
+func (app *NotificationApp) sendWithBreaker(ctx context.Context, name string, send func() error) error {
+	breaker := app.breaker(name)
+	if err := breaker.allow(time.Now()); err != nil {
+		return err
+	}
+	err := redactRequestURL(send())
+	breaker.record(ctx, name, err, time.Now())
+	return err
+}
+
+// redactRequestURL 去掉发送错误中的请求地址，webhook 的 key、钉钉的 access_token 和 Telegram 的机器人 token 都在地址中
+func redactRequestURL(err error) error {
+	var urlErr *url.Error
+	if err == nil || !errors.As(err, &urlErr) {
+		return err
+	}
+	prefix := ""
+	if i := strings.Index(err.Error(), urlErr.Error()); i > 0 {
+		prefix = err.Error()[:i]
+	}
+	return fmt.Errorf("%s%s: %w", prefix, urlErr.Op, urlErr.Err)
+}
@@ -0,0 +1,92 @@
+package app
+
+import (
+	"notify/internal/config"
+	"notify/internal/logger"
+)
+
+// resolveTargets 把发送目标中的 user:、group: 引用解析为通知服务类型对应的身份，其他目标原样保留
+// 联系人组优先使用组自身的身份（如群聊 ID），未配置时展开为成员；没有对应身份的联系人会被忽略
+func (app *NotificationApp) resolveTargets(notifierType config.NotifiersType, targets []string) []string {
+	cfg := app.configManager.GetConfig()
+	return resolveContactRefs(cfg, targets, func(prefix, id string) []string {
+		if prefix == config.ContactGroupPrefix {
+			group := cfg.ContactGroups[id]
+			if channel := group.Channel(notifierType); channel != "" {
+				return []string{channel}
+			}
+			var ids []string
+			for _, member := range group.Members {
+				ids = append(ids, contactTarget(cfg.Contacts[member], notifierType))
+			}
+			return ids
+		}
+		return []string{contactTarget(cfg.Contacts[id], notifierType)}
+	})
+}
+
+// resolveMentions 把提及对象中的 user:、group: 引用解析为通知服务类型对应的身份，联系人组展开为成员
+func (app *NotificationApp) resolveMentions(notifierType config.NotifiersType, mentions []string) []string {
+	cfg := app.configManager.GetConfig()
+	return resolveContactRefs(cfg, mentions, func(prefix, id string) []string {
+		members := []string{id}
+		if prefix == config.ContactGroupPrefix {
+			members = cfg.ContactGroups[id].Members
+		}
+		var ids []string
+		for _, member := range members {
+			contact := cfg.Contacts[member]
+			mention := contact.Channel(notifierType)
+			// 钉钉和企业微信群机器人支持按手机号提及
+			if mention == "" && (notifierType == config.DingTalkAppBot || notifierType == config.WechatWorkWebhookBot) {
+				mention = contact.Mobile
+			}
+			ids = append(ids, mention)
+		}
+		return ids
+	})
+}
+
+// contactTarget 返回联系人作为发送目标时的身份，钉钉未配置时使用手机号，飞书未配置时使用邮箱
+func contactTarget(contact config.Contact, notifierType config.NotifiersType) string {
+	if channel := contact.Channel(notifierType); channel != "" {
+		return channel
+	}
+	switch notifierType {
+	case config.DingTalkAppBot:
+		return contact.Mobile
+	case config.FeishuAppBot:
+		return contact.Email
+	}
+	return ""
+}
+
+// resolveContactRefs 展开列表中的联系人引用并去重，不存在的引用会被忽略并记录日志
+func resolveContactRefs(cfg *config.Config, values []string, expand func(prefix, id string) []string) []string {
+	seen := make(map[string]bool, len(values))
+	result := make([]string, 0, len(values))
+	add := func(value string) {
+		if value != "" && !seen[value] {
+			seen[value] = true
+			result = append(result, value)
+		}
+	}
+
+	for _, value := range values {
+		prefix, id := config.ContactRef(value)
+		if prefix == "" {
+			add(value)
+			continue
+		}
+		_, userExists := cfg.Contacts[id]
+		_, groupExists := cfg.ContactGroups[id]
+		if (prefix == config.ContactUserPrefix && !userExists) || (prefix == config.ContactGroupPrefix && !groupExists) {
+			logger.Warn("联系人不存在", "ref", value)
+			continue
+		}
+		for _, resolved := range expand(prefix, id) {
+			add(resolved)
+		}
+	}
+	return result
+}
@@ -0,0 +1,160 @@
+package app
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"strings"
+	"time"
+
+	"notify/internal/config"
+	"notify/internal/logger"
+	"notify/internal/notifier"
+)
+
+// dedupStoreName 去重状态在数据目录中的文件名
+const dedupStoreName = "dedup"
+
+// DedupEntry 一个指纹的去重窗口
+type DedupEntry struct {
+	AppID       string                        `json:"appId"`
+	Fingerprint string                        `json:"fingerprint"`
+	Route       Route                         `json:"route"`
+	Targets     []string                      `json:"targets,omitempty"`
+	Message     *notifier.NotificationMessage `json:"message"` // 窗口内最近一条消息，窗口结束时用于发送重复次数
+	FirstAt     time.Time                     `json:"firstAt"`
+	WindowEnd   time.Time                     `json:"windowEnd"`
+	Repeats     int                           `json:"repeats"` // 窗口内被抑制的次数
+}
+
+// loadDedup 从数据目录恢复去重状态
+func (app *NotificationApp) loadDedup() {
+	app.dedup = make(map[string]*DedupEntry)
+	if err := app.store.Load(dedupStoreName, &app.dedup); err != nil {
+		logger.Error("读取去重状态失败", "error", err)
+		app.dedup = make(map[string]*DedupEntry)
+	}
+	for _, entry := range app.dedup {
+		app.restoreAttachments(entry.Message)
+	}
+}
+
+// saveDedup 保存去重状态，调用方需要持有 dedupMu
+func (app *NotificationApp) saveDedup() {
+	if err := app.store.Save(dedupStoreName, app.dedup); err != nil {
+		logger.Error("保存去重状态失败", "error", err)
+	}
+}
+
+// fingerprint 计算消息指纹，未配置指纹模板或渲染结果为空时使用标题、内容和发送目标
+func (app *NotificationApp) fingerprint(appConfig config.NotificationApp, message *notifier.NotificationMessage, targets []string, req *map[string]any) string {
+	if tpl := appConfig.Dedup.Fingerprint; tpl != "" {
+		value, err := app.renderTemplate(appConfig.AppID+"_fingerprint", tpl, req)
+		if err != nil {
+			logger.Warn("渲染去重指纹失败，使用消息内容作为指纹", "app", appConfig.AppID, "error", err)
+		} else if value = strings.TrimSpace(value); value != "" {
+			return value
+		}
+	}
+	sum := sha256.Sum256([]byte(strings.Join([]string{message.Title, message.Content, strings.Join(targets, ",")}, "\n")))
+	return hex.EncodeToString(sum[:8])
+}
+
+// suppressDuplicate 判断消息是否在去重窗口内，是则计数并返回 true
+// 窗口已结束但还没来得及发送重复次数时，把次数合并到这条消息的标题中
+func (app *NotificationApp) suppressDuplicate(appConfig config.NotificationApp, route Route, message *notifier.NotificationMessage, targets []string, req *map[string]any) bool {
+	if appConfig.Dedup == nil {
+		return false
+	}
+	window, err := appConfig.Dedup.WindowDuration()
+	if err != nil {
+		logger.Warn("去重配置无效，不做去重", "app", appConfig.AppID, "error", err)
+		return false
+	}
+
+	fingerprint := app.fingerprint(appConfig, message, targets, req)
+	key := strings.Join([]string{appConfig.AppID, route.Rule, route.TemplateID, fingerprint}, "\n")
+	now := time.Now()
+
+	app.dedupMu.Lock()
+	defer app.dedupMu.Unlock()
+
+	entry, exists := app.dedup[key]
+	if exists && now.Before(entry.WindowEnd) {
+		entry.Repeats++
+		entry.Message = message.Clone()
+		app.persistAttachments(entry.Message)
+		app.saveDedup()
+		logger.Debug("消息在去重窗口内，跳过发送", "app", appConfig.AppID, "fingerprint", fingerprint, "repeats", entry.Repeats)
+		return true
+	}
+	app.dedup[key] = &DedupEntry{
+		AppID:       appConfig.AppID,
+		Fingerprint: fingerprint,
+		Route:       route,
+		Targets:     targets,
+		Message:     message.Clone(),
+		FirstAt:     now,
+		WindowEnd:   now.Add(window),
+	}
+	app.persistAttachments(app.dedup[key].Message)
+	app.saveDedup()
+	if exists && entry.Repeats > 0 {
+		message.Title = fmt.Sprintf("[重复 %d 次] %s", entry.Repeats, message.Title)
+	}
+	return false
+}
+
+// processDedup 处理结束的去重窗口，窗口内有重复时发送带重复次数的消息并开始新的窗口
+func (app *NotificationApp) processDedup(now time.Time) {
+	cfg := app.configManager.GetConfig()
+
+	type repeat struct {
+		appConfig config.NotificationApp
+		entry     DedupEntry
+	}
+	var repeats []repeat
+
+	app.dedupMu.Lock()
+	changed := false
+	for key, entry := range app.dedup {
+		if now.Before(entry.WindowEnd) {
+			continue
+		}
+		changed = true
+		appConfig, exists := cfg.NotificationApps[entry.AppID]
+		if entry.Repeats == 0 || !exists {
+			delete(app.dedup, key)
+			continue
+		}
+		repeats = append(repeats, repeat{appConfig: appConfig, entry: *entry})
+		window := entry.WindowEnd.Sub(entry.FirstAt)
+		if appConfig.Dedup != nil {
+			if duration, err := appConfig.Dedup.WindowDuration(); err == nil {
+				window = duration
+			}
+		}
+		entry.FirstAt = now
+		entry.WindowEnd = now.Add(window)
+		entry.Repeats = 0
+	}
+	if changed {
+		app.saveDedup()
+	}
+	app.dedupMu.Unlock()
+
+	for _, r := range repeats {
+		message := r.entry.Message.Clone()
+		message.Title = fmt.Sprintf("[重复 %d 次] %s", r.entry.Repeats, message.Title)
+		message.Content = fmt.Sprintf("%s\n\n%s 至 %s 期间重复 %d 次", message.Content,
+			r.entry.FirstAt.Format("2006-01-02 15:04:05"), r.entry.WindowEnd.Format("2006-01-02 15:04:05"), r.entry.Repeats)
+		message.Timestamp = now.Format("2006-01-02 15:04:05")
+
+		ctx, cancel := context.WithTimeout(context.Background(), backgroundSendTimeout)
+		if _, err := app.dispatch(ctx, r.appConfig, r.entry.Route, message, r.entry.Targets); err != nil {
+			logger.Error("发送重复次数失败", "app", r.entry.AppID, "fingerprint", r.entry.Fingerprint, "error", err)
+		}
+		cancel()
+	}
+}
@@ -0,0 +1,250 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+
+	"notify/internal/config"
+	"notify/internal/notifier"
+)
+
+// 单个通知服务的投递状态
+const (
+	DeliverySent    = "sent"    // 发送成功
+	DeliveryFailed  = "failed"  // 发送失败
+	DeliverySkipped = "skipped" // 按投递策略或免打扰未发送
+)
+
+// 通知服务在 fallback 策略中的角色
+const (
+	RolePrimary  = "primary"
+	RoleFallback = "fallback"
+)
+
+// maxConcurrentNotifiers 同一条消息最多同时发送的通知服务数
+const maxConcurrentNotifiers = 10
+
+// NotifierResult 单个通知服务的投递结果，记录按投递策略实际走过的路径
+type NotifierResult struct {
+	MessageID string `json:"messageId,omitempty"`
+	Notifier  string `json:"notifier"`
+	Group     string `json:"group,omitempty"`    // 所在的通知服务组
+	Strategy  string `json:"strategy,omitempty"` // 组的投递策略
+	Role      string `json:"role,omitempty"`     // fallback 策略下为 primary 或 fallback
+	Status    string `json:"status"`
+	Error     string `json:"error,omitempty"` // 失败或跳过的原因
+}
+
+// deliveryUnit 一次投递中独立执行的单元，未分组的通知服务按 all 策略单独成为一个单元
+type deliveryUnit struct {
+	group    string
+	strategy string
+	primary  []string
+	fallback []string
+}
+
+// deliveryUnits 把路由中的通知服务和组名展开为投递单元
+func deliveryUnits(appConfig config.NotificationApp, names []string) []deliveryUnit {
+	units := make([]deliveryUnit, 0, len(names))
+	for _, name := range names {
+		if group, exists := appConfig.NotifierGroups[name]; exists {
+			units = append(units, deliveryUnit{
+				group:    name,
+				strategy: group.EffectiveStrategy(),
+				primary:  group.Notifiers,
+				fallback: group.Fallback,
+			})
+			continue
+		}
+		units = append(units, deliveryUnit{strategy: config.DeliveryAll, primary: []string{name}})
+	}
+	return units
+}
+
+// delivery 一次投递共享的消息、目标和并发限制
+type delivery struct {
+	app       *NotificationApp
+	appConfig config.NotificationApp
+	route     Route
+	message   *notifier.NotificationMessage
+	targets   []string
+	semaphore chan struct{}
+
+	mu     sync.Mutex
+	silent map[string]bool // 免打扰期间需要静默发送的通知服务
+}
+
+// newDelivery 创建一次投递
+func (app *NotificationApp) newDelivery(appConfig config.NotificationApp, route Route, message *notifier.NotificationMessage, targets []string) *delivery {
+	return &delivery{
+		app:       app,
+		appConfig: appConfig,
+		route:     route,
+		message:   message,
+		targets:   targets,
+		semaphore: make(chan struct{}, maxConcurrentNotifiers),
+		silent:    make(map[string]bool),
+	}
+}
+
+// quiet 按免打扰策略处理通知服务，返回需要立即发送的通知服务，暂存或丢弃的通知服务视为已处理
+// release 为通知服务全部被暂存时免打扰结束后重新投递的单元
+func (d *delivery) quiet(release deliveryUnit, names []string) []string {
+	route := d.route
+	route.Notifiers = names
+	send, silent := d.app.applyQuietHours(d.appConfig, route, d.message, d.targets, release)
+	d.mu.Lock()
+	for name := range silent {
+		d.silent[name] = true
+	}
+	d.mu.Unlock()
+	return send
+}
+
+// run 按单元的投递策略发送，返回每个通知服务的结果
+// all 策略下任一通知服务失败即返回错误，其他策略只在没有任何通知服务发送成功时返回错误
+func (d *delivery) run(ctx context.Context, unit deliveryUnit) ([]NotifierResult, error) {
+	var (
+		results []NotifierResult
+		errs    []string
+		sent    int
+	)
+	record := func(name, role, status, reason string) {
+		result := NotifierResult{MessageID: d.message.ID, Notifier: name, Role: role, Status: status, Error: reason}
+		if unit.group != "" {
+			result.Group, result.Strategy = unit.group, unit.strategy
+		}
+		results = append(results, result)
+	}
+	// 处于免打扰的通知服务由免打扰策略暂存或丢弃，不参与本次投递
+	quiet := func(release deliveryUnit, names []string, role string) []string {
+		send := d.quiet(release, names)
+		allowed := make(map[string]bool, len(send))
+		for _, name := range send {
+			allowed[name] = true
+		}
+		for _, name := range names {
+			if !allowed[name] {
+				record(name, role, DeliverySkipped, "免打扰")
+			}
+		}
+		return send
+	}
+	skip := func(names []string, role, reason string) {
+		for _, name := range names {
+			record(name, role, DeliverySkipped, reason)
+		}
+	}
+	sendAll := func(names []string, role string) {
+		for i, err := range d.sendAll(ctx, names) {
+			if err != nil {
+				record(names[i], role, DeliveryFailed, err.Error())
+				errs = append(errs, err.Error())
+				continue
+			}
+			record(names[i], role, DeliverySent, "")
+			sent++
+		}
+	}
+
+	switch unit.strategy {
+	case config.DeliveryFirstSuccess:
+		for i, name := range unit.primary {
+			// 被暂存时免打扰结束后从该通知服务开始继续按顺序尝试
+			remaining := deliveryUnit{group: unit.group, strategy: unit.strategy, primary: unit.primary[i:]}
+			if len(quiet(remaining, []string{name}, "")) == 0 {
+				skip(unit.primary[i+1:], "", fmt.Sprintf("%s 处于免打扰", name))
+				return results, nil
+			}
+			sendAll([]string{name}, "")
+			if sent > 0 {
+				skip(unit.primary[i+1:], "", fmt.Sprintf("已由 %s 发送成功", name))
+				return results, nil
+			}
+		}
+	case config.DeliveryFallback:
+		primary := quiet(unit, unit.primary, RolePrimary)
+		sendAll(primary, RolePrimary)
+		switch {
+		case sent > 0:
+			skip(unit.fallback, RoleFallback, "主通知服务发送成功")
+			return results, nil
+		case len(primary) == 0:
+			// 主通知服务全部处于免打扰，由免打扰策略暂存或丢弃，不转移到备用通知服务
+			skip(unit.fallback, RoleFallback, "主通知服务处于免打扰")
+			return results, nil
+		}
+		// 部分主通知服务处于免打扰，其余发送失败时仍然转移，失败的错误随结果返回
+		sendAll(quiet(deliveryUnit{strategy: config.DeliveryAll}, unit.fallback, RoleFallback), RoleFallback)
+		if sent > 0 {
+			return results, nil
+		}
+	default:
+		sendAll(quiet(unit, unit.primary, ""), "")
+	}
+	if len(errs) == 0 {
+		return results, nil
+	}
+	return results, fmt.Errorf("%s", strings.Join(errs, "\n "))
+}
+
+// sendAll 并发发送到多个通知服务，按顺序返回每个通知服务的错误
+func (d *delivery) sendAll(ctx context.Context, names []string) []error {
+	errs := make([]error, len(names))
+	var wg sync.WaitGroup
+	for i, name := range names {
+		wg.Add(1)
+		go func(i int, name string) {
+			defer wg.Done()
+			errs[i] = d.send(ctx, name)
+		}(i, name)
+	}
+	wg.Wait()
+	return errs
+}
+
+// send 发送到单个通知服务，每个通知服务使用消息副本，避免并发下载附件时互相影响
+func (d *delivery) send(ctx context.Context, name string) error {
+	// 提前检查通知服务是否存在和启用
+	instance, exists := d.app.notifiers[name]
+	if !exists {
+		return fmt.Errorf("通知服务 %s 不存在", name)
+	}
+	if !instance.IsEnabled() {
+		return fmt.Errorf("通知服务 %s 未启用", name)
+	}
+
+	// 联系人引用按通知服务类型解析为对应渠道的身份
+	notifierType := d.app.configManager.GetConfig().Notifiers[name].Type
+	d.mu.Lock()
+	silent := d.silent[name]
+	d.mu.Unlock()
+	message := d.message.Clone()
+	message.Silent = message.Silent || silent
+	message.Mentions = d.app.resolveMentions(notifierType, message.Mentions)
+	targets := d.app.resolveTargets(notifierType, d.targets)
+	if len(d.targets) > 0 && len(targets) == 0 {
+		// 避免目标全部无法解析时发送到通知服务的默认目标
+		return fmt.Errorf("通知服务 %s 发送失败: 发送目标在该渠道没有对应的身份", name)
+	}
+	// 超出通知服务发送频率的消息排队等待，排队超时不计入熔断统计
+	if limiter := d.app.limiter(name); limiter != nil {
+		if err := limiter.wait(ctx, targets); err != nil {
+			return fmt.Errorf("通知服务 %s 发送失败: %w", name, err)
+		}
+	}
+	// 获取信号量，控制并发数
+	d.semaphore <- struct{}{}
+	defer func() { <-d.semaphore }()
+
+	// 经过熔断器发送，熔断期间直接失败，由通知服务组转移到其他通知服务
+	err := d.app.sendWithBreaker(ctx, name, func() error {
+		return instance.Send(ctx, message, targets)
+	})
+	if err != nil {
+		return fmt.Errorf("通知服务 %s 发送失败: %w", name, err)
+	}
+	return nil
+}
@@ -0,0 +1,273 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"notify/internal/config"
+	"notify/internal/logger"
+	"notify/internal/notifier"
+	"notify/internal/source"
+	"notify/internal/utils"
+)
+
+// digestStoreName 摘要缓存在数据目录中的文件名
+const digestStoreName = "digests"
+
+// 内置摘要模板
+const (
+	defaultDigestTitle   = "{{.appName}} 摘要（{{.count}} 条）"
+	defaultDigestContent = "{{range .items}}{{.index}}. [{{.timestamp}}] {{.title}}\n{{.content}}\n\n{{end}}"
+)
+
+// DigestBuffer 一个路由的摘要缓存
+type DigestBuffer struct {
+	AppID       string       `json:"appId"`
+	Route       Route        `json:"route"`
+	Targets     []string     `json:"targets,omitempty"`
+	Items       []DigestItem `json:"items"`
+	CreatedAt   time.Time    `json:"createdAt"`
+	NextFlushAt *time.Time   `json:"nextFlushAt,omitempty"` // 按计划发送的时间，只按条数发送时为空
+}
+
+// DigestItem 摘要中的一条消息
+type DigestItem struct {
+	Title     string         `json:"title"`
+	Content   string         `json:"content"`
+	Severity  string         `json:"severity,omitempty"`
+	Priority  int            `json:"priority,omitempty"`
+	URL       string         `json:"url,omitempty"`
+	Image     string         `json:"image,omitempty"`
+	Timestamp string         `json:"timestamp"`
+	Data      map[string]any `json:"data,omitempty"` // 原始请求数据，摘要模板中可通过 .data 访问
+}
+
+// loadDigests 从数据目录恢复摘要缓存
+func (app *NotificationApp) loadDigests() {
+	app.digests = make(map[string]*DigestBuffer)
+	if err := app.store.Load(digestStoreName, &app.digests); err != nil {
+		logger.Error("读取摘要缓存失败", "error", err)
+		app.digests = make(map[string]*DigestBuffer)
+	}
+}
+
+// saveDigests 保存摘要缓存，调用方需要持有 digestMu
+func (app *NotificationApp) saveDigests() {
+	if err := app.store.Save(digestStoreName, app.digests); err != nil {
+		logger.Error("保存摘要缓存失败", "error", err)
+	}
+}
+
+// digestKey 返回摘要缓存的键
+func digestKey(appID string, route Route, targets []string) string {
+	return strings.Join([]string{appID, route.Rule, strings.Join(targets, ",")}, "\n")
+}
+
+// addToDigest 路由配置了摘要时把消息加入缓存，返回 true 表示消息将在摘要中发送，达到条数上限时立即发送
+func (app *NotificationApp) addToDigest(ctx context.Context, appConfig config.NotificationApp, route Route, message *notifier.NotificationMessage, targets []string, req *map[string]any) bool {
+	digest := appConfig.DigestFor(route.Rule)
+	if digest == nil {
+		return false
+	}
+	if err := digest.Validate(); err != nil {
+		logger.Warn("摘要配置无效，直接发送", "app", appConfig.AppID, "error", err)
+		return false
+	}
+
+	// 附件不进入摘要，避免缓存文件过大；摘要保存在数据目录中，不保存应用令牌等凭证
+	data := make(map[string]any, len(*req))
+	for k, v := range *req {
+		if k != "attachments" {
+			data[k] = v
+		}
+	}
+	source.StripCredentials(data)
+	item := DigestItem{
+		Title:     message.Title,
+		Content:   message.Content,
+		Severity:  message.Severity,
+		Priority:  message.Priority,
+		URL:       message.URL,
+		Image:     message.Image,
+		Timestamp: message.Timestamp,
+		Data:      data,
+	}
+	key := digestKey(appConfig.AppID, route, targets)
+
+	app.digestMu.Lock()
+	buffer, exists := app.digests[key]
+	if !exists {
+		now := time.Now()
+		buffer = &DigestBuffer{
+			AppID:     appConfig.AppID,
+			Route:     route,
+			Targets:   targets,
+			CreatedAt: now,
+		}
+		if digest.Schedule != "" {
+			schedule, _ := utils.ParseCron(digest.Schedule)
+			if next := schedule.Next(now); !next.IsZero() {
+				buffer.NextFlushAt = &next
+			}
+		}
+		app.digests[key] = buffer
+	}
+	buffer.Items = append(buffer.Items, item)
+	full := digest.MaxItems > 0 && len(buffer.Items) >= digest.MaxItems
+	if full {
+		delete(app.digests, key)
+	}
+	app.saveDigests()
+	app.digestMu.Unlock()
+
+	if full {
+		if err := app.sendDigest(ctx, buffer); err != nil {
+			logger.Error("发送摘要失败", "app", appConfig.AppID, "error", err)
+		}
+	}
+	return true
+}
+
+// processDigests 发送到达计划时间的摘要
+func (app *NotificationApp) processDigests(now time.Time) {
+	app.digestMu.Lock()
+	var due []*DigestBuffer
+	for key, buffer := range app.digests {
+		if buffer.NextFlushAt != nil && !now.Before(*buffer.NextFlushAt) {
+			due = append(due, buffer)
+			delete(app.digests, key)
+		}
+	}
+	if len(due) > 0 {
+		app.saveDigests()
+	}
+	app.digestMu.Unlock()
+
+	for _, buffer := range due {
+		ctx, cancel := context.WithTimeout(context.Background(), backgroundSendTimeout)
+		if err := app.sendDigest(ctx, buffer); err != nil {
+			logger.Error("发送摘要失败", "app", buffer.AppID, "rule", buffer.Route.Rule, "error", err)
+		}
+		cancel()
+	}
+}
+
+// FlushDigests 立即发送摘要缓存，appID 为空时发送全部，返回发送的摘要数
+func (app *NotificationApp) FlushDigests(ctx context.Context, appID string) (int, error) {
+	app.digestMu.Lock()
+	var buffers []*DigestBuffer
+	for key, buffer := range app.digests {
+		if appID == "" || buffer.AppID == appID {
+			buffers = append(buffers, buffer)
+			delete(app.digests, key)
+		}
+	}
+	if len(buffers) > 0 {
+		app.saveDigests()
+	}
+	app.digestMu.Unlock()
+
+	var errorMsgs []string
+	for _, buffer := range buffers {
+		if err := app.sendDigest(ctx, buffer); err != nil {
+			errorMsgs = append(errorMsgs, err.Error())
+		}
+	}
+	if len(errorMsgs) > 0 {
+		return len(buffers), fmt.Errorf("发送摘要时发生错误: %s", strings.Join(errorMsgs, "\n "))
+	}
+	return len(buffers), nil
+}
+
+// PendingDigests 返回等待发送的摘要缓存，appID 为空时返回全部
+func (app *NotificationApp) PendingDigests(appID string) []DigestBuffer {
+	app.digestMu.Lock()
+	defer app.digestMu.Unlock()
+
+	buffers := []DigestBuffer{}
+	for _, buffer := range app.digests {
+		if appID == "" || buffer.AppID == appID {
+			buffers = append(buffers, *buffer)
+		}
+	}
+	sort.Slice(buffers, func(i, j int) bool {
+		return buffers[i].CreatedAt.Before(buffers[j].CreatedAt)
+	})
+	return buffers
+}
+
+// sendDigest 使用摘要模板渲染缓存的消息并发送
+func (app *NotificationApp) sendDigest(ctx context.Context, buffer *DigestBuffer) error {
+	appConfig, exists := app.configManager.GetConfig().NotificationApps[buffer.AppID]
+	if !exists {
+		return fmt.Errorf("通知应用 %s 不存在", buffer.AppID)
+	}
+	if len(buffer.Items) == 0 {
+		return nil
+	}
+
+	message, err := app.renderDigest(appConfig, buffer)
+	if err != nil {
+		return err
+	}
+	_, err = app.dispatch(ctx, appConfig, buffer.Route, message, buffer.Targets)
+	return err
+}
+
+// renderDigest 渲染摘要消息，级别和优先级取缓存中最高的
+func (app *NotificationApp) renderDigest(appConfig config.NotificationApp, buffer *DigestBuffer) (*notifier.NotificationMessage, error) {
+	titleTpl, contentTpl := defaultDigestTitle, defaultDigestContent
+	if digest := appConfig.DigestFor(buffer.Route.Rule); digest != nil && digest.TemplateID != "" {
+		tpl, exists := app.configManager.GetConfig().Templates[digest.TemplateID]
+		if !exists {
+			return nil, fmt.Errorf("摘要模板 %s 不存在", digest.TemplateID)
+		}
+		titleTpl, contentTpl = tpl.Title, tpl.Content
+	}
+
+	now := time.Now()
+	items := make([]any, 0, len(buffer.Items))
+	message := &notifier.NotificationMessage{Timestamp: now.Format("2006-01-02 15:04:05")}
+	for i, item := range buffer.Items {
+		items = append(items, map[string]any{
+			"index":     i + 1,
+			"title":     item.Title,
+			"content":   item.Content,
+			"severity":  item.Severity,
+			"priority":  item.Priority,
+			"url":       item.URL,
+			"image":     item.Image,
+			"timestamp": item.Timestamp,
+			"data":      item.Data,
+		})
+		if notifier.SeverityRank(item.Severity) > notifier.SeverityRank(message.Severity) {
+			message.Severity = item.Severity
+		}
+		if item.Priority > message.Priority {
+			message.Priority = item.Priority
+		}
+	}
+	data := map[string]any{
+		"appId":   appConfig.AppID,
+		"appName": appConfig.Name,
+		"rule":    buffer.Route.Rule,
+		"count":   len(buffer.Items),
+		"items":   items,
+		"from":    buffer.CreatedAt.Format("2006-01-02 15:04:05"),
+		"to":      message.Timestamp,
+	}
+
+	var err error
+	name := appConfig.AppID + "_digest"
+	if message.Title, err = app.renderTemplate(name+"_title", titleTpl, &data); err != nil {
+		return nil, fmt.Errorf("渲染摘要模板失败: %w", err)
+	}
+	if message.Content, err = app.renderTemplate(name+"_content", contentTpl, &data); err != nil {
+		return nil, fmt.Errorf("渲染摘要模板失败: %w", err)
+	}
+	message.Content = strings.TrimSpace(message.Content)
+	return message, nil
+}
@@ -0,0 +1,338 @@
+package app
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"notify/internal/config"
+	"notify/internal/logger"
+	"notify/internal/notifier"
+)
+
+const (
+	// escalationStoreName 升级状态在数据目录中的文件名
+	escalationStoreName = "escalations"
+	// escalationRetention 已结束的升级保留时间
+	escalationRetention = 7 * 24 * time.Hour
+)
+
+// 升级状态
+const (
+	EscalationActive    = "active"    // 等待确认
+	EscalationAcked     = "acked"     // 已确认
+	EscalationExhausted = "exhausted" // 所有步骤执行完仍未确认
+)
+
+// Escalation 一条按升级策略发送的告警
+type Escalation struct {
+	ID        string                        `json:"id"`
+	AppID     string                        `json:"appId"`
+	Policy    string                        `json:"policy"`
+	MessageID string                        `json:"messageId,omitempty"` // 对应的消息历史记录
+	Route     Route                         `json:"route"`
+	Targets   []string                      `json:"targets,omitempty"` // 路由的发送目标，步骤没有配置目标时使用
+	Message   *notifier.NotificationMessage `json:"message"`
+	Status    string                        `json:"status"`
+	Step      int                           `json:"step"`  // 下一个要执行的步骤下标
+	Round     int                           `json:"round"` // 已重复的轮数
+	NextAt    time.Time                     `json:"nextAt"`
+	CreatedAt time.Time                     `json:"createdAt"`
+	AckedBy   string                        `json:"ackedBy,omitempty"`
+	AckedAt   *time.Time                    `json:"ackedAt,omitempty"`
+	Events    []EscalationEvent             `json:"events"`
+}
+
+// EscalationEvent 升级步骤的执行记录
+type EscalationEvent struct {
+	Step      int              `json:"step"`
+	Round     int              `json:"round"`
+	Targets   []string         `json:"targets"`
+	Notifiers []string         `json:"notifiers"`
+	Results   []NotifierResult `json:"results,omitempty"` // 每个通知服务的投递结果
+	At        time.Time        `json:"at"`
+	Error     string           `json:"error,omitempty"`
+}
+
+// loadEscalations 从数据目录恢复升级状态
+func (app *NotificationApp) loadEscalations() {
+	app.escalations = make(map[string]*Escalation)
+	if err := app.store.Load(escalationStoreName, &app.escalations); err != nil {
+		logger.Error("读取升级状态失败", "error", err)
+		app.escalations = make(map[string]*Escalation)
+	}
+	for _, escalation := range app.escalations {
+		app.restoreAttachments(escalation.Message)
+	}
+}
+
+// saveEscalations 保存升级状态，调用方需要持有 escalationMu
+func (app *NotificationApp) saveEscalations() {
+	if err := app.store.Save(escalationStoreName, app.escalations); err != nil {
+		logger.Error("保存升级状态失败", "error", err)
+	}
+}
+
+// startEscalation 创建升级并立即执行第一步
+func (app *NotificationApp) startEscalation(ctx context.Context, appConfig config.NotificationApp, route Route, message *notifier.NotificationMessage, targets []string) error {
+	if _, exists := app.configManager.GetConfig().EscalationPolicies[appConfig.EscalationPolicy]; !exists {
+		return fmt.Errorf("升级策略 %s 不存在", appConfig.EscalationPolicy)
+	}
+
+	now := time.Now()
+	escalation := &Escalation{
+		ID:        newRandomID(),
+		AppID:     appConfig.AppID,
+		Policy:    appConfig.EscalationPolicy,
+		MessageID: message.ID,
+		Route:     route,
+		Targets:   targets,
+		Message:   message,
+		Status:    EscalationActive,
+		NextAt:    now,
+		CreatedAt: now,
+		Events:    []EscalationEvent{},
+	}
+	app.persistAttachments(message)
+	app.escalationMu.Lock()
+	app.escalations[escalation.ID] = escalation
+	app.saveEscalations()
+	app.escalationMu.Unlock()
+
+	return app.runEscalationStep(ctx, escalation.ID, now)
+}
+
+// processEscalations 执行到期的升级步骤，并清理过期的已结束升级
+func (app *NotificationApp) processEscalations(now time.Time) {
+	app.escalationMu.Lock()
+	var due []string
+	for id, escalation := range app.escalations {
+		if escalation.Status == EscalationActive && !now.Before(escalation.NextAt) {
+			due = append(due, id)
+		}
+		if escalation.Status != EscalationActive && now.Sub(escalation.CreatedAt) > escalationRetention {
+			delete(app.escalations, id)
+		}
+	}
+	app.escalationMu.Unlock()
+
+	for _, id := range due {
+		ctx, cancel := context.WithTimeout(context.Background(), backgroundSendTimeout)
+		if err := app.runEscalationStep(ctx, id, now); err != nil {
+			logger.Error("执行升级步骤失败", "escalation", id, "error", err)
+		}
+		cancel()
+	}
+}
+
+// runEscalationStep 执行升级的当前步骤，并安排下一步
+func (app *NotificationApp) runEscalationStep(ctx context.Context, id string, now time.Time) error {
+	cfg := app.configManager.GetConfig()
+
+	app.escalationMu.Lock()
+	escalation, exists := app.escalations[id]
+	if !exists || escalation.Status != EscalationActive {
+		app.escalationMu.Unlock()
+		return nil
+	}
+	policy, exists := cfg.EscalationPolicies[escalation.Policy]
+	if !exists || escalation.Step >= len(policy.Steps) {
+		escalation.Status = EscalationExhausted
+		app.saveEscalations()
+		app.escalationMu.Unlock()
+		return fmt.Errorf("升级策略 %s 不存在或步骤已变更", escalation.Policy)
+	}
+	stepIndex, round := escalation.Step, escalation.Round
+	step := policy.Steps[stepIndex]
+	message := escalation.Message.Clone()
+	route := escalation.Route
+	routeTargets := escalation.Targets
+
+	// 发送前先安排下一步，避免发送耗时较长时被下一次检查重复执行
+	delay, _ := step.StepDelay()
+	escalation.NextAt = now.Add(delay)
+	escalation.Step++
+	if escalation.Step >= len(policy.Steps) {
+		if escalation.Round < policy.Repeat {
+			escalation.Round++
+			escalation.Step = 0
+		} else {
+			escalation.Status = EscalationExhausted
+		}
+	}
+	app.saveEscalations()
+	app.escalationMu.Unlock()
+
+	// 展开值班表，步骤中的联系人同时作为提及对象，方便群机器人类渠道提醒到人
+	targets, err := app.expandSchedules(step.Targets, now)
+	if len(step.Targets) == 0 {
+		targets = routeTargets
+	}
+	for _, target := range targets {
+		if prefix, _ := config.ContactRef(target); prefix != "" {
+			message.Mentions = append(message.Mentions, target)
+		}
+	}
+	if stepIndex > 0 || round > 0 {
+		message.Title = fmt.Sprintf("[升级 %d] %s", stepIndex+1, message.Title)
+	}
+	if len(step.Notifiers) > 0 {
+		route.Notifiers = step.Notifiers
+	}
+
+	appConfig, exists := cfg.NotificationApps[escalation.AppID]
+	if !exists {
+		appConfig = config.NotificationApp{AppID: escalation.AppID, Name: escalation.AppID}
+	}
+	// 消息已带有确认按钮时不再重复添加
+	if !appConfig.AckLinks || message.ID == "" {
+		if ackURL := app.EscalationAckURL(id); ackURL != "" {
+			message.Actions = append(message.Actions, notifier.Action{Label: "✅ 确认", URL: ackURL, Style: notifier.ActionStylePrimary})
+		}
+	}
+	var results []NotifierResult
+	if err == nil {
+		results, err = app.deliver(ctx, appConfig, route, message, targets)
+	}
+
+	app.escalationMu.Lock()
+	defer app.escalationMu.Unlock()
+	event := EscalationEvent{
+		Step:      stepIndex + 1,
+		Round:     round,
+		Targets:   targets,
+		Notifiers: route.Notifiers,
+		Results:   results,
+		At:        now,
+	}
+	if err != nil {
+		event.Error = err.Error()
+	}
+	escalation.Events = append(escalation.Events, event)
+	app.saveEscalations()
+	return err
+}
+
+// expandSchedules 把 schedule: 引用替换为当前值班的联系人
+func (app *NotificationApp) expandSchedules(targets []string, at time.Time) ([]string, error) {
+	cfg := app.configManager.GetConfig()
+	expanded := make([]string, 0, len(targets))
+	for _, target := range targets {
+		id, ok := strings.CutPrefix(target, config.SchedulePrefix)
+		if !ok {
+			expanded = append(expanded, target)
+			continue
+		}
+		schedule, exists := cfg.Schedules[id]
+		if !exists {
+			return expanded, fmt.Errorf("值班表 %s 不存在", id)
+		}
+		contact, err := schedule.OnCall(at)
+		if err != nil {
+			return expanded, fmt.Errorf("值班表 %s: %w", id, err)
+		}
+		expanded = append(expanded, config.ContactUserPrefix+contact)
+	}
+	return expanded, nil
+}
+
+// AckEscalation 确认升级，停止后续步骤，并在消息历史中记录确认人
+func (app *NotificationApp) AckEscalation(ctx context.Context, id, by string) (*Escalation, error) {
+	app.escalationMu.Lock()
+	escalation, exists := app.escalations[id]
+	if !exists {
+		app.escalationMu.Unlock()
+		return nil, fmt.Errorf("升级 %s 不存在", id)
+	}
+	if escalation.Status == EscalationAcked {
+		app.escalationMu.Unlock()
+		return nil, fmt.Errorf("升级 %s 已被 %s 确认", id, escalation.AckedBy)
+	}
+	now := time.Now()
+	escalation.Status = EscalationAcked
+	escalation.AckedBy = by
+	escalation.AckedAt = &now
+	app.saveEscalations()
+	result := *escalation
+	app.escalationMu.Unlock()
+
+	if result.MessageID != "" {
+		if record, err := app.ackMessageRecord(result.MessageID, MessageActionAck, by); err == nil {
+			app.notifyAck(ctx, record, MessageActionAck, by)
+		}
+	}
+	return &result, nil
+}
+
+// ackEscalationsForMessage 消息被确认时停止对应的升级
+func (app *NotificationApp) ackEscalationsForMessage(messageID, by string) {
+	app.escalationMu.Lock()
+	defer app.escalationMu.Unlock()
+
+	now := time.Now()
+	for _, escalation := range app.escalations {
+		if escalation.MessageID == messageID && escalation.Status != EscalationAcked {
+			escalation.Status = EscalationAcked
+			escalation.AckedBy = by
+			escalation.AckedAt = &now
+			app.saveEscalations()
+		}
+	}
+}
+
+// EscalationAckURL 返回升级的签名确认链接，未配置 PUBLIC_BASE_URL 时返回空字符串
+func (app *NotificationApp) EscalationAckURL(id string) string {
+	baseURL := strings.TrimSuffix(config.EnvCfg.PUBLIC_BASE_URL, "/")
+	if baseURL == "" {
+		return ""
+	}
+	return fmt.Sprintf("%s/api/v1/escalations/%s/ack?sig=%s", baseURL, id, app.signer.Sign(id, "ack"))
+}
+
+// VerifyEscalationAck 校验确认链接的签名
+func (app *NotificationApp) VerifyEscalationAck(id, signature string) bool {
+	return app.signer.Verify(signature, id, "ack")
+}
+
+// GetEscalation 获取单个升级
+func (app *NotificationApp) GetEscalation(id string) (*Escalation, bool) {
+	app.escalationMu.Lock()
+	defer app.escalationMu.Unlock()
+
+	escalation, exists := app.escalations[id]
+	if !exists {
+		return nil, false
+	}
+	result := *escalation
+	return &result, true
+}
+
+// ListEscalations 返回升级列表，status 为空时返回全部，最新的在前
+func (app *NotificationApp) ListEscalations(status string) []Escalation {
+	app.escalationMu.Lock()
+	defer app.escalationMu.Unlock()
+
+	escalations := []Escalation{}
+	for _, escalation := range app.escalations {
+		if status == "" || escalation.Status == status {
+			escalations = append(escalations, *escalation)
+		}
+	}
+	sort.Slice(escalations, func(i, j int) bool {
+		return escalations[i].CreatedAt.After(escalations[j].CreatedAt)
+	})
+	return escalations
+}
+
+// newRandomID 生成随机 ID，用于升级和消息记录
+func newRandomID() string {
+	b := make([]byte, 8)
+	if _, err := rand.Read(b); err != nil {
+		return fmt.Sprintf("%x", time.Now().UnixNano())
+	}
+	return hex.EncodeToString(b)
+}
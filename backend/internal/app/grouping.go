@@ -0,0 +1,194 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"notify/internal/config"
+	"notify/internal/logger"
+	"notify/internal/notifier"
+)
+
+// groupStoreName 分组状态在数据目录中的文件名
+const groupStoreName = "groups"
+
+// AlertGroup 一个分组，缓存等待合并发送的消息
+type AlertGroup struct {
+	Key         string                          `json:"key"` // 分组键
+	AppID       string                          `json:"appId"`
+	Route       Route                           `json:"route"`
+	Targets     []string                        `json:"targets,omitempty"`
+	Messages    []*notifier.NotificationMessage `json:"messages"` // 等待发送的消息
+	FlushAt     time.Time                       `json:"flushAt"`
+	LastFlushAt time.Time                       `json:"lastFlushAt,omitempty"`
+}
+
+// loadGroups 从数据目录恢复分组状态
+func (app *NotificationApp) loadGroups() {
+	app.groups = make(map[string]*AlertGroup)
+	if err := app.store.Load(groupStoreName, &app.groups); err != nil {
+		logger.Error("读取分组状态失败", "error", err)
+		app.groups = make(map[string]*AlertGroup)
+	}
+	for _, group := range app.groups {
+		for _, message := range group.Messages {
+			app.restoreAttachments(message)
+		}
+	}
+}
+
+// saveGroups 保存分组状态，调用方需要持有 groupMu
+func (app *NotificationApp) saveGroups() {
+	if err := app.store.Save(groupStoreName, app.groups); err != nil {
+		logger.Error("保存分组状态失败", "error", err)
+	}
+}
+
+// addToGroup 应用配置了分组时把消息加入分组，返回 true 表示消息将在分组发送时合并发送
+// 新分组等待 group_wait 后发送，之后加入的消息距上次发送至少 group_interval 后再发送
+func (app *NotificationApp) addToGroup(appConfig config.NotificationApp, route Route, message *notifier.NotificationMessage, targets []string, req *map[string]any) bool {
+	if appConfig.Grouping == nil {
+		return false
+	}
+	wait, err := appConfig.Grouping.WaitDuration()
+	if err == nil {
+		_, err = appConfig.Grouping.IntervalDuration()
+	}
+	if err != nil {
+		logger.Warn("分组配置无效，不做分组", "app", appConfig.AppID, "error", err)
+		return false
+	}
+	groupKey, err := app.renderTemplate(appConfig.AppID+"_group_by", appConfig.Grouping.GroupBy, req)
+	if err != nil {
+		logger.Warn("渲染分组键失败，直接发送", "app", appConfig.AppID, "error", err)
+		return false
+	}
+	groupKey = strings.TrimSpace(groupKey)
+
+	key := strings.Join([]string{appConfig.AppID, route.Rule, route.TemplateID, strings.Join(targets, ","), groupKey}, "\n")
+	now := time.Now()
+
+	app.groupMu.Lock()
+	defer app.groupMu.Unlock()
+
+	group, exists := app.groups[key]
+	if !exists {
+		group = &AlertGroup{
+			Key:     groupKey,
+			AppID:   appConfig.AppID,
+			Route:   route,
+			Targets: targets,
+			FlushAt: now.Add(wait),
+		}
+		app.groups[key] = group
+	} else if len(group.Messages) == 0 {
+		// 分组已发送过，距上次发送满 group_interval 后再发送
+		interval, _ := appConfig.Grouping.IntervalDuration()
+		group.FlushAt = group.LastFlushAt.Add(interval)
+	}
+	clone := message.Clone()
+	app.persistAttachments(clone)
+	group.Messages = append(group.Messages, clone)
+	app.saveGroups()
+	logger.Debug("消息加入分组", "app", appConfig.AppID, "group", groupKey, "count", len(group.Messages))
+	return true
+}
+
+// processGroups 发送到期的分组，并清理超过 group_interval 没有新消息的分组
+func (app *NotificationApp) processGroups(now time.Time) {
+	cfg := app.configManager.GetConfig()
+
+	type flush struct {
+		appConfig config.NotificationApp
+		group     AlertGroup
+	}
+	var flushes []flush
+
+	app.groupMu.Lock()
+	changed := false
+	for key, group := range app.groups {
+		appConfig, exists := cfg.NotificationApps[group.AppID]
+		interval := config.DefaultGroupInterval
+		if exists && appConfig.Grouping != nil {
+			interval, _ = appConfig.Grouping.IntervalDuration()
+		}
+		if len(group.Messages) == 0 {
+			if now.Sub(group.LastFlushAt) >= interval {
+				delete(app.groups, key)
+				changed = true
+			}
+			continue
+		}
+		if now.Before(group.FlushAt) {
+			continue
+		}
+		changed = true
+		if !exists {
+			delete(app.groups, key)
+			continue
+		}
+		flushes = append(flushes, flush{appConfig: appConfig, group: *group})
+		group.Messages = nil
+		group.LastFlushAt = now
+	}
+	if changed {
+		app.saveGroups()
+	}
+	app.groupMu.Unlock()
+
+	for _, f := range flushes {
+		message := combineMessages(f.group.Messages, now)
+		ctx, cancel := context.WithTimeout(context.Background(), backgroundSendTimeout)
+		if _, err := app.dispatch(ctx, f.appConfig, f.group.Route, message, f.group.Targets); err != nil {
+			logger.Error("发送分组消息失败", "app", f.group.AppID, "group", f.group.Key, "error", err)
+		}
+		cancel()
+	}
+}
+
+// combineMessages 把分组内的消息合并为一条，级别和优先级取最高，附件、按钮和提及合并
+func combineMessages(messages []*notifier.NotificationMessage, now time.Time) *notifier.NotificationMessage {
+	if len(messages) == 1 {
+		return messages[0]
+	}
+
+	combined := &notifier.NotificationMessage{
+		Title:     fmt.Sprintf("[%d 条] %s", len(messages), messages[0].Title),
+		Timestamp: now.Format("2006-01-02 15:04:05"),
+	}
+	contents := make([]string, 0, len(messages))
+	seenActions := make(map[string]bool)
+	seenMentions := make(map[string]bool)
+	for i, message := range messages {
+		contents = append(contents, fmt.Sprintf("%d. %s\n%s", i+1, message.Title, message.Content))
+		if notifier.SeverityRank(message.Severity) > notifier.SeverityRank(combined.Severity) {
+			combined.Severity = message.Severity
+		}
+		if message.Priority > combined.Priority {
+			combined.Priority = message.Priority
+		}
+		if combined.Image == "" {
+			combined.Image = message.Image
+		}
+		if combined.URL == "" {
+			combined.URL = message.URL
+		}
+		combined.Attachments = append(combined.Attachments, message.Attachments...)
+		for _, action := range message.Actions {
+			if !seenActions[action.URL] {
+				seenActions[action.URL] = true
+				combined.Actions = append(combined.Actions, action)
+			}
+		}
+		for _, mention := range message.Mentions {
+			if !seenMentions[mention] {
+				seenMentions[mention] = true
+				combined.Mentions = append(combined.Mentions, mention)
+			}
+		}
+	}
+	combined.Content = strings.Join(contents, "\n\n")
+	return combined
+}
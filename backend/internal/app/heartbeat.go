@@ -0,0 +1,338 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"notify/internal/config"
+	"notify/internal/logger"
+)
+
+const (
+	// heartbeatStoreName 心跳状态在数据目录中的文件名
+	heartbeatStoreName = "heartbeats"
+	// heartbeatBodyLimit ping 请求体保留的最大长度
+	heartbeatBodyLimit = 2000
+)
+
+// 心跳状态
+const (
+	HeartbeatNew  = "new"  // 还没有收到过成功或失败的 ping
+	HeartbeatUp   = "up"   // 按时收到 ping
+	HeartbeatDown = "down" // 超时未收到 ping 或收到失败 ping
+)
+
+// ping 类型
+const (
+	PingStart   = "start"
+	PingSuccess = "success"
+	PingFail    = "fail"
+)
+
+// 心跳通知事件
+const (
+	heartbeatMissed    = "missed"
+	heartbeatFailed    = "failed"
+	heartbeatRecovered = "recovered"
+)
+
+// HeartbeatState 心跳监控的状态
+type HeartbeatState struct {
+	Status        string     `json:"status"`
+	Running       bool       `json:"running"` // 收到 start 后还没有收到结束 ping
+	LastPingAt    *time.Time `json:"lastPingAt,omitempty"`
+	LastStartAt   *time.Time `json:"lastStartAt,omitempty"`
+	LastSuccessAt *time.Time `json:"lastSuccessAt,omitempty"`
+	LastFailAt    *time.Time `json:"lastFailAt,omitempty"`
+	LastDuration  string     `json:"lastDuration,omitempty"` // 最近一次 start 到结束 ping 的耗时
+	LastBody      string     `json:"lastBody,omitempty"`     // 最近一次失败 ping 的请求体
+	DownReason    string     `json:"downReason,omitempty"`   // missed 或 failed
+	DownSince     *time.Time `json:"downSince,omitempty"`
+	DownRepeats   int        `json:"downRepeats,omitempty"` // 已经是 down 时又收到的失败 ping 次数，这些 ping 不再发送通知
+	Pings         int        `json:"pings"`
+}
+
+// HeartbeatStatus 心跳监控配置和状态
+type HeartbeatStatus struct {
+	ID        string           `json:"id"`
+	Heartbeat config.Heartbeat `json:"heartbeat"`
+	State     HeartbeatState   `json:"state"`
+	PingURL   string           `json:"pingUrl"`
+	Deadline  *time.Time       `json:"deadline,omitempty"` // 下一次 ping 的截止时间
+}
+
+// loadHeartbeats 从数据目录恢复心跳状态
+func (app *NotificationApp) loadHeartbeats() {
+	app.heartbeats = make(map[string]*HeartbeatState)
+	if err := app.store.Load(heartbeatStoreName, &app.heartbeats); err != nil {
+		logger.Error("读取心跳状态失败", "error", err)
+		app.heartbeats = make(map[string]*HeartbeatState)
+	}
+}
+
+// saveHeartbeats 保存心跳状态，调用方需要持有 heartbeatMu
+func (app *NotificationApp) saveHeartbeats() {
+	if err := app.store.Save(heartbeatStoreName, app.heartbeats); err != nil {
+		logger.Error("保存心跳状态失败", "error", err)
+	}
+}
+
+// heartbeatDeadline 返回下一次 ping 的截止时间，还没有收到过 ping 时返回 nil
+// 收到 start 后需要在宽限时间内收到结束 ping，未配置宽限时间时为一个间隔
+func heartbeatDeadline(heartbeat config.Heartbeat, state *HeartbeatState) *time.Time {
+	interval, err := heartbeat.IntervalDuration()
+	if err != nil {
+		return nil
+	}
+	grace, _ := heartbeat.GraceDuration()
+
+	var deadline time.Time
+	switch {
+	case state.Running && state.LastStartAt != nil:
+		if grace > 0 {
+			deadline = state.LastStartAt.Add(grace)
+		} else {
+			deadline = state.LastStartAt.Add(interval)
+		}
+	case state.LastSuccessAt != nil || state.LastFailAt != nil:
+		last := state.LastSuccessAt
+		if last == nil || (state.LastFailAt != nil && state.LastFailAt.After(*last)) {
+			last = state.LastFailAt
+		}
+		deadline = last.Add(interval + grace)
+	default:
+		return nil
+	}
+	return &deadline
+}
+
+// Ping 记录心跳监控收到的 ping，kind 为 start、success 或 fail，返回心跳监控 ID
+// 从正常变为失败的 ping 和恢复后的第一次成功 ping 会在后台发送通知，已经失败时的失败 ping 只计数
+func (app *NotificationApp) Ping(token, kind, body string) (string, error) {
+	id, heartbeat, exists := app.configManager.FindHeartbeatByToken(token)
+	if !exists {
+		return "", fmt.Errorf("心跳监控不存在")
+	}
+
+	now := time.Now()
+	app.heartbeatMu.Lock()
+	state, exists := app.heartbeats[id]
+	if !exists {
+		state = &HeartbeatState{Status: HeartbeatNew}
+		app.heartbeats[id] = state
+	}
+	state.LastPingAt = &now
+	state.Pings++
+
+	event := ""
+	switch kind {
+	case PingStart:
+		state.Running = true
+		state.LastStartAt = &now
+	case PingSuccess, PingFail:
+		state.LastDuration = ""
+		if state.Running && state.LastStartAt != nil {
+			state.LastDuration = now.Sub(*state.LastStartAt).Round(time.Millisecond).String()
+		}
+		state.Running = false
+		if kind == PingSuccess {
+			state.LastSuccessAt = &now
+			if state.Status == HeartbeatDown {
+				event = heartbeatRecovered
+			}
+			state.Status = HeartbeatUp
+			state.DownReason = ""
+			state.DownSince = nil
+			state.DownRepeats = 0
+		} else {
+			state.LastFailAt = &now
+			state.LastBody = truncateBody(body)
+			if state.Status == HeartbeatDown {
+				// 重试中的任务可能连续失败，只在进入 down 时通知一次
+				state.DownRepeats++
+			} else {
+				event = heartbeatFailed
+				state.Status = HeartbeatDown
+				state.DownReason = heartbeatFailed
+				state.DownSince = &now
+			}
+		}
+	default:
+		app.heartbeatMu.Unlock()
+		return id, fmt.Errorf("未知的 ping 类型 %s", kind)
+	}
+	snapshot := *state
+	app.saveHeartbeats()
+	app.heartbeatMu.Unlock()
+
+	logger.Debug("收到心跳 ping", "heartbeat", id, "kind", kind)
+	if event != "" && !heartbeat.Paused {
+		// 发送通知可能较慢，不阻塞 ping 请求
+		app.wg.Add(1)
+		go func() {
+			defer app.wg.Done()
+			app.notifyHeartbeat(id, heartbeat, snapshot, event)
+		}()
+	}
+	return id, nil
+}
+
+// processHeartbeats 检查心跳监控是否超时，超时的监控标记为 down 并发送通知
+func (app *NotificationApp) processHeartbeats(now time.Time) {
+	heartbeats := app.configManager.Heartbeats()
+
+	type missed struct {
+		id        string
+		heartbeat config.Heartbeat
+		state     HeartbeatState
+	}
+	var due []missed
+
+	app.heartbeatMu.Lock()
+	changed := false
+	for id := range app.heartbeats {
+		if _, exists := heartbeats[id]; !exists {
+			delete(app.heartbeats, id)
+			changed = true
+		}
+	}
+	for id, heartbeat := range heartbeats {
+		state, exists := app.heartbeats[id]
+		if !exists || heartbeat.Paused || state.Status == HeartbeatDown {
+			continue
+		}
+		deadline := heartbeatDeadline(heartbeat, state)
+		if deadline == nil || now.Before(*deadline) {
+			continue
+		}
+		state.Status = HeartbeatDown
+		state.DownReason = heartbeatMissed
+		state.DownSince = &now
+		state.Running = false
+		changed = true
+		due = append(due, missed{id: id, heartbeat: heartbeat, state: *state})
+	}
+	if changed {
+		app.saveHeartbeats()
+	}
+	app.heartbeatMu.Unlock()
+
+	sort.Slice(due, func(i, j int) bool {
+		return due[i].id < due[j].id
+	})
+	for _, item := range due {
+		logger.Warn("心跳监控超时", "heartbeat", item.id, "lastPingAt", item.state.LastPingAt)
+		app.notifyHeartbeat(item.id, item.heartbeat, item.state, heartbeatMissed)
+	}
+}
+
+// notifyHeartbeat 使用心跳监控配置的应用发送超时、失败或恢复通知
+func (app *NotificationApp) notifyHeartbeat(id string, heartbeat config.Heartbeat, state HeartbeatState, event string) {
+	lastPing := "从未"
+	if state.LastSuccessAt != nil {
+		lastPing = state.LastSuccessAt.Format(time.DateTime)
+	}
+
+	var title, severity, status string
+	lines := []string{fmt.Sprintf("预期间隔: %s", heartbeat.Interval)}
+	if heartbeat.Grace != "" {
+		lines[0] += fmt.Sprintf("，宽限时间: %s", heartbeat.Grace)
+	}
+	switch event {
+	case heartbeatMissed:
+		title = fmt.Sprintf("心跳超时: %s", heartbeat.Name)
+		severity, status = "error", "firing"
+		if state.LastStartAt != nil && (state.LastSuccessAt == nil || state.LastStartAt.After(*state.LastSuccessAt)) {
+			lines = append(lines, fmt.Sprintf("任务开始于 %s，未在截止时间前结束", state.LastStartAt.Format(time.DateTime)))
+		}
+		lines = append(lines, fmt.Sprintf("上次成功: %s", lastPing))
+	case heartbeatFailed:
+		title = fmt.Sprintf("任务失败: %s", heartbeat.Name)
+		severity, status = "error", "firing"
+		if state.LastDuration != "" {
+			lines = append(lines, fmt.Sprintf("耗时: %s", state.LastDuration))
+		}
+		if state.LastBody != "" {
+			lines = append(lines, state.LastBody)
+		}
+	case heartbeatRecovered:
+		title = fmt.Sprintf("心跳恢复: %s", heartbeat.Name)
+		severity, status = "info", "resolved"
+		lines = append(lines, fmt.Sprintf("恢复时间: %s", lastPing))
+	}
+
+	info := map[string]any{
+		"id":       id,
+		"name":     heartbeat.Name,
+		"event":    event,
+		"interval": heartbeat.Interval,
+		"grace":    heartbeat.Grace,
+	}
+	if state.LastPingAt != nil {
+		info["lastPingAt"] = state.LastPingAt.Format(time.RFC3339)
+	}
+	data := map[string]any{
+		"title":     title,
+		"content":   strings.Join(lines, "\n"),
+		"severity":  severity,
+		"status":    status,
+		"heartbeat": info,
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), backgroundSendTimeout)
+	defer cancel()
+	if _, err := app.Send(ctx, config.NotificationApp{AppID: heartbeat.AppID}, &data); err != nil {
+		logger.Error("发送心跳通知失败", "heartbeat", id, "event", event, "error", err)
+	}
+}
+
+// HeartbeatStatuses 返回所有心跳监控的配置和状态，按 ID 排序
+func (app *NotificationApp) HeartbeatStatuses() []HeartbeatStatus {
+	heartbeats := app.configManager.Heartbeats()
+
+	app.heartbeatMu.Lock()
+	defer app.heartbeatMu.Unlock()
+
+	list := make([]HeartbeatStatus, 0, len(heartbeats))
+	for id, heartbeat := range heartbeats {
+		status := HeartbeatStatus{
+			ID:        id,
+			Heartbeat: heartbeat,
+			State:     HeartbeatState{Status: HeartbeatNew},
+			PingURL:   HeartbeatPingURL(heartbeat.Token),
+		}
+		if state, exists := app.heartbeats[id]; exists {
+			status.State = *state
+			if !heartbeat.Paused && state.Status != HeartbeatDown {
+				status.Deadline = heartbeatDeadline(heartbeat, state)
+			}
+		}
+		list = append(list, status)
+	}
+	sort.Slice(list, func(i, j int) bool {
+		return list[i].ID < list[j].ID
+	})
+	return list
+}
+
+// HeartbeatPingURL 返回心跳监控的 ping 地址，未配置 PUBLIC_BASE_URL 时返回相对路径
+func HeartbeatPingURL(token string) string {
+	return fmt.Sprintf("%s/api/v1/ping/%s", strings.TrimSuffix(config.EnvCfg.PUBLIC_BASE_URL, "/"), token)
+}
+
+// truncateBody 截断 ping 请求体
+func truncateBody(body string) string {
+	body = strings.TrimSpace(body)
+	if len(body) <= heartbeatBodyLimit {
+		return body
+	}
+	// 避免截断在 UTF-8 字符中间
+	cut := heartbeatBodyLimit
+	for cut > 0 && body[cut]&0xC0 == 0x80 {
+		cut--
+	}
+	return body[:cut] + "..."
+}
@@ -0,0 +1,181 @@
+package app
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"notify/internal/config"
+	"notify/internal/logger"
+)
+
+// idempotencyStoreName 幂等键和请求结果在数据目录中的文件名
+const idempotencyStoreName = "idempotency"
+
+const (
+	// idempotencyMaxBody 保存的响应内容上限，超过时只保存状态码，重复的请求返回空响应
+	idempotencyMaxBody = 64 * 1024
+	// idempotencyMaxEntries 最多保存的幂等键数量，超过时丢弃最早的
+	idempotencyMaxEntries = 10000
+)
+
+// ErrIdempotencyKeyReused 同一个幂等键用于内容不同的请求
+var ErrIdempotencyKeyReused = errors.New("幂等键已用于内容不同的请求")
+
+// errIdempotentRunFailed 相同幂等键的请求处理失败，没有可以返回的结果
+var errIdempotentRunFailed = errors.New("相同幂等键的请求处理失败，请重试")
+
+// IdempotentResult 幂等键对应的请求和响应，有效期内重复的请求直接返回保存的响应
+type IdempotentResult struct {
+	AppID       string          `json:"appId"`
+	Key         string          `json:"key"`
+	Fingerprint string          `json:"fingerprint"` // 请求内容的摘要，用于发现幂等键被其他请求复用
+	Status      int             `json:"status"`      // HTTP 状态码
+	Body        json.RawMessage `json:"body"`        // 响应内容
+	CreatedAt   time.Time       `json:"createdAt"`
+	ExpiresAt   time.Time       `json:"expiresAt"`
+}
+
+// idempotentCall 正在处理的请求，重复的请求等待 done 关闭后使用同一个结果
+type idempotentCall struct {
+	fingerprint string
+	done        chan struct{}
+	result      *IdempotentResult
+	err         error // 处理失败时等待中的请求收到的错误
+}
+
+// RequestFingerprint 返回请求内容的摘要
+func RequestFingerprint(parts ...string) string {
+	hash := sha256.New()
+	for _, part := range parts {
+		hash.Write([]byte(part))
+		hash.Write([]byte{0})
+	}
+	return hex.EncodeToString(hash.Sum(nil))
+}
+
+// idempotencyKey 幂等键按应用区分
+func idempotencyKey(appID, key string) string {
+	return appID + "/" + key
+}
+
+// loadIdempotency 从数据目录恢复幂等键
+func (app *NotificationApp) loadIdempotency() {
+	app.idempotent = make(map[string]*IdempotentResult)
+	app.inflight = make(map[string]*idempotentCall)
+	if err := app.store.Load(idempotencyStoreName, &app.idempotent); err != nil {
+		logger.Error("读取幂等键失败", "error", err)
+		app.idempotent = make(map[string]*IdempotentResult)
+	}
+}
+
+// saveIdempotency 保存幂等键，调用方需要持有 idempotencyMu
+func (app *NotificationApp) saveIdempotency() {
+	if err := app.store.Save(idempotencyStoreName, app.idempotent); err != nil {
+		logger.Error("保存幂等键失败", "error", err)
+		return
+	}
+	app.idempotencyDirty = false
+}
+
+// evictIdempotency 幂等键超过数量上限时丢弃最早的，调用方需要持有 idempotencyMu
+func (app *NotificationApp) evictIdempotency() {
+	for len(app.idempotent) > idempotencyMaxEntries {
+		var oldestID string
+		var oldest time.Time
+		for id, result := range app.idempotent {
+			if oldestID == "" || result.CreatedAt.Before(oldest) {
+				oldestID, oldest = id, result.CreatedAt
+			}
+		}
+		delete(app.idempotent, oldestID)
+	}
+}
+
+// Idempotent 按幂等键处理请求，有效期内已处理过的请求直接返回保存的结果，replayed 为 true
+// 相同幂等键的请求正在处理时等待其完成，幂等键用于内容不同的请求时返回 ErrIdempotencyKeyReused
+// run 返回 HTTP 状态码和响应内容，调用方应使用不随客户端断开而取消的 context 执行发送，保证结果可以被重试的请求使用
+// run 返回错误时不保存结果，如超过请求限制，等待中的请求收到同一个错误，之后的重试重新执行 run
+// 结果由后台任务批量保存到数据目录
+func (app *NotificationApp) Idempotent(ctx context.Context, appID, key, fingerprint string, run func() (int, []byte, error)) (*IdempotentResult, bool, error) {
+	id := idempotencyKey(appID, key)
+	now := time.Now()
+
+	app.idempotencyMu.Lock()
+	if result, exists := app.idempotent[id]; exists && now.Before(result.ExpiresAt) {
+		app.idempotencyMu.Unlock()
+		if result.Fingerprint != fingerprint {
+			return nil, false, ErrIdempotencyKeyReused
+		}
+		return result, true, nil
+	}
+	if call, exists := app.inflight[id]; exists {
+		app.idempotencyMu.Unlock()
+		if call.fingerprint != fingerprint {
+			return nil, false, ErrIdempotencyKeyReused
+		}
+		select {
+		case <-call.done:
+			return call.result, call.result != nil, call.err
+		case <-ctx.Done():
+			return nil, false, ctx.Err()
+		}
+	}
+	call := &idempotentCall{fingerprint: fingerprint, done: make(chan struct{}), err: errIdempotentRunFailed}
+	app.inflight[id] = call
+	app.idempotencyMu.Unlock()
+
+	// run 出现 panic 时同样结束处理，等待中的请求收到错误而不是一直等待
+	defer func() {
+		app.idempotencyMu.Lock()
+		delete(app.inflight, id)
+		if call.result != nil && config.EnvCfg.IDEMPOTENCY_TTL > 0 {
+			app.idempotent[id] = call.result
+			app.evictIdempotency()
+			app.idempotencyDirty = true
+		}
+		app.idempotencyMu.Unlock()
+		close(call.done)
+	}()
+
+	status, body, err := run()
+	if err != nil {
+		call.err = err
+		return nil, false, err
+	}
+	if len(body) > idempotencyMaxBody {
+		logger.Warn("响应内容过大，幂等键只保存状态码", "app", appID, "size", len(body))
+		body = nil
+	}
+	finished := time.Now()
+	call.result = &IdempotentResult{
+		AppID:       appID,
+		Key:         key,
+		Fingerprint: fingerprint,
+		Status:      status,
+		Body:        body,
+		CreatedAt:   finished,
+		ExpiresAt:   finished.Add(config.EnvCfg.IDEMPOTENCY_TTL),
+	}
+	call.err = nil
+	return call.result, false, nil
+}
+
+// processIdempotency 清理过期的幂等键，有变化时保存
+func (app *NotificationApp) processIdempotency(now time.Time) {
+	app.idempotencyMu.Lock()
+	defer app.idempotencyMu.Unlock()
+
+	for id, result := range app.idempotent {
+		if !now.Before(result.ExpiresAt) {
+			delete(app.idempotent, id)
+			app.idempotencyDirty = true
+		}
+	}
+	if app.idempotencyDirty {
+		app.saveIdempotency()
+	}
+}
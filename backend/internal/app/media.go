@@ -0,0 +1,71 @@
+package app
+
+import (
+	"context"
+	"strings"
+
+	"notify/internal/config"
+	"notify/internal/logger"
+	"notify/internal/media"
+	"notify/internal/notifier"
+)
+
+// MediaStore 返回媒体存储，未启用时为 nil
+func (app *NotificationApp) MediaStore() *media.Store {
+	return app.media
+}
+
+// hostMedia 把消息中的图片和附件保存到媒体存储，并改写为公网地址
+// data URI 图片和没有地址的附件总是托管；应用开启 media_hosting 时远程图片也会被下载后托管
+func (app *NotificationApp) hostMedia(ctx context.Context, appConfig config.NotificationApp, message *notifier.NotificationMessage) {
+	if app.media == nil {
+		return
+	}
+
+	if message.Image != "" && !app.media.IsHosted(message.Image) {
+		var image *notifier.Attachment
+		if strings.HasPrefix(message.Image, "data:") {
+			data, contentType, err := decodeBase64Attachment(message.Image)
+			if err != nil {
+				logger.Error("解析图片失败", "error", err)
+			} else {
+				image = &notifier.Attachment{Data: data, ContentType: contentType}
+			}
+		} else if appConfig.MediaHosting {
+			image = &notifier.Attachment{URL: message.Image}
+		}
+		if image != nil {
+			if url, err := app.putMedia(ctx, image); err != nil {
+				logger.Error("托管图片失败", "image", message.Image, "error", err)
+			} else {
+				message.Image = url
+			}
+		}
+	}
+
+	// 附件保留内容用于原生上传，同时补充地址供只能发送链接的通知服务使用
+	for i := range message.Attachments {
+		attachment := &message.Attachments[i]
+		if attachment.URL != "" {
+			continue
+		}
+		if url, err := app.putMedia(ctx, attachment); err != nil {
+			logger.Error("托管附件失败", "name", attachment.FileName(), "error", err)
+		} else {
+			attachment.URL = url
+		}
+	}
+}
+
+// putMedia 读取内容并保存到媒体存储，返回公网地址
+func (app *NotificationApp) putMedia(ctx context.Context, attachment *notifier.Attachment) (string, error) {
+	data, err := attachment.Load(ctx)
+	if err != nil {
+		return "", err
+	}
+	object, err := app.media.Put(data, attachment.ContentType)
+	if err != nil {
+		return "", err
+	}
+	return object.URL, nil
+}
@@ -0,0 +1,44 @@
+package app
+
+import (
+	"fmt"
+	"strings"
+
+	"notify/internal/config"
+)
+
+// renderMentions 渲染模板中的提及对象，模板未配置时使用请求数据中的 mentions（列表或逗号分隔的字符串）
+func (app *NotificationApp) renderMentions(templateID string, tpl *config.MessageTemplate, req *map[string]any) ([]string, error) {
+	if tpl.Mentions != "" {
+		rendered, err := app.renderTemplate(templateID+"_mentions", tpl.Mentions, req)
+		if err != nil {
+			return nil, fmt.Errorf("渲染提及对象失败: %w", err)
+		}
+		return splitList(rendered), nil
+	}
+
+	switch v := (*req)["mentions"].(type) {
+	case string:
+		return splitList(v), nil
+	case []any:
+		mentions := make([]string, 0, len(v))
+		for _, item := range v {
+			if s := strings.TrimSpace(fmt.Sprint(item)); item != nil && s != "" {
+				mentions = append(mentions, s)
+			}
+		}
+		return mentions, nil
+	}
+	return nil, nil
+}
+
+// splitList 拆分逗号分隔的列表，去掉空白和空项
+func splitList(s string) []string {
+	var items []string
+	for _, item := range strings.Split(s, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
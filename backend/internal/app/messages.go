@@ -0,0 +1,268 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"notify/internal/config"
+	"notify/internal/logger"
+	"notify/internal/notifier"
+)
+
+// messageStoreName 消息历史在数据目录中的文件名
+const messageStoreName = "messages"
+
+// 消息确认操作
+const (
+	MessageActionAck     = "ack"
+	MessageActionResolve = "resolve"
+)
+
+// 消息状态
+const (
+	MessagePending  = "pending"  // 正在发送
+	MessageSent     = "sent"     // 已发送
+	MessageFailed   = "failed"   // 发送失败
+	MessageAcked    = "acked"    // 已确认
+	MessageResolved = "resolved" // 已解决
+)
+
+// MessageRecord 消息历史记录
+type MessageRecord struct {
+	ID         string     `json:"id"`
+	AppID      string     `json:"appId"`
+	Title      string     `json:"title"`
+	Severity   string     `json:"severity,omitempty"`
+	Route      Route      `json:"route"`
+	Targets    []string   `json:"targets,omitempty"`
+	Status     string     `json:"status"`
+	Error      string     `json:"error,omitempty"`
+	SentAt     time.Time  `json:"sentAt"`
+	AckedBy    string     `json:"ackedBy,omitempty"`
+	AckedAt    *time.Time `json:"ackedAt,omitempty"`
+	ResolvedBy string     `json:"resolvedBy,omitempty"`
+	ResolvedAt *time.Time `json:"resolvedAt,omitempty"`
+
+	Results []NotifierResult `json:"results,omitempty"` // 每个通知服务的投递结果
+}
+
+// ErrMessageAlreadyDone 消息已经被确认或解决，不能重复操作
+var ErrMessageAlreadyDone = errors.New("消息已处理")
+
+// IsMessageAction 判断是否为支持的确认操作
+func IsMessageAction(action string) bool {
+	return action == MessageActionAck || action == MessageActionResolve
+}
+
+// loadMessages 从数据目录恢复消息历史
+func (app *NotificationApp) loadMessages() {
+	if err := app.store.Load(messageStoreName, &app.messages); err != nil {
+		logger.Error("读取消息历史失败", "error", err)
+		app.messages = nil
+	}
+}
+
+// saveMessages 保存消息历史，调用方需要持有 messageMu
+func (app *NotificationApp) saveMessages() {
+	if err := app.store.Save(messageStoreName, app.messages); err != nil {
+		logger.Error("保存消息历史失败", "error", err)
+	}
+}
+
+// recordMessage 把消息记录到历史并生成消息 ID，应用开启确认按钮时附加签名链接
+func (app *NotificationApp) recordMessage(appConfig config.NotificationApp, route Route, message *notifier.NotificationMessage, targets []string) {
+	message.ID = newRandomID()
+	record := &MessageRecord{
+		ID:       message.ID,
+		AppID:    appConfig.AppID,
+		Title:    message.Title,
+		Severity: message.Severity,
+		Route:    route,
+		Targets:  targets,
+		Status:   MessagePending,
+		SentAt:   time.Now(),
+	}
+
+	app.messageMu.Lock()
+	app.messages = append(app.messages, record)
+	if limit := config.EnvCfg.MESSAGE_HISTORY_LIMIT; limit > 0 && len(app.messages) > limit {
+		app.messages = append([]*MessageRecord(nil), app.messages[len(app.messages)-limit:]...)
+	}
+	app.saveMessages()
+	app.messageMu.Unlock()
+
+	if appConfig.AckLinks {
+		expires := time.Now().Add(config.EnvCfg.ACK_LINK_TTL)
+		if ackURL := app.MessageActionURL(message.ID, MessageActionAck, expires); ackURL != "" {
+			message.Actions = append(message.Actions,
+				notifier.Action{Label: "✅ 确认", URL: ackURL, Style: notifier.ActionStylePrimary},
+				notifier.Action{Label: "☑️ 解决", URL: app.MessageActionURL(message.ID, MessageActionResolve, expires)},
+			)
+		}
+	}
+}
+
+// finishMessage 记录消息的发送结果，免打扰结束后发送的暂存消息只追加投递结果
+func (app *NotificationApp) finishMessage(id string, results []NotifierResult, sendErr error) {
+	app.messageMu.Lock()
+	defer app.messageMu.Unlock()
+
+	record := app.findMessage(id)
+	if record == nil {
+		return
+	}
+	record.Results = append(record.Results, results...)
+	if record.Status == MessagePending {
+		record.Status = MessageSent
+		if sendErr != nil {
+			record.Status = MessageFailed
+			record.Error = sendErr.Error()
+		}
+	}
+	app.saveMessages()
+}
+
+// findMessage 查找消息记录，调用方需要持有 messageMu
+func (app *NotificationApp) findMessage(id string) *MessageRecord {
+	for i := len(app.messages) - 1; i >= 0; i-- {
+		if app.messages[i].ID == id {
+			return app.messages[i]
+		}
+	}
+	return nil
+}
+
+// MessageActionURL 返回消息的签名确认链接，未配置 PUBLIC_BASE_URL 时返回空字符串
+func (app *NotificationApp) MessageActionURL(id, action string, expires time.Time) string {
+	baseURL := strings.TrimSuffix(config.EnvCfg.PUBLIC_BASE_URL, "/")
+	if baseURL == "" {
+		return ""
+	}
+	exp := strconv.FormatInt(expires.Unix(), 10)
+	return fmt.Sprintf("%s/api/v1/messages/%s/%s?expires=%s&sig=%s", baseURL, id, action, exp, app.signer.Sign(id, action, exp))
+}
+
+// VerifyMessageAction 校验确认链接的签名和有效期
+func (app *NotificationApp) VerifyMessageAction(id, action, expires, signature string) error {
+	if !IsMessageAction(action) {
+		return fmt.Errorf("无效的操作 %s", action)
+	}
+	exp, err := strconv.ParseInt(expires, 10, 64)
+	if err != nil || !app.signer.Verify(signature, id, action, expires) {
+		return fmt.Errorf("确认链接无效")
+	}
+	if time.Now().Unix() > exp {
+		return fmt.Errorf("确认链接已过期")
+	}
+	return nil
+}
+
+// AckMessage 确认或解决消息，同时停止该消息的升级，应用开启 ack_notify 时通知原通知服务
+func (app *NotificationApp) AckMessage(ctx context.Context, id, action, by string) (*MessageRecord, error) {
+	record, err := app.ackMessageRecord(id, action, by)
+	if err != nil {
+		return nil, err
+	}
+	app.ackEscalationsForMessage(id, by)
+	app.notifyAck(ctx, record, action, by)
+	return record, nil
+}
+
+// ackMessageRecord 在消息历史中记录确认人和时间，返回记录副本
+func (app *NotificationApp) ackMessageRecord(id, action, by string) (*MessageRecord, error) {
+	if !IsMessageAction(action) {
+		return nil, fmt.Errorf("无效的操作 %s", action)
+	}
+
+	app.messageMu.Lock()
+	defer app.messageMu.Unlock()
+
+	record := app.findMessage(id)
+	if record == nil {
+		return nil, fmt.Errorf("消息 %s 不存在", id)
+	}
+	now := time.Now()
+	switch action {
+	case MessageActionAck:
+		if record.AckedAt != nil {
+			return nil, fmt.Errorf("%w: 已被 %s 确认", ErrMessageAlreadyDone, record.AckedBy)
+		}
+		record.AckedBy, record.AckedAt = by, &now
+		if record.Status != MessageResolved {
+			record.Status = MessageAcked
+		}
+	case MessageActionResolve:
+		if record.ResolvedAt != nil {
+			return nil, fmt.Errorf("%w: 已被 %s 解决", ErrMessageAlreadyDone, record.ResolvedBy)
+		}
+		// 解决同时视为确认
+		if record.AckedAt == nil {
+			record.AckedBy, record.AckedAt = by, &now
+		}
+		record.ResolvedBy, record.ResolvedAt = by, &now
+		record.Status = MessageResolved
+	}
+	app.saveMessages()
+
+	result := *record
+	return &result, nil
+}
+
+// notifyAck 向消息原来的通知服务和目标发送确认通知
+func (app *NotificationApp) notifyAck(ctx context.Context, record *MessageRecord, action, by string) {
+	appConfig, exists := app.configManager.GetConfig().NotificationApps[record.AppID]
+	if !exists || !appConfig.AckNotify {
+		return
+	}
+
+	title := fmt.Sprintf("✅ %s 已确认: %s", by, record.Title)
+	if action == MessageActionResolve {
+		title = fmt.Sprintf("☑️ %s 已解决: %s", by, record.Title)
+	}
+	message := &notifier.NotificationMessage{
+		ID:        record.ID,
+		Title:     title,
+		Content:   title,
+		Timestamp: time.Now().Format("2006-01-02 15:04:05"),
+		Severity:  notifier.SeverityInfo,
+		Priority:  notifier.SeverityPriority(notifier.SeverityInfo),
+	}
+	if _, err := app.deliver(ctx, appConfig, record.Route, message, record.Targets); err != nil {
+		logger.Error("发送确认通知失败", "message", record.ID, "error", err)
+	}
+}
+
+// GetMessage 获取消息记录
+func (app *NotificationApp) GetMessage(id string) (*MessageRecord, bool) {
+	app.messageMu.Lock()
+	defer app.messageMu.Unlock()
+
+	record := app.findMessage(id)
+	if record == nil {
+		return nil, false
+	}
+	result := *record
+	return &result, true
+}
+
+// ListMessages 返回消息历史，最新的在前，appID 和 status 为空时不过滤
+func (app *NotificationApp) ListMessages(appID, status string, limit int) []MessageRecord {
+	app.messageMu.Lock()
+	defer app.messageMu.Unlock()
+
+	records := []MessageRecord{}
+	for i := len(app.messages) - 1; i >= 0; i-- {
+		record := app.messages[i]
+		if (appID == "" || record.AppID == appID) && (status == "" || record.Status == status) {
+			records = append(records, *record)
+			if limit > 0 && len(records) >= limit {
+				break
+			}
+		}
+	}
+	return records
+}
@@ -11,7 +11,11 @@ import (
 
 	"notify/internal/config"
 	"notify/internal/logger"
+	"notify/internal/media"
+	"notify/internal/metrics"
 	"notify/internal/notifier"
+	"notify/internal/source"
+	"notify/internal/store"
 )
 
 var funcMap = template.FuncMap{
@@ -113,21 +117,129 @@ var funcMap = template.FuncMap{
 
 // NotificationApp 通知应用
 type NotificationApp struct {
-	configManager *config.ConfigManager
-	notifiers     map[string]notifier.Notifier
+	configManager   *config.ConfigManager
+	notifiers       map[string]notifier.Notifier
+	store           *store.Store
+	media           *media.Store // 媒体存储，未配置公网地址时为 nil
+	templateHistory *TemplateHistory
+	signer          *Signer
+	scheduler       *Scheduler
+
+	messageMu sync.Mutex
+	messages  []*MessageRecord // 按发送时间升序
+
+	escalationMu sync.Mutex
+	escalations  map[string]*Escalation
+
+	dedupMu sync.Mutex
+	dedup   map[string]*DedupEntry
+
+	groupMu sync.Mutex
+	groups  map[string]*AlertGroup
+
+	digestMu sync.Mutex
+	digests  map[string]*DigestBuffer
+
+	deferredMu sync.Mutex
+	deferred   map[string]*DeferredMessage
+
+	heartbeatMu sync.Mutex
+	heartbeats  map[string]*HeartbeatState
+
+	breakerMu sync.Mutex
+	breakers  map[string]*circuitBreaker // 按通知服务实例名
+
+	limiterMu sync.Mutex
+	limiters  map[string]*notifierLimiter // 按通知服务实例名
+
+	requestMu       sync.Mutex
+	requestLimiters map[string]*requestLimiter // 按应用 ID
+	rejections      *metrics.CounterVec
+
+	idempotencyMu    sync.Mutex
+	idempotent       map[string]*IdempotentResult // 按应用 ID 和幂等键
+	inflight         map[string]*idempotentCall
+	idempotencyDirty bool // 有未保存的幂等键，由后台任务保存
+
+	stop chan struct{}
+	wg   sync.WaitGroup
 }
 
 // NewNotificationApp 创建通知应用实例
-func NewNotificationApp(configManager *config.ConfigManager) *NotificationApp {
-	app := &NotificationApp{
-		configManager: configManager,
-		notifiers:     make(map[string]notifier.Notifier),
+func NewNotificationApp(configManager *config.ConfigManager, dataStore *store.Store, mediaStore *media.Store) (*NotificationApp, error) {
+	signer, err := newSigner(dataStore)
+	if err != nil {
+		return nil, err
 	}
 
+	app := &NotificationApp{
+		configManager:   configManager,
+		notifiers:       make(map[string]notifier.Notifier),
+		store:           dataStore,
+		media:           mediaStore,
+		templateHistory: NewTemplateHistory(configManager, dataStore, config.EnvCfg.TEMPLATE_HISTORY_LIMIT),
+		signer:          signer,
+		breakers:        make(map[string]*circuitBreaker),
+		limiters:        make(map[string]*notifierLimiter),
+		requestLimiters: make(map[string]*requestLimiter),
+		rejections:      metrics.NewCounterVec("notify_requests_rejected_total", "超过请求限制被拒绝的通知请求数", "app", "scope", "reason"),
+	}
+	app.scheduler = NewScheduler(app, dataStore)
+
 	// 初始化通知服务
 	app.InitNotifiers()
+	app.loadMessages()
+	app.loadEscalations()
+	app.loadDedup()
+	app.loadGroups()
+	app.loadDigests()
+	app.loadDeferred()
+	app.loadHeartbeats()
+	app.loadIdempotency()
+
+	return app, nil
+}
 
-	return app
+// backgroundSendTimeout 后台任务发送通知的超时
+const backgroundSendTimeout = time.Minute
+
+// Start 启动后台任务，按间隔执行到期的升级步骤、去重窗口、分组、摘要、免打扰暂存的消息和心跳检查，并定期清理不再引用的附件
+func (app *NotificationApp) Start(interval time.Duration) {
+	app.stop = make(chan struct{})
+	app.wg.Add(1)
+	go func() {
+		defer app.wg.Done()
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		var attachmentsCleanedAt time.Time
+		for {
+			select {
+			case now := <-ticker.C:
+				app.processEscalations(now)
+				app.processDedup(now)
+				app.processGroups(now)
+				app.processDigests(now)
+				app.processDeferred(now)
+				app.processHeartbeats(now)
+				app.processIdempotency(now)
+				if now.Sub(attachmentsCleanedAt) >= attachmentCleanupInterval {
+					app.cleanupAttachments(now)
+					attachmentsCleanedAt = now
+				}
+			case <-app.stop:
+				return
+			}
+		}
+	}()
+}
+
+// Stop 停止后台任务，等待正在执行的任务结束，并保存还未保存的幂等键
+func (app *NotificationApp) Stop() {
+	if app.stop != nil {
+		close(app.stop)
+		app.wg.Wait()
+	}
+	app.processIdempotency(time.Now())
 }
 
 // initNotifiers 初始化通知服务
@@ -262,6 +374,12 @@ func (app *NotificationApp) parseDingTalkConfig(configData map[string]interface{
 	if targets, ok := configData["targets"].(string); ok {
 		cfg.Targets = targets
 	}
+	if appKey, ok := configData["app_key"].(string); ok {
+		cfg.AppKey = appKey
+	}
+	if appSecret, ok := configData["app_secret"].(string); ok {
+		cfg.AppSecret = appSecret
+	}
 
 	if cfg.AccessToken == "" {
 		return cfg, fmt.Errorf("钉钉配置不完整")
@@ -296,115 +414,325 @@ func (app *NotificationApp) parseFeishuConfig(configData map[string]interface{})
 	return cfg, nil
 }
 
+// SendResult 发送结果
+type SendResult struct {
+	Messages []*notifier.NotificationMessage `json:"messages"` // 已投递的消息
+	Skipped  int                             `json:"skipped"`  // 低于最低级别未发送的消息数
+
+	Suppressed int `json:"suppressed"` // 去重窗口内被抑制的消息数
+	Grouped    int `json:"grouped"`    // 加入分组等待合并发送的消息数
+	Digested   int `json:"digested"`   // 加入摘要缓存的消息数
+
+	Results    []NotifierResult `json:"results,omitempty"`    // 每个通知服务的投递结果
+	RuleErrors []RuleEvaluation `json:"ruleErrors,omitempty"` // 匹配出错的路由规则，出错的规则按未命中处理
+}
+
 // Send 发送通知
-func (app *NotificationApp) Send(ctx context.Context, appConfig config.NotificationApp, req *map[string]any) error {
+func (app *NotificationApp) Send(ctx context.Context, appConfig config.NotificationApp, req *map[string]any) (*SendResult, error) {
 	// 获取通知应用配置
 	appConfig, exists := app.configManager.GetConfig().NotificationApps[appConfig.AppID]
 	if !exists {
-		return fmt.Errorf("通知应用 %s 不存在", appConfig.Name)
+		return nil, fmt.Errorf("通知应用 %s 不存在", appConfig.Name)
 	}
 
 	if !appConfig.Enabled {
-		return fmt.Errorf("通知应用 %s 未启用", appConfig.Name)
+		return nil, fmt.Errorf("通知应用 %s 未启用", appConfig.Name)
 	}
 
-	// 根据TemplateID查找模板内容
-	template, err := app.getTemplateContent(appConfig.TemplateID)
+	// 按路由规则确定需要投递的通知服务和模板
+	routes, evaluations := app.resolveRoutes(appConfig, req)
+
+	result := &SendResult{}
+	for _, evaluation := range evaluations {
+		if evaluation.Error != "" {
+			logger.Error("路由规则匹配出错，按未命中处理", "app", appConfig.AppID, "rule", evaluation.Index, "name", evaluation.Name, "error", evaluation.Error)
+			result.RuleErrors = append(result.RuleErrors, evaluation)
+		}
+	}
+	var errorMsgs []string
+	for _, route := range routes {
+		message, targets, err := app.buildMessage(appConfig, route, req)
+		if err != nil {
+			// 一条路由渲染失败不影响其他路由的投递
+			logger.Error("渲染通知消息失败", "app", appConfig.AppID, "rule", route.Rule, "error", err)
+			errorMsgs = append(errorMsgs, err.Error())
+			continue
+		}
+		if belowMinSeverity(appConfig, message) {
+			logger.Debug("消息级别低于应用最低级别，跳过发送", "app", appConfig.AppID, "severity", message.Severity, "minSeverity", appConfig.MinSeverity)
+			result.Skipped++
+			continue
+		}
+		if app.suppressDuplicate(appConfig, route, message, targets, req) {
+			result.Suppressed++
+			continue
+		}
+		if app.addToDigest(ctx, appConfig, route, message, targets, req) {
+			result.Digested++
+			continue
+		}
+		if app.addToGroup(appConfig, route, message, targets, req) {
+			result.Grouped++
+			continue
+		}
+		results, err := app.dispatch(ctx, appConfig, route, message, targets)
+		if err != nil {
+			errorMsgs = append(errorMsgs, err.Error())
+		}
+		result.Messages = append(result.Messages, message)
+		result.Results = append(result.Results, results...)
+	}
+
+	// 如果有错误，返回合并的错误信息
+	if len(errorMsgs) > 0 {
+		return result, fmt.Errorf("发送通知时发生错误: %s", strings.Join(errorMsgs, "\n "))
+	}
+
+	return result, nil
+}
+
+// dispatch 托管媒体、记录消息历史后投递，配置了升级策略时由升级流程发送，未确认前按步骤继续通知
+func (app *NotificationApp) dispatch(ctx context.Context, appConfig config.NotificationApp, route Route, message *notifier.NotificationMessage, targets []string) ([]NotifierResult, error) {
+	app.hostMedia(ctx, appConfig, message)
+	app.recordMessage(appConfig, route, message, targets)
+	var (
+		results []NotifierResult
+		err     error
+	)
+	if appConfig.EscalationPolicy != "" {
+		err = app.startEscalation(ctx, appConfig, route, message, targets)
+	} else {
+		results, err = app.deliver(ctx, appConfig, route, message, targets)
+	}
+	app.finishMessage(message.ID, results, err)
+	return results, err
+}
+
+// buildMessage 使用路由对应的模板渲染通知消息和发送目标
+func (app *NotificationApp) buildMessage(appConfig config.NotificationApp, route Route, req *map[string]any) (*notifier.NotificationMessage, []string, error) {
+	// 根据TemplateID查找模板内容，固定了版本时使用历史版本
+	template, err := app.getRouteTemplate(route, req)
 	if err != nil {
-		return fmt.Errorf("获取模板失败: %w", err)
+		return nil, nil, fmt.Errorf("获取模板失败: %w", err)
 	}
-	title, err := app.renderTemplate(appConfig.TemplateID+"_title", template.Title, req)
+	title, err := app.renderTemplate(route.TemplateID+"_title", template.Title, req)
 	if err != nil {
-		return fmt.Errorf("渲染消息模板失败: %w", err)
+		return nil, nil, fmt.Errorf("渲染消息模板失败: %w", err)
 	}
 	// 渲染消息模板
-	content, err := app.renderTemplate(appConfig.TemplateID+"_content", template.Content, req)
+	content, err := app.renderTemplate(route.TemplateID+"_content", template.Content, req)
 	if err != nil {
-		return fmt.Errorf("渲染消息模板失败: %w", err)
+		return nil, nil, fmt.Errorf("渲染消息模板失败: %w", err)
 	}
-	url, _ := app.renderTemplate(appConfig.TemplateID+"_url", template.URL, req)
-	image, _ := app.renderTemplate(appConfig.TemplateID+"_image", template.Image, req)
+	url, _ := app.renderTemplate(route.TemplateID+"_url", template.URL, req)
+	image, _ := app.renderTemplate(route.TemplateID+"_image", template.Image, req)
 	if image == "" {
 		image = appConfig.DefaultImage
 	}
-	targetsStr, _ := app.renderTemplate(appConfig.TemplateID+"_targets", template.Targets, req)
+	// 路由规则指定了目标时覆盖模板中的目标
+	targetsTpl := template.Targets
+	if route.Targets != "" {
+		targetsTpl = route.Targets
+	}
+	targetsStr, _ := app.renderTemplate(route.TemplateID+"_targets", targetsTpl, req)
 	targets := []string{}
 	if targetsStr != "" {
 		targets = strings.Split(targetsStr, ",")
 	}
-	// 创建通知消息
-	message := &notifier.NotificationMessage{
-		Title:     title,
-		Content:   content,
-		Image:     image,
-		URL:       url,
-		Timestamp: time.Now().Format("2006-01-02 15:04:05"),
+	attachments, err := parseAttachments(*req)
+	if err != nil {
+		return nil, nil, err
 	}
-	if len(appConfig.Notifiers) == 0 {
-		return fmt.Errorf("通知应用 %s 未配置任何通知服务", appConfig.Name)
+	// 模板没有配置按钮时使用请求数据中的 actions
+	actions, err := app.renderActions(route.TemplateID, template.Actions, req)
+	if err != nil {
+		return nil, nil, err
 	}
-	// 发送到配置的通知服务 - 并发发送，最多10个协程
-	const maxConcurrentNotifiers = 10
-
-	var (
-		errors    []error
-		errorsMux sync.Mutex // 保护errors切片的并发安全
-		wg        sync.WaitGroup
-		semaphore = make(chan struct{}, maxConcurrentNotifiers) // 信号量，限制并发数
-	)
+	if len(template.Actions) == 0 {
+		actions = parseActions(*req)
+	}
+	severity, priority, err := app.renderSeverity(route.TemplateID, template, req)
+	if err != nil {
+		return nil, nil, err
+	}
+	mentions, err := app.renderMentions(route.TemplateID, template, req)
+	if err != nil {
+		return nil, nil, err
+	}
+	// 创建通知消息
+	message := &notifier.NotificationMessage{
+		Title:       title,
+		Content:     content,
+		Image:       image,
+		URL:         url,
+		Severity:    severity,
+		Priority:    priority,
+		Timestamp:   time.Now().Format("2006-01-02 15:04:05"),
+		Attachments: attachments,
+		Actions:     actions,
+		Mentions:    mentions,
+	}
+	return message, targets, nil
+}
 
-	// 遍历所有通知服务，为每个启动一个协程
-	for _, notifierName := range appConfig.Notifiers {
-		// 提前检查通知服务是否存在和启用
-		notifierInstance, exists := app.notifiers[notifierName]
-		if !exists {
-			errorsMux.Lock()
-			errors = append(errors, fmt.Errorf("通知服务 %s 不存在", notifierName))
-			errorsMux.Unlock()
+// renderActions 渲染模板中的按钮，标签或链接为空的按钮会被忽略
+func (app *NotificationApp) renderActions(templateID string, actionTemplates []config.ActionTemplate, req *map[string]any) ([]notifier.Action, error) {
+	actions := make([]notifier.Action, 0, len(actionTemplates))
+	for i, tpl := range actionTemplates {
+		if tpl.Label == "" || tpl.URL == "" {
 			continue
 		}
-
-		if !notifierInstance.IsEnabled() {
-			errorsMux.Lock()
-			errors = append(errors, fmt.Errorf("通知服务 %s 未启用", notifierName))
-			errorsMux.Unlock()
+		name := fmt.Sprintf("%s_action_%d", templateID, i)
+		label, err := app.renderTemplate(name+"_label", tpl.Label, req)
+		if err != nil {
+			return nil, fmt.Errorf("渲染按钮 %d 失败: %w", i+1, err)
+		}
+		url, err := app.renderTemplate(name+"_url", tpl.URL, req)
+		if err != nil {
+			return nil, fmt.Errorf("渲染按钮 %d 失败: %w", i+1, err)
+		}
+		style := tpl.Style
+		if style != "" {
+			if style, err = app.renderTemplate(name+"_style", tpl.Style, req); err != nil {
+				return nil, fmt.Errorf("渲染按钮 %d 失败: %w", i+1, err)
+			}
+		}
+		label, url = strings.TrimSpace(label), strings.TrimSpace(url)
+		if label == "" || url == "" {
 			continue
 		}
+		actions = append(actions, notifier.Action{Label: label, URL: url, Style: strings.TrimSpace(style)})
+	}
+	return actions, nil
+}
 
-		// 为每个有效的通知服务启动协程
+// deliver 把消息并发发送到路由中的通知服务，路由中的组名按组的投递策略发送，返回每个通知服务的投递结果
+func (app *NotificationApp) deliver(ctx context.Context, appConfig config.NotificationApp, route Route, message *notifier.NotificationMessage, targets []string) ([]NotifierResult, error) {
+	if len(route.Notifiers) == 0 {
+		return nil, fmt.Errorf("通知应用 %s 未配置任何通知服务", appConfig.Name)
+	}
+
+	return app.deliverUnits(ctx, appConfig, route, message, targets, deliveryUnits(appConfig, route.Notifiers))
+}
+
+// deliverUnits 并发执行投递单元，返回所有通知服务的结果
+func (app *NotificationApp) deliverUnits(ctx context.Context, appConfig config.NotificationApp, route Route, message *notifier.NotificationMessage, targets []string, units []deliveryUnit) ([]NotifierResult, error) {
+	d := app.newDelivery(appConfig, route, message, targets)
+	unitResults := make([][]NotifierResult, len(units))
+	unitErrors := make([]error, len(units))
+
+	// 各投递单元互不影响，并发执行
+	var wg sync.WaitGroup
+	for i, unit := range units {
 		wg.Add(1)
-		go func(name string, notifier notifier.Notifier) {
+		go func(i int, unit deliveryUnit) {
 			defer wg.Done()
+			unitResults[i], unitErrors[i] = d.run(ctx, unit)
+		}(i, unit)
+	}
+	wg.Wait()
+
+	var (
+		results   []NotifierResult
+		errorMsgs []string
+	)
+	for i := range units {
+		results = append(results, unitResults[i]...)
+		if unitErrors[i] != nil {
+			errorMsgs = append(errorMsgs, unitErrors[i].Error())
+		}
+	}
+	if len(errorMsgs) > 0 {
+		return results, fmt.Errorf("%s", strings.Join(errorMsgs, "\n "))
+	}
+	return results, nil
+}
+
+// RoutePreview 路由试运行时单个投递的预览
+type RoutePreview struct {
+	Route   Route                         `json:"route"`
+	Message *notifier.NotificationMessage `json:"message,omitempty"`
+	Targets []string                      `json:"targets"`
+	Skipped string                        `json:"skipped,omitempty"` // 不会发送的原因
+	Error   string                        `json:"error,omitempty"`
+}
 
-			// 获取信号量，控制并发数
-			semaphore <- struct{}{}
-			defer func() { <-semaphore }()
+// RouteDryRunResult 路由试运行结果
+type RouteDryRunResult struct {
+	Data   map[string]any   `json:"data"` // 参与匹配的通知数据
+	Rules  []RuleEvaluation `json:"rules"`
+	Routes []RoutePreview   `json:"routes"`
+}
 
-			// 发送通知
-			if err := notifier.Send(ctx, message, targets); err != nil {
-				errorsMux.Lock()
-				errors = append(errors, fmt.Errorf("通知服务 %s 发送失败: %w", name, err))
-				errorsMux.Unlock()
+// DryRunRoutes 使用示例数据匹配路由规则并渲染消息，不会真正发送
+func (app *NotificationApp) DryRunRoutes(appConfig config.NotificationApp, req *map[string]any) *RouteDryRunResult {
+	routes, evaluations := app.resolveRoutes(appConfig, req)
+	result := &RouteDryRunResult{
+		Data:   *req,
+		Rules:  evaluations,
+		Routes: make([]RoutePreview, 0, len(routes)),
+	}
+	if result.Rules == nil {
+		result.Rules = []RuleEvaluation{}
+	}
+
+	for _, route := range routes {
+		preview := RoutePreview{Route: route, Targets: []string{}}
+		message, targets, err := app.buildMessage(appConfig, route, req)
+		if err != nil {
+			preview.Error = err.Error()
+		} else {
+			preview.Message = message
+			preview.Targets = targets
+			if belowMinSeverity(appConfig, message) {
+				preview.Skipped = fmt.Sprintf("消息级别低于应用最低级别 %s", appConfig.MinSeverity)
 			}
-		}(notifierName, notifierInstance)
+		}
+		result.Routes = append(result.Routes, preview)
 	}
+	return result
+}
 
-	// 等待所有协程完成
-	wg.Wait()
+// ApplySource 使用数据源适配器把请求转换为一条或多条通知数据
+// sourceName 为空时使用应用配置的数据源，都未配置时原样返回请求数据
+// 应用配置了数据源时只能使用该数据源，避免指定其他适配器绕过 webhook 签名校验
+func (app *NotificationApp) ApplySource(appConfig config.NotificationApp, sourceName string, req *source.Request) ([]map[string]any, error) {
+	if appConfig.Source != nil && appConfig.Source.Type != "" {
+		if sourceName != "" && !sameSource(sourceName, appConfig.Source.Type) {
+			return nil, fmt.Errorf("%w: 应用已配置数据源 %s，不能使用 %s", source.ErrUnauthorized, appConfig.Source.Type, sourceName)
+		}
+		sourceName = appConfig.Source.Type
+	}
 
-	// 如果有错误，返回合并的错误信息
-	if len(errors) > 0 {
-		var errorMsg string
-		for i, err := range errors {
-			if i > 0 {
-				errorMsg += "\n "
-			}
-			errorMsg += err.Error()
+	items := []map[string]any{req.Data}
+	if sourceName != "" {
+		adapter, ok := source.Get(sourceName)
+		if !ok {
+			return nil, fmt.Errorf("不支持的数据源: %s", sourceName)
+		}
+
+		var err error
+		items, err = adapter.Parse(req, appConfig.Source)
+		if err != nil {
+			return nil, fmt.Errorf("数据源 %s 解析失败: %w", adapter.Name(), err)
 		}
-		return fmt.Errorf("发送通知时发生错误: %s", errorMsg)
 	}
 
-	return nil
+	// 请求信息放在 .request 下，不覆盖请求数据中的同名字段
+	requestContext := req.Context()
+	for _, item := range items {
+		if _, exists := item["request"]; !exists {
+			item["request"] = requestContext
+		}
+	}
+	return items, nil
+}
+
+// sameSource 判断两个数据源名称是否指向同一个适配器，名称可以是别名
+func sameSource(a, b string) bool {
+	adapterA, okA := source.Get(a)
+	adapterB, okB := source.Get(b)
+	return okA && okB && adapterA.Name() == adapterB.Name()
 }
 
 // renderTemplate 渲染消息模板
@@ -444,6 +772,15 @@ func (app *NotificationApp) getTemplateContent(templateID string) (*config.Messa
 		return nil, fmt.Errorf("模板ID不能为空")
 	}
 
+	// 数据源适配器自带的内置模板
+	if strings.HasPrefix(templateID, source.BuiltinTemplatePrefix) {
+		template, err := source.BuiltinTemplate(templateID)
+		if err != nil {
+			return nil, err
+		}
+		return &template, nil
+	}
+
 	template, exists := app.configManager.GetConfig().Templates[templateID]
 	if !exists {
 		return nil, fmt.Errorf("模板ID '%s' 不存在", templateID)
@@ -452,8 +789,92 @@ func (app *NotificationApp) getTemplateContent(templateID string) (*config.Messa
 	return &template, nil
 }
 
+// getRouteTemplate 获取路由使用的模板，固定了版本时从历史中读取
+// 未配置模板但数据来自数据源适配器时，使用适配器的内置模板
+func (app *NotificationApp) getRouteTemplate(route Route, data *map[string]any) (*config.MessageTemplate, error) {
+	if route.TemplateID == "" {
+		if name, ok := (*data)["source"].(string); ok {
+			if _, exists := source.Get(name); exists {
+				return app.getTemplateContent(source.BuiltinTemplatePrefix + name)
+			}
+		}
+	}
+	if route.TemplateVersion <= 0 {
+		return app.getTemplateContent(route.TemplateID)
+	}
+
+	version, err := app.templateHistory.Get(route.TemplateID, route.TemplateVersion)
+	if err != nil {
+		return nil, err
+	}
+	return &version.Template, nil
+}
+
+// TemplateHistory 获取模板版本历史管理器
+func (app *NotificationApp) TemplateHistory() *TemplateHistory {
+	return app.templateHistory
+}
+
+// Scheduler 返回定时通知调度器
+func (app *NotificationApp) Scheduler() *Scheduler {
+	return app.scheduler
+}
+
+// ValidateRules 验证应用路由规则引用的模板是否存在、匹配条件和条件表达式是否有效
+func (app *NotificationApp) ValidateRules(appConfig config.NotificationApp) error {
+	for i, rule := range appConfig.Rules {
+		if rule.TemplateID != "" {
+			if _, err := app.getTemplateContent(rule.TemplateID); err != nil {
+				return fmt.Errorf("路由规则 %d 引用了无效的模板: %v", i, err)
+			}
+		}
+		if rule.Match != "" {
+			if _, err := parseFieldConditions(rule.Match); err != nil {
+				return fmt.Errorf("路由规则 %d 配置错误: %v", i, err)
+			}
+		}
+		if rule.Condition != "" {
+			if _, err := template.New("condition").Funcs(funcMap).Parse(rule.Condition); err != nil {
+				return fmt.Errorf("路由规则 %d 的条件表达式无效: %v", i, err)
+			}
+		}
+	}
+	return nil
+}
+
 // ValidateConfig 验证配置
 func (app *NotificationApp) ValidateConfig() error {
+	// 验证免打扰策略，时区无效时免打扰不会生效
+	for id, quietHours := range app.configManager.GetConfig().QuietHours {
+		if err := quietHours.Validate(); err != nil {
+			return fmt.Errorf("免打扰策略 %s 配置错误: %v", id, err)
+		}
+	}
+
+	// 验证模板、路由规则、值班表等引用的联系人是否存在
+	if dangling := app.configManager.GetConfig().DanglingContactRefs(); len(dangling) > 0 {
+		return fmt.Errorf("引用了不存在的联系人: %v", dangling)
+	}
+
+	// 验证定时任务
+	for id, job := range app.configManager.ScheduledJobs() {
+		if err := app.configManager.GetConfig().ValidateScheduledJob(job); err != nil {
+			return fmt.Errorf("定时任务 %s 配置错误: %v", id, err)
+		}
+	}
+
+	// 验证值班表和升级策略
+	for id, schedule := range app.configManager.GetConfig().Schedules {
+		if err := app.configManager.GetConfig().ValidateSchedule(schedule); err != nil {
+			return fmt.Errorf("值班表 %s 配置错误: %v", id, err)
+		}
+	}
+	for id, policy := range app.configManager.GetConfig().EscalationPolicies {
+		if err := app.configManager.GetConfig().ValidateEscalationPolicy(policy); err != nil {
+			return fmt.Errorf("升级策略 %s 配置错误: %v", id, err)
+		}
+	}
+
 	// 验证通知服务配置
 	for instanceName, instance := range app.configManager.GetConfig().Notifiers {
 		if !instance.Enabled {
@@ -484,6 +905,12 @@ func (app *NotificationApp) ValidateConfig() error {
 		default:
 			return fmt.Errorf("通知服务实例 %s 使用了未知的类型: %s", instanceName, instance.Type)
 		}
+		if _, err := instance.RateLimit(); err != nil {
+			return fmt.Errorf("通知服务实例 %s 限流配置错误: %v", instanceName, err)
+		}
+		if err := app.configManager.GetConfig().ValidateQuietHoursRef(instance.QuietHoursID()); err != nil {
+			return fmt.Errorf("通知服务实例 %s 配置错误: %v", instanceName, err)
+		}
 	}
 
 	// 验证通知应用配置
@@ -502,10 +929,50 @@ func (app *NotificationApp) ValidateConfig() error {
 		// 	return fmt.Errorf("通知应用 %s 未配置任何通知服务", name)
 		// }
 
-		// 验证引用的通知服务实例是否存在
-		for _, notifierName := range appConfig.Notifiers {
-			if _, exists := app.configManager.GetConfig().Notifiers[notifierName]; !exists {
-				return fmt.Errorf("通知应用 %s 引用了不存在的通知服务实例: %s", name, notifierName)
+		// 验证引用的通知服务实例和通知服务组是否存在
+		if err := app.configManager.GetConfig().ValidateNotifierGroups(appConfig); err != nil {
+			return fmt.Errorf("通知应用 %s 配置错误: %v", name, err)
+		}
+		if err := appConfig.ValidateAggregation(); err != nil {
+			return fmt.Errorf("通知应用 %s 配置错误: %v", name, err)
+		}
+		if err := appConfig.ValidateRateLimit(); err != nil {
+			return fmt.Errorf("通知应用 %s %v", name, err)
+		}
+
+		if err := app.configManager.GetConfig().ValidateQuietHoursRef(appConfig.QuietHours); err != nil {
+			return fmt.Errorf("通知应用 %s 配置错误: %v", name, err)
+		}
+
+		if _, exists := app.configManager.GetConfig().EscalationPolicies[appConfig.EscalationPolicy]; appConfig.EscalationPolicy != "" && !exists {
+			return fmt.Errorf("通知应用 %s 配置错误: 升级策略 %s 不存在", name, appConfig.EscalationPolicy)
+		}
+
+		if appConfig.MinSeverity != "" && !notifier.IsSeverity(appConfig.MinSeverity) {
+			return fmt.Errorf("通知应用 %s 配置了无效的最低级别 %s，可选 info、notice、warning、error、critical", name, appConfig.MinSeverity)
+		}
+
+		// 验证应用和路由规则的摘要配置
+		if err := app.configManager.GetConfig().ValidateDigest(appConfig); err != nil {
+			return fmt.Errorf("通知应用 %s 配置错误: %v", name, err)
+		}
+
+		// 验证路由规则引用的模板和匹配条件
+		if err := app.ValidateRules(appConfig); err != nil {
+			return fmt.Errorf("通知应用 %s 的%v", name, err)
+		}
+
+		// 验证数据源类型
+		if appConfig.Source != nil && appConfig.Source.Type != "" {
+			if _, ok := source.Get(appConfig.Source.Type); !ok {
+				return fmt.Errorf("通知应用 %s 使用了不支持的数据源: %s", name, appConfig.Source.Type)
+			}
+		}
+
+		// 验证固定的模板版本是否存在
+		if appConfig.TemplateVersion > 0 {
+			if _, err := app.templateHistory.Get(appConfig.TemplateID, appConfig.TemplateVersion); err != nil {
+				return fmt.Errorf("通知应用 %s 固定的模板版本无效: %v", name, err)
 			}
 		}
 
@@ -0,0 +1,194 @@
+package app
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"notify/internal/config"
+	"notify/internal/logger"
+	"notify/internal/notifier"
+)
+
+// deferredStoreName 免打扰期间暂存的消息在数据目录中的文件名
+const deferredStoreName = "deferred"
+
+// DeferredMessage 免打扰期间暂存的消息，免打扰结束后发送
+type DeferredMessage struct {
+	ID         string                        `json:"id"`
+	AppID      string                        `json:"appId"`
+	QuietHours string                        `json:"quietHours"` // 生效的免打扰策略
+	Route      Route                         `json:"route"`      // 只包含被暂存的通知服务
+	Targets    []string                      `json:"targets,omitempty"`
+	Message    *notifier.NotificationMessage `json:"message"`
+	CreatedAt  time.Time                     `json:"createdAt"`
+	ReleaseAt  time.Time                     `json:"releaseAt"`
+
+	// 通知服务组整体被暂存时记录组的投递策略，发送时按策略投递到 Route 中的主通知服务和 Fallback
+	Group    string   `json:"group,omitempty"`
+	Strategy string   `json:"strategy,omitempty"`
+	Fallback []string `json:"fallback,omitempty"`
+}
+
+// loadDeferred 从数据目录恢复暂存的消息
+func (app *NotificationApp) loadDeferred() {
+	app.deferred = make(map[string]*DeferredMessage)
+	if err := app.store.Load(deferredStoreName, &app.deferred); err != nil {
+		logger.Error("读取暂存消息失败", "error", err)
+		app.deferred = make(map[string]*DeferredMessage)
+	}
+	for _, entry := range app.deferred {
+		app.restoreAttachments(entry.Message)
+	}
+}
+
+// saveDeferred 保存暂存的消息，调用方需要持有 deferredMu
+func (app *NotificationApp) saveDeferred() {
+	if err := app.store.Save(deferredStoreName, app.deferred); err != nil {
+		logger.Error("保存暂存消息失败", "error", err)
+	}
+}
+
+// activeQuietHours 返回通知服务当前生效的免打扰策略和结束时间，应用的策略优先于通知服务的策略
+// 消息级别达到策略的 bypass_severity 时该策略不生效
+func (app *NotificationApp) activeQuietHours(appConfig config.NotificationApp, notifierName string, message *notifier.NotificationMessage, now time.Time) (string, config.QuietHours, time.Time, bool) {
+	cfg := app.configManager.GetConfig()
+	for _, id := range []string{appConfig.QuietHours, cfg.Notifiers[notifierName].QuietHoursID()} {
+		if id == "" {
+			continue
+		}
+		policy, exists := cfg.QuietHours[id]
+		if !exists {
+			continue
+		}
+		if policy.BypassSeverity != "" && notifier.SeverityRank(message.Severity) >= notifier.SeverityRank(policy.BypassSeverity) {
+			continue
+		}
+		if until, quiet := policy.QuietUntil(now); quiet {
+			return id, policy, until, true
+		}
+	}
+	return "", config.QuietHours{}, time.Time{}, false
+}
+
+// applyQuietHours 按免打扰策略处理路由中的通知服务，返回需要立即发送的通知服务和其中需要静默发送的通知服务
+// 需要暂存的通知服务按策略和结束时间合并为一条暂存消息，需要丢弃的通知服务直接跳过
+// 通知服务全部被暂存且 release 不是 all 策略时，暂存消息记录 release 的组、策略和主备通知服务，免打扰结束后按策略投递
+func (app *NotificationApp) applyQuietHours(appConfig config.NotificationApp, route Route, message *notifier.NotificationMessage, targets []string, release deliveryUnit) ([]string, map[string]bool) {
+	now := time.Now()
+	send := make([]string, 0, len(route.Notifiers))
+	silent := make(map[string]bool)
+	deferred := make(map[string]*DeferredMessage)
+
+	for _, name := range route.Notifiers {
+		id, policy, until, quiet := app.activeQuietHours(appConfig, name, message, now)
+		if !quiet {
+			send = append(send, name)
+			continue
+		}
+		switch policy.EffectiveMode() {
+		case config.QuietModeDrop:
+			logger.Info("免打扰期间丢弃消息", "app", appConfig.AppID, "notifier", name, "quietHours", id)
+		case config.QuietModeSilent:
+			send = append(send, name)
+			silent[name] = true
+		default:
+			key := id + "\n" + until.String()
+			entry, exists := deferred[key]
+			if !exists {
+				entry = &DeferredMessage{
+					ID:         newRandomID(),
+					AppID:      appConfig.AppID,
+					QuietHours: id,
+					Route:      route,
+					Targets:    targets,
+					Message:    message.Clone(),
+					CreatedAt:  now,
+					ReleaseAt:  until,
+				}
+				entry.Route.Notifiers = nil
+				deferred[key] = entry
+			}
+			entry.Route.Notifiers = append(entry.Route.Notifiers, name)
+		}
+	}
+
+	if release.strategy != config.DeliveryAll && len(deferred) == 1 {
+		for _, entry := range deferred {
+			if len(entry.Route.Notifiers) == len(route.Notifiers) {
+				entry.Group, entry.Strategy = release.group, release.strategy
+				entry.Route.Notifiers, entry.Fallback = release.primary, release.fallback
+			}
+		}
+	}
+
+	if len(deferred) > 0 {
+		app.deferredMu.Lock()
+		for _, entry := range deferred {
+			app.persistAttachments(entry.Message)
+			app.deferred[entry.ID] = entry
+			logger.Info("免打扰期间暂存消息", "app", appConfig.AppID, "notifiers", entry.Route.Notifiers, "quietHours", entry.QuietHours, "releaseAt", entry.ReleaseAt)
+		}
+		app.saveDeferred()
+		app.deferredMu.Unlock()
+	}
+	return send, silent
+}
+
+// processDeferred 发送免打扰已结束的暂存消息
+func (app *NotificationApp) processDeferred(now time.Time) {
+	cfg := app.configManager.GetConfig()
+
+	app.deferredMu.Lock()
+	var due []*DeferredMessage
+	for id, entry := range app.deferred {
+		if now.Before(entry.ReleaseAt) {
+			continue
+		}
+		due = append(due, entry)
+		delete(app.deferred, id)
+	}
+	if len(due) > 0 {
+		app.saveDeferred()
+	}
+	app.deferredMu.Unlock()
+
+	sort.Slice(due, func(i, j int) bool {
+		return due[i].CreatedAt.Before(due[j].CreatedAt)
+	})
+	for _, entry := range due {
+		appConfig, exists := cfg.NotificationApps[entry.AppID]
+		if !exists {
+			logger.Warn("暂存消息的通知应用不存在，丢弃", "app", entry.AppID, "id", entry.ID)
+			continue
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), backgroundSendTimeout)
+		units := deliveryUnits(appConfig, entry.Route.Notifiers)
+		if entry.Strategy != "" {
+			units = []deliveryUnit{{group: entry.Group, strategy: entry.Strategy, primary: entry.Route.Notifiers, fallback: entry.Fallback}}
+		}
+		results, err := app.deliverUnits(ctx, appConfig, entry.Route, entry.Message, entry.Targets, units)
+		if err != nil {
+			logger.Error("发送暂存消息失败", "app", entry.AppID, "id", entry.ID, "error", err)
+		}
+		app.finishMessage(entry.Message.ID, results, err)
+		cancel()
+	}
+}
+
+// DeferredMessages 返回免打扰期间暂存的消息，按结束时间升序，appID 为空时返回全部
+func (app *NotificationApp) DeferredMessages(appID string) []DeferredMessage {
+	app.deferredMu.Lock()
+	defer app.deferredMu.Unlock()
+
+	messages := []DeferredMessage{}
+	for _, entry := range app.deferred {
+		if appID == "" || entry.AppID == appID {
+			messages = append(messages, *entry)
+		}
+	}
+	sort.Slice(messages, func(i, j int) bool {
+		return messages[i].ReleaseAt.Before(messages[j].ReleaseAt)
+	})
+	return messages
+}
@@ -0,0 +1,241 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"reflect"
+	"sort"
+	"sync"
+	"time"
+
+	"notify/internal/config"
+	"notify/internal/logger"
+	"notify/internal/metrics"
+)
+
+// maxIdleTargetBuckets 每个通知服务保留的发送目标令牌桶数量，超过时清理已回满的令牌桶
+const maxIdleTargetBuckets = 256
+
+// tokenBucket 令牌桶，令牌可以预支为负数，按预支的数量计算需要等待的时间
+type tokenBucket struct {
+	rate   float64 // 每秒补充的令牌数
+	burst  float64
+	tokens float64
+	last   time.Time
+}
+
+// newTokenBucket 创建装满令牌的令牌桶
+func newTokenBucket(limit config.RateLimit, now time.Time) *tokenBucket {
+	rate, burst, _ := limit.PerSecond()
+	return &tokenBucket{rate: rate, burst: burst, tokens: burst, last: now}
+}
+
+// refill 按经过的时间补充令牌
+func (b *tokenBucket) refill(now time.Time) {
+	if now.After(b.last) {
+		b.tokens = math.Min(b.burst, b.tokens+now.Sub(b.last).Seconds()*b.rate)
+		b.last = now
+	}
+}
+
+// reserve 预支 n 个令牌，返回令牌可用前需要等待的时间
+func (b *tokenBucket) reserve(n float64, now time.Time) time.Duration {
+	b.refill(now)
+	b.tokens -= n
+	if b.tokens >= 0 {
+		return 0
+	}
+	return time.Duration(-b.tokens / b.rate * float64(time.Second))
+}
+
+// notifierLimiter 单个通知服务的发送限流
+type notifierLimiter struct {
+	limit   config.NotifierRateLimit
+	maxWait time.Duration
+
+	mu      sync.Mutex
+	bucket  *tokenBucket
+	targets map[string]*tokenBucket
+
+	waiting  int           // 正在排队的消息数
+	waits    int           // 需要排队的消息总数
+	waitTime time.Duration // 累计排队时间
+	timeouts int           // 排队超过最长等待时间的消息数
+}
+
+// RateLimitStatus 通知服务的限流状态
+type RateLimitStatus struct {
+	Rate      float64 `json:"rate"`    // 每秒补充的令牌数
+	Burst     float64 `json:"burst"`   // 桶容量
+	Tokens    float64 `json:"tokens"`  // 当前可用的令牌数，为负数时表示已预支给排队中的消息
+	Waiting   int     `json:"waiting"` // 正在排队的消息数
+	Waits     int     `json:"waits"`   // 需要排队的消息总数
+	WaitTime  float64 `json:"waitSeconds"`
+	Timeouts  int     `json:"timeouts"` // 排队超时发送失败的消息数
+	PerTarget bool    `json:"perTarget"`
+}
+
+// limiter 返回通知服务的限流器，限流配置变化时重新创建，没有限流时返回 nil
+func (app *NotificationApp) limiter(name string) *notifierLimiter {
+	limit, err := app.configManager.GetConfig().Notifiers[name].RateLimit()
+	if err != nil {
+		logger.Warn("通知服务的限流配置无效，不限流", "notifier", name, "error", err)
+	}
+
+	app.limiterMu.Lock()
+	defer app.limiterMu.Unlock()
+
+	if limit == nil {
+		delete(app.limiters, name)
+		return nil
+	}
+	current, exists := app.limiters[name]
+	if exists && reflect.DeepEqual(current.limit, *limit) {
+		return current
+	}
+	maxWait, _ := limit.MaxWaitDuration()
+	current = &notifierLimiter{
+		limit:   *limit,
+		maxWait: maxWait,
+		bucket:  newTokenBucket(limit.RateLimit, time.Now()),
+		targets: make(map[string]*tokenBucket),
+	}
+	app.limiters[name] = current
+	return current
+}
+
+// wait 为发送到 targets 的消息排队，通知服务整体按目标数消耗令牌，每个目标再消耗自己的令牌
+// 需要等待的时间超过最长等待时间或 ctx 结束时归还令牌并返回错误
+func (l *notifierLimiter) wait(ctx context.Context, targets []string) error {
+	now := time.Now()
+	keys := targets
+	if len(keys) == 0 {
+		keys = []string{""} // 通知服务配置的默认目标
+	}
+	n := float64(len(keys))
+
+	l.mu.Lock()
+	delay := l.bucket.reserve(n, now)
+	var reserved []*tokenBucket
+	if l.limit.PerTarget != nil {
+		for _, key := range keys {
+			bucket := l.targetBucket(key, now)
+			reserved = append(reserved, bucket)
+			if d := bucket.reserve(1, now); d > delay {
+				delay = d
+			}
+		}
+	}
+	release := func() {
+		l.bucket.tokens += n
+		for _, bucket := range reserved {
+			bucket.tokens++
+		}
+	}
+	if delay == 0 {
+		l.mu.Unlock()
+		return nil
+	}
+	if delay > l.maxWait {
+		release()
+		l.timeouts++
+		l.mu.Unlock()
+		return fmt.Errorf("超过发送频率限制，需要排队 %s，超过最长等待时间 %s", delay.Round(time.Millisecond), l.maxWait)
+	}
+	l.waiting++
+	l.waits++
+	l.mu.Unlock()
+
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		l.mu.Lock()
+		l.waiting--
+		l.waitTime += delay
+		l.mu.Unlock()
+		return nil
+	case <-ctx.Done():
+		l.mu.Lock()
+		l.waiting--
+		release()
+		l.mu.Unlock()
+		return fmt.Errorf("等待发送频率限制时取消: %w", ctx.Err())
+	}
+}
+
+// targetBucket 返回发送目标的令牌桶，调用方需要持有 mu
+func (l *notifierLimiter) targetBucket(key string, now time.Time) *tokenBucket {
+	if bucket, exists := l.targets[key]; exists {
+		return bucket
+	}
+	if len(l.targets) >= maxIdleTargetBuckets {
+		for k, bucket := range l.targets {
+			bucket.refill(now)
+			if bucket.tokens >= bucket.burst {
+				delete(l.targets, k)
+			}
+		}
+	}
+	bucket := newTokenBucket(*l.limit.PerTarget, now)
+	l.targets[key] = bucket
+	return bucket
+}
+
+// status 返回限流器当前状态
+func (l *notifierLimiter) status(now time.Time) RateLimitStatus {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.bucket.refill(now)
+	return RateLimitStatus{
+		Rate:      l.bucket.rate,
+		Burst:     l.bucket.burst,
+		Tokens:    l.bucket.tokens,
+		Waiting:   l.waiting,
+		Waits:     l.waits,
+		WaitTime:  l.waitTime.Seconds(),
+		Timeouts:  l.timeouts,
+		PerTarget: l.limit.PerTarget != nil,
+	}
+}
+
+// RateLimitStatuses 返回所有启用了限流的通知服务的限流状态
+func (app *NotificationApp) RateLimitStatuses() map[string]RateLimitStatus {
+	now := time.Now()
+	statuses := make(map[string]RateLimitStatus)
+	for name := range app.configManager.GetConfig().Notifiers {
+		if limiter := app.limiter(name); limiter != nil {
+			statuses[name] = limiter.status(now)
+		}
+	}
+	return statuses
+}
+
+// RateLimitMetrics 返回限流状态的监控指标
+func (app *NotificationApp) RateLimitMetrics() []metrics.Family {
+	statuses := app.RateLimitStatuses()
+	names := make([]string, 0, len(statuses))
+	for name := range statuses {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	families := []metrics.Family{
+		{Name: "notify_rate_limit_tokens", Help: "通知服务令牌桶当前可用的令牌数", Type: metrics.TypeGauge},
+		{Name: "notify_rate_limit_burst", Help: "通知服务令牌桶容量", Type: metrics.TypeGauge},
+		{Name: "notify_rate_limit_waiting", Help: "正在排队等待发送的消息数", Type: metrics.TypeGauge},
+		{Name: "notify_rate_limit_waits_total", Help: "因限流排队的消息总数", Type: metrics.TypeCounter},
+		{Name: "notify_rate_limit_wait_seconds_total", Help: "因限流排队的累计时间", Type: metrics.TypeCounter},
+		{Name: "notify_rate_limit_timeouts_total", Help: "排队超过最长等待时间而发送失败的消息数", Type: metrics.TypeCounter},
+	}
+	for _, name := range names {
+		status := statuses[name]
+		labels := map[string]string{"notifier": name}
+		for i, value := range []float64{status.Tokens, status.Burst, float64(status.Waiting), float64(status.Waits), status.WaitTime, float64(status.Timeouts)} {
+			families[i].Samples = append(families[i].Samples, metrics.Sample{Labels: labels, Value: value})
+		}
+	}
+	return families
+}
@@ -0,0 +1,198 @@
+package app
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"sync"
+	"time"
+
+	"notify/internal/config"
+	"notify/internal/metrics"
+)
+
+// 请求被拒绝的范围和原因
+const (
+	RequestScopeApp = "app" // 应用整体的限制
+	RequestScopeIP  = "ip"  // 单个客户端 IP 的限制
+
+	RejectRateLimit = "rate_limit" // 超过每秒请求数
+	RejectQuota     = "quota"      // 超过每日配额
+)
+
+// maxRequestClients 每个应用保留的客户端计数数量，超过时清理不再影响限制的计数
+const maxRequestClients = 1024
+
+// RequestRejection 超过请求限制时的范围、原因和建议的重试等待时间
+type RequestRejection struct {
+	Scope      string
+	Reason     string
+	RetryAfter time.Duration
+}
+
+// Error 返回拒绝原因的描述
+func (r *RequestRejection) Error() string {
+	scope := "应用"
+	if r.Scope == RequestScopeIP {
+		scope = "客户端"
+	}
+	if r.Reason == RejectQuota {
+		return fmt.Sprintf("%s今日请求数已达到配额，请在 %s 后重试", scope, r.RetryAfter.Round(time.Second))
+	}
+	return fmt.Sprintf("%s请求过于频繁，请在 %s 后重试", scope, r.RetryAfter.Round(time.Millisecond))
+}
+
+// requestCounter 应用或客户端的请求频率和当日请求数
+type requestCounter struct {
+	bucket *tokenBucket
+	day    string
+	count  int
+	last   time.Time
+}
+
+// check 判断是否还能接受一个请求，不消耗令牌和配额
+func (c *requestCounter) check(limit config.RequestLimit, now time.Time) (string, time.Duration) {
+	if day := now.Format("2006-01-02"); c.day != day {
+		c.day, c.count = day, 0
+	}
+	if limit.DailyQuota > 0 && c.count >= limit.DailyQuota {
+		y, m, d := now.Date()
+		return RejectQuota, time.Date(y, m, d+1, 0, 0, 0, 0, now.Location()).Sub(now)
+	}
+	if bucket := limit.Bucket(); bucket != nil {
+		if c.bucket == nil {
+			c.bucket = newTokenBucket(*bucket, now)
+		}
+		c.bucket.refill(now)
+		if c.bucket.tokens < 1 {
+			return RejectRateLimit, time.Duration((1 - c.bucket.tokens) / c.bucket.rate * float64(time.Second))
+		}
+	}
+	return "", 0
+}
+
+// consume 记录一次接受的请求
+func (c *requestCounter) consume(now time.Time) {
+	c.count++
+	c.last = now
+	if c.bucket != nil {
+		c.bucket.tokens--
+	}
+}
+
+// requestLimiter 单个应用的入站请求限制
+type requestLimiter struct {
+	mu      sync.Mutex
+	limit   config.AppRateLimit
+	app     requestCounter
+	clients map[string]*requestCounter
+}
+
+// reconfigure 更新限制配置，重新计算请求频率，保留当日已使用的配额
+func (l *requestLimiter) reconfigure(limit config.AppRateLimit) {
+	l.limit = limit
+	l.app.bucket = nil
+	for _, client := range l.clients {
+		client.bucket = nil
+	}
+}
+
+// allow 判断应用和客户端是否都还能接受请求，都可以时才消耗令牌和配额
+func (l *requestLimiter) allow(clientIP string, now time.Time) *RequestRejection {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if reason, retry := l.app.check(l.limit.RequestLimit, now); reason != "" {
+		return &RequestRejection{Scope: RequestScopeApp, Reason: reason, RetryAfter: retry}
+	}
+	var client *requestCounter
+	if l.limit.PerIP != nil {
+		client = l.client(clientIP, now)
+		if reason, retry := client.check(*l.limit.PerIP, now); reason != "" {
+			return &RequestRejection{Scope: RequestScopeIP, Reason: reason, RetryAfter: retry}
+		}
+		client.consume(now)
+	}
+	l.app.consume(now)
+	return nil
+}
+
+// client 返回客户端的计数，调用方需要持有 mu
+func (l *requestLimiter) client(clientIP string, now time.Time) *requestCounter {
+	if client, exists := l.clients[clientIP]; exists {
+		return client
+	}
+	if len(l.clients) >= maxRequestClients {
+		today := now.Format("2006-01-02")
+		for ip, client := range l.clients {
+			// 当日没有请求且令牌已回满的客户端不影响限制
+			idle := client.bucket == nil || now.Sub(client.last).Seconds()*client.bucket.rate >= client.bucket.burst
+			if idle && (client.day != today || l.limit.PerIP.DailyQuota == 0) {
+				delete(l.clients, ip)
+			}
+		}
+	}
+	client := &requestCounter{}
+	l.clients[clientIP] = client
+	return client
+}
+
+// AllowRequest 按应用的请求限制判断是否接受来自 clientIP 的请求，超过限制时返回拒绝原因并计入监控指标
+func (app *NotificationApp) AllowRequest(appConfig config.NotificationApp, clientIP string) *RequestRejection {
+	app.requestMu.Lock()
+	if appConfig.RateLimit == nil {
+		delete(app.requestLimiters, appConfig.AppID)
+		app.requestMu.Unlock()
+		return nil
+	}
+	limiter, exists := app.requestLimiters[appConfig.AppID]
+	if !exists {
+		limiter = &requestLimiter{limit: *appConfig.RateLimit, clients: make(map[string]*requestCounter)}
+		app.requestLimiters[appConfig.AppID] = limiter
+	}
+	app.requestMu.Unlock()
+
+	limiter.mu.Lock()
+	if !reflect.DeepEqual(limiter.limit, *appConfig.RateLimit) {
+		limiter.reconfigure(*appConfig.RateLimit)
+	}
+	limiter.mu.Unlock()
+
+	rejection := limiter.allow(clientIP, time.Now())
+	if rejection != nil {
+		app.rejections.Inc(appConfig.AppID, rejection.Scope, rejection.Reason)
+	}
+	return rejection
+}
+
+// RequestLimitMetrics 返回请求限制的监控指标
+func (app *NotificationApp) RequestLimitMetrics() []metrics.Family {
+	used := metrics.Family{Name: "notify_requests_quota_used", Help: "通知应用今日已使用的请求配额", Type: metrics.TypeGauge}
+
+	app.requestMu.Lock()
+	appIDs := make([]string, 0, len(app.requestLimiters))
+	for appID := range app.requestLimiters {
+		appIDs = append(appIDs, appID)
+	}
+	sort.Strings(appIDs)
+	limiters := make([]*requestLimiter, len(appIDs))
+	for i, appID := range appIDs {
+		limiters[i] = app.requestLimiters[appID]
+	}
+	app.requestMu.Unlock()
+
+	today := time.Now().Format("2006-01-02")
+	for i, limiter := range limiters {
+		limiter.mu.Lock()
+		count := 0
+		if limiter.app.day == today {
+			count = limiter.app.count
+		}
+		quota := limiter.limit.DailyQuota
+		limiter.mu.Unlock()
+		if quota > 0 {
+			used.Samples = append(used.Samples, metrics.Sample{Labels: map[string]string{"app": appIDs[i]}, Value: float64(count)})
+		}
+	}
+	return []metrics.Family{app.rejections.Family(), used}
+}
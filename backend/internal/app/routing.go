@@ -0,0 +1,225 @@
+package app
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"notify/internal/config"
+	"notify/internal/utils"
+)
+
+// Route 一次投递使用的通知服务、模板和目标
+type Route struct {
+	Rule            string   `json:"rule"` // 命中的规则名称，为空表示应用默认配置
+	Notifiers       []string `json:"notifiers"`
+	TemplateID      string   `json:"templateId"`
+	TemplateVersion int      `json:"templateVersion,omitempty"`
+	Targets         string   `json:"targets,omitempty"` // 覆盖模板 targets 的模板字符串
+}
+
+// RuleEvaluation 单条路由规则的匹配结果
+type RuleEvaluation struct {
+	Index   int    `json:"index"`
+	Name    string `json:"name"`
+	Matched bool   `json:"matched"`
+	Error   string `json:"error,omitempty"`
+}
+
+// resolveRoutes 按顺序匹配应用的路由规则，得到需要执行的投递列表
+// 没有配置规则或没有规则命中时，使用应用默认的通知服务和模板
+func (app *NotificationApp) resolveRoutes(appConfig config.NotificationApp, data *map[string]any) ([]Route, []RuleEvaluation) {
+	defaultRoute := Route{
+		Notifiers:       appConfig.Notifiers,
+		TemplateID:      appConfig.TemplateID,
+		TemplateVersion: appConfig.TemplateVersion,
+	}
+	if len(appConfig.Rules) == 0 {
+		return []Route{defaultRoute}, nil
+	}
+
+	var (
+		routes      []Route
+		evaluations []RuleEvaluation
+	)
+	for i, rule := range appConfig.Rules {
+		matched, err := app.matchRule(appConfig.AppID, i, rule, data)
+		evaluation := RuleEvaluation{Index: i, Name: rule.Name, Matched: matched}
+		if err != nil {
+			evaluation.Error = err.Error()
+		}
+		evaluations = append(evaluations, evaluation)
+		if !matched {
+			continue
+		}
+
+		route := Route{
+			Rule:       rule.Name,
+			Notifiers:  rule.Notifiers,
+			TemplateID: rule.TemplateID,
+			Targets:    rule.Targets,
+		}
+		if len(route.Notifiers) == 0 {
+			route.Notifiers = appConfig.Notifiers
+		}
+		// 规则未指定模板时沿用应用的模板和固定版本
+		if route.TemplateID == "" {
+			route.TemplateID = appConfig.TemplateID
+			route.TemplateVersion = appConfig.TemplateVersion
+		}
+		routes = append(routes, route)
+
+		if !rule.Continue {
+			break
+		}
+	}
+
+	if len(routes) == 0 {
+		routes = append(routes, defaultRoute)
+	}
+	return routes, evaluations
+}
+
+// matchRule 判断规则是否命中，condition 和 match 同时配置时需要都满足，都未配置时总是命中
+func (app *NotificationApp) matchRule(appID string, index int, rule config.RoutingRule, data *map[string]any) (bool, error) {
+	if rule.Match != "" {
+		conditions, err := parseFieldConditions(rule.Match)
+		if err != nil {
+			return false, err
+		}
+		for _, cond := range conditions {
+			if !cond.match(*data) {
+				return false, nil
+			}
+		}
+	}
+
+	if rule.Condition != "" {
+		name := fmt.Sprintf("%s_rule_%d", appID, index)
+		result, err := app.renderTemplate(name, rule.Condition, data)
+		if err != nil {
+			return false, err
+		}
+		if !isTruthy(result) {
+			return false, nil
+		}
+	}
+
+	return true, nil
+}
+
+// isTruthy 判断模板表达式的渲染结果是否为真
+func isTruthy(s string) bool {
+	switch strings.ToLower(strings.TrimSpace(s)) {
+	case "", "false", "0", "no", "off", "nil", "<nil>":
+		return false
+	}
+	return true
+}
+
+// fieldConditionRegex 匹配 "字段 操作符 值" 形式的条件
+var fieldConditionRegex = regexp.MustCompile(`^([\w.\-]+)\s*(not in|in|==|!=|=~|!~)\s*(.+)$`)
+
+// fieldExistsRegex 匹配只有字段名（可带 ! 取反）的条件，表示字段存在且非空
+var fieldExistsRegex = regexp.MustCompile(`^(!?)([\w.\-]+)$`)
+
+// fieldCondition 简单字段匹配条件
+type fieldCondition struct {
+	field  string
+	op     string
+	values []string
+	re     *regexp.Regexp
+}
+
+// parseFieldConditions 解析字段匹配表达式，多个条件用 && 连接
+// 支持: field == v, field != v, field in [a, b], field not in [a, b], field =~ regex, field !~ regex, field, !field
+func parseFieldConditions(expr string) ([]fieldCondition, error) {
+	var conditions []fieldCondition
+	for _, part := range strings.Split(expr, "&&") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+
+		if m := fieldExistsRegex.FindStringSubmatch(part); m != nil {
+			op := "exists"
+			if m[1] == "!" {
+				op = "missing"
+			}
+			conditions = append(conditions, fieldCondition{field: m[2], op: op})
+			continue
+		}
+
+		m := fieldConditionRegex.FindStringSubmatch(part)
+		if m == nil {
+			return nil, fmt.Errorf("无法解析匹配条件: %s", part)
+		}
+		cond := fieldCondition{field: m[1], op: m[2]}
+		value := strings.TrimSpace(m[3])
+
+		switch cond.op {
+		case "in", "not in":
+			if !strings.HasPrefix(value, "[") || !strings.HasSuffix(value, "]") {
+				return nil, fmt.Errorf("匹配条件 %s 的值必须是 [a, b] 形式的列表", part)
+			}
+			for _, v := range strings.Split(strings.Trim(value, "[]"), ",") {
+				if v = unquote(strings.TrimSpace(v)); v != "" {
+					cond.values = append(cond.values, v)
+				}
+			}
+		case "=~", "!~":
+			re, err := regexp.Compile(unquote(value))
+			if err != nil {
+				return nil, fmt.Errorf("匹配条件 %s 的正则表达式无效: %w", part, err)
+			}
+			cond.re = re
+		default:
+			cond.values = []string{unquote(value)}
+		}
+		conditions = append(conditions, cond)
+	}
+
+	if len(conditions) == 0 {
+		return nil, fmt.Errorf("匹配条件不能为空")
+	}
+	return conditions, nil
+}
+
+// match 判断数据是否满足条件，字符串比较不区分大小写
+func (c fieldCondition) match(data map[string]any) bool {
+	str := utils.GetString(data, c.field)
+
+	switch c.op {
+	case "exists":
+		return str != ""
+	case "missing":
+		return str == ""
+	case "==", "in":
+		return containsFold(c.values, str)
+	case "!=", "not in":
+		return !containsFold(c.values, str)
+	case "=~":
+		return c.re.MatchString(str)
+	case "!~":
+		return !c.re.MatchString(str)
+	}
+	return false
+}
+
+// containsFold 不区分大小写判断列表是否包含指定值
+func containsFold(values []string, s string) bool {
+	for _, v := range values {
+		if strings.EqualFold(v, s) {
+			return true
+		}
+	}
+	return false
+}
+
+// unquote 去掉值两侧的引号
+func unquote(s string) string {
+	if len(s) >= 2 && (s[0] == '"' || s[0] == '\'') && s[len(s)-1] == s[0] {
+		return s[1 : len(s)-1]
+	}
+	return s
+}
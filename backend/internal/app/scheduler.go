@@ -0,0 +1,407 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"notify/internal/config"
+	"notify/internal/logger"
+	"notify/internal/store"
+	"notify/internal/utils"
+)
+
+const (
+	// scheduledStoreName 定时发送的通知在数据目录中的文件名
+	scheduledStoreName = "scheduled"
+	// jobStateStoreName 定时任务执行状态在数据目录中的文件名
+	jobStateStoreName = "jobs"
+
+	// scheduledMaxAttempts 定时通知最多发送的次数，超过后丢弃
+	scheduledMaxAttempts = 10
+	// scheduledRetryBase 定时通知发送失败后第一次重试的等待时间，之后每次翻倍
+	scheduledRetryBase = time.Minute
+	// scheduledRetryMax 定时通知重试的最长等待时间
+	scheduledRetryMax = time.Hour
+)
+
+// ScheduledNotification 一条等待定时发送的通知
+type ScheduledNotification struct {
+	ID        string         `json:"id"`
+	AppID     string         `json:"appId"`
+	Data      map[string]any `json:"data"` // 请求数据，到时间后按普通请求发送
+	SendAt    time.Time      `json:"sendAt"`
+	CreatedAt time.Time      `json:"createdAt"`
+
+	Attempts  int    `json:"attempts,omitempty"`  // 已失败的发送次数，失败后推迟 SendAt 重试
+	LastError string `json:"lastError,omitempty"` // 最近一次发送失败的原因
+}
+
+// JobState 定时任务的执行状态
+type JobState struct {
+	Schedule  string     `json:"schedule"` // 计算 NextRunAt 时使用的 cron 表达式，修改后重新计算
+	NextRunAt *time.Time `json:"nextRunAt,omitempty"`
+	LastRunAt *time.Time `json:"lastRunAt,omitempty"`
+	LastError string     `json:"lastError,omitempty"`
+	Runs      int        `json:"runs"`
+}
+
+// JobStatus 定时任务配置和执行状态
+type JobStatus struct {
+	ID    string              `json:"id"`
+	Job   config.ScheduledJob `json:"job"`
+	State JobState            `json:"state"`
+}
+
+// Scheduler 定时发送通知和执行定时任务
+type Scheduler struct {
+	app   *NotificationApp
+	store *store.Store
+
+	mu      sync.Mutex
+	pending map[string]*ScheduledNotification
+	jobs    map[string]*JobState
+
+	stop chan struct{}
+	wg   sync.WaitGroup
+}
+
+// NewScheduler 创建调度器，从数据目录恢复等待发送的通知和定时任务状态
+func NewScheduler(app *NotificationApp, dataStore *store.Store) *Scheduler {
+	s := &Scheduler{
+		app:     app,
+		store:   dataStore,
+		pending: make(map[string]*ScheduledNotification),
+		jobs:    make(map[string]*JobState),
+	}
+	if err := dataStore.Load(scheduledStoreName, &s.pending); err != nil {
+		logger.Error("读取定时通知失败", "error", err)
+		s.pending = make(map[string]*ScheduledNotification)
+	}
+	if err := dataStore.Load(jobStateStoreName, &s.jobs); err != nil {
+		logger.Error("读取定时任务状态失败", "error", err)
+		s.jobs = make(map[string]*JobState)
+	}
+	return s
+}
+
+// Start 启动调度，按间隔发送到期的通知和执行到期的定时任务
+func (s *Scheduler) Start(interval time.Duration) {
+	s.stop = make(chan struct{})
+	s.wg.Add(1)
+	go func() {
+		defer s.wg.Done()
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case now := <-ticker.C:
+				s.processPending(now)
+				s.processJobs(now)
+			case <-s.stop:
+				return
+			}
+		}
+	}()
+}
+
+// Stop 停止调度，等待正在发送的通知结束
+func (s *Scheduler) Stop() {
+	if s.stop != nil {
+		close(s.stop)
+		s.wg.Wait()
+	}
+}
+
+// savePending 保存等待发送的通知，调用方需要持有 mu
+func (s *Scheduler) savePending() {
+	if err := s.store.Save(scheduledStoreName, s.pending); err != nil {
+		logger.Error("保存定时通知失败", "error", err)
+	}
+}
+
+// saveJobs 保存定时任务状态，调用方需要持有 mu
+func (s *Scheduler) saveJobs() {
+	if err := s.store.Save(jobStateStoreName, s.jobs); err != nil {
+		logger.Error("保存定时任务状态失败", "error", err)
+	}
+}
+
+// Schedule 保存一条定时发送的通知
+func (s *Scheduler) Schedule(appID string, data map[string]any, sendAt time.Time) *ScheduledNotification {
+	scheduled := &ScheduledNotification{
+		ID:        newRandomID(),
+		AppID:     appID,
+		Data:      data,
+		SendAt:    sendAt,
+		CreatedAt: time.Now(),
+	}
+
+	s.mu.Lock()
+	s.pending[scheduled.ID] = scheduled
+	s.savePending()
+	s.mu.Unlock()
+
+	logger.Info("通知已加入定时发送", "app", appID, "id", scheduled.ID, "sendAt", sendAt)
+	return scheduled
+}
+
+// Cancel 取消定时发送的通知，appID 不为空时只能取消该应用的通知
+func (s *Scheduler) Cancel(id, appID string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	scheduled, exists := s.pending[id]
+	if !exists || (appID != "" && scheduled.AppID != appID) {
+		return fmt.Errorf("定时通知 %s 不存在", id)
+	}
+	delete(s.pending, id)
+	s.savePending()
+	return nil
+}
+
+// Pending 返回等待发送的通知，按发送时间升序，appID 为空时返回全部
+func (s *Scheduler) Pending(appID string) []ScheduledNotification {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	list := []ScheduledNotification{}
+	for _, scheduled := range s.pending {
+		if appID == "" || scheduled.AppID == appID {
+			list = append(list, *scheduled)
+		}
+	}
+	sort.Slice(list, func(i, j int) bool {
+		return list[i].SendAt.Before(list[j].SendAt)
+	})
+	return list
+}
+
+// processPending 发送到期的定时通知，发送成功后才删除，失败时按指数退避推迟重试，超过最多次数后丢弃
+func (s *Scheduler) processPending(now time.Time) {
+	s.mu.Lock()
+	var due []ScheduledNotification
+	for _, scheduled := range s.pending {
+		if !now.Before(scheduled.SendAt) {
+			due = append(due, *scheduled)
+		}
+	}
+	s.mu.Unlock()
+	if len(due) == 0 {
+		return
+	}
+
+	sort.Slice(due, func(i, j int) bool {
+		return due[i].SendAt.Before(due[j].SendAt)
+	})
+	errs := make([]error, len(due))
+	for i, scheduled := range due {
+		errs[i] = s.send(scheduled.AppID, scheduled.Data)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for i, scheduled := range due {
+		current, exists := s.pending[scheduled.ID]
+		if !exists {
+			// 发送期间被取消
+			continue
+		}
+		if errs[i] == nil {
+			delete(s.pending, scheduled.ID)
+			continue
+		}
+		current.Attempts++
+		current.LastError = errs[i].Error()
+		if current.Attempts >= scheduledMaxAttempts {
+			logger.Error("定时通知多次发送失败，已丢弃", "app", current.AppID, "id", current.ID, "attempts", current.Attempts, "error", errs[i])
+			delete(s.pending, scheduled.ID)
+			continue
+		}
+		current.SendAt = time.Now().Add(scheduledRetryDelay(current.Attempts))
+		logger.Error("发送定时通知失败，稍后重试", "app", current.AppID, "id", current.ID, "attempts", current.Attempts, "retryAt", current.SendAt, "error", errs[i])
+	}
+	s.savePending()
+}
+
+// scheduledRetryDelay 返回第 attempts 次失败后的重试等待时间
+func scheduledRetryDelay(attempts int) time.Duration {
+	delay := scheduledRetryBase
+	for i := 1; i < attempts && delay < scheduledRetryMax; i++ {
+		delay *= 2
+	}
+	return min(delay, scheduledRetryMax)
+}
+
+// processJobs 执行到期的定时任务，新增或修改了计划的任务从当前时间开始计算下次执行时间
+// 服务停止期间错过的执行不会补发
+func (s *Scheduler) processJobs(now time.Time) {
+	jobs := s.app.configManager.ScheduledJobs()
+
+	s.mu.Lock()
+	changed := false
+	var due []string
+	for id := range s.jobs {
+		if _, exists := jobs[id]; !exists {
+			delete(s.jobs, id)
+			changed = true
+		}
+	}
+	for id, job := range jobs {
+		state, exists := s.jobs[id]
+		if !exists || state.Schedule != job.Schedule {
+			if !exists {
+				state = &JobState{}
+				s.jobs[id] = state
+			}
+			state.Schedule = job.Schedule
+			state.NextRunAt = nextRun(job.Schedule, now)
+			changed = true
+			continue
+		}
+		// 执行计划无效或没有下次执行时间，计划修改前不再重复计算
+		if state.NextRunAt == nil || now.Before(*state.NextRunAt) {
+			continue
+		}
+		missed := now.Sub(*state.NextRunAt) > time.Minute
+		state.NextRunAt = nextRun(job.Schedule, now)
+		changed = true
+		if missed {
+			logger.Warn("错过定时任务的执行时间，等待下次执行", "job", id, "nextRunAt", state.NextRunAt)
+			continue
+		}
+		if !job.Paused {
+			due = append(due, id)
+		}
+	}
+	if changed {
+		s.saveJobs()
+	}
+	s.mu.Unlock()
+
+	sort.Strings(due)
+	for _, id := range due {
+		s.runJob(id, jobs[id])
+	}
+}
+
+// RunJob 立即执行一次定时任务，不影响下次执行时间
+func (s *Scheduler) RunJob(id string) error {
+	job, exists := s.app.configManager.ScheduledJobs()[id]
+	if !exists {
+		return fmt.Errorf("定时任务 %s 不存在", id)
+	}
+	return s.runJob(id, job)
+}
+
+// runJob 执行定时任务并记录执行结果
+func (s *Scheduler) runJob(id string, job config.ScheduledJob) error {
+	data := make(map[string]any, len(job.Payload)+1)
+	for key, value := range job.Payload {
+		data[key] = value
+	}
+	if _, exists := data["job"]; !exists {
+		data["job"] = map[string]any{"id": id, "name": job.Name}
+	}
+	err := s.send(job.AppID, data)
+	if err != nil {
+		logger.Error("执行定时任务失败", "job", id, "app", job.AppID, "error", err)
+	}
+
+	now := time.Now()
+	s.mu.Lock()
+	state, exists := s.jobs[id]
+	if !exists {
+		state = &JobState{Schedule: job.Schedule, NextRunAt: nextRun(job.Schedule, now)}
+		s.jobs[id] = state
+	}
+	state.LastRunAt = &now
+	state.LastError = ""
+	if err != nil {
+		state.LastError = err.Error()
+	}
+	state.Runs++
+	s.saveJobs()
+	s.mu.Unlock()
+	return err
+}
+
+// Jobs 返回所有定时任务的配置和执行状态，按 ID 排序
+func (s *Scheduler) Jobs() []JobStatus {
+	jobs := s.app.configManager.ScheduledJobs()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	list := make([]JobStatus, 0, len(jobs))
+	for id, job := range jobs {
+		status := JobStatus{ID: id, Job: job}
+		if state, exists := s.jobs[id]; exists && state.Schedule == job.Schedule {
+			status.State = *state
+		} else {
+			// 新增或刚修改的任务还没有计算执行时间
+			status.State = JobState{Schedule: job.Schedule, NextRunAt: nextRun(job.Schedule, time.Now())}
+		}
+		list = append(list, status)
+	}
+	sort.Slice(list, func(i, j int) bool {
+		return list[i].ID < list[j].ID
+	})
+	return list
+}
+
+// send 使用请求数据发送通知
+func (s *Scheduler) send(appID string, data map[string]any) error {
+	ctx, cancel := context.WithTimeout(context.Background(), backgroundSendTimeout)
+	defer cancel()
+	_, err := s.app.Send(ctx, config.NotificationApp{AppID: appID}, &data)
+	return err
+}
+
+// nextRun 返回 cron 表达式在 after 之后的下一次执行时间，表达式无效或没有执行时间时返回 nil
+func nextRun(schedule string, after time.Time) *time.Time {
+	cron, err := utils.ParseCron(schedule)
+	if err != nil {
+		logger.Warn("定时任务的执行计划无效", "schedule", schedule, "error", err)
+		return nil
+	}
+	next := cron.Next(after)
+	if next.IsZero() {
+		return nil
+	}
+	return &next
+}
+
+// ParseSendTime 解析定时发送参数，send_at 支持 RFC3339、本地时间 2006-01-02 15:04[:05] 和 Unix 秒，delay 为时长如 30m
+// 两者都为空时返回 false
+func ParseSendTime(sendAt, delay string, now time.Time) (time.Time, bool, error) {
+	sendAt, delay = strings.TrimSpace(sendAt), strings.TrimSpace(delay)
+	switch {
+	case sendAt != "" && delay != "":
+		return time.Time{}, false, fmt.Errorf("send_at 和 delay 不能同时指定")
+	case delay != "":
+		duration, err := time.ParseDuration(delay)
+		if err != nil || duration < 0 {
+			return time.Time{}, false, fmt.Errorf("无效的 delay %q，格式如 30m、2h", delay)
+		}
+		return now.Add(duration), true, nil
+	case sendAt != "":
+		if seconds, err := strconv.ParseInt(sendAt, 10, 64); err == nil {
+			return time.Unix(seconds, 0), true, nil
+		}
+		if t, err := time.Parse(time.RFC3339, sendAt); err == nil {
+			return t, true, nil
+		}
+		for _, layout := range []string{time.DateTime, "2006-01-02 15:04"} {
+			if t, err := time.ParseInLocation(layout, sendAt, time.Local); err == nil {
+				return t, true, nil
+			}
+		}
+		return time.Time{}, false, fmt.Errorf("无效的 send_at %q，支持 RFC3339、2006-01-02 15:04:05 和 Unix 时间戳", sendAt)
+	}
+	return time.Time{}, false, nil
+}
@@ -0,0 +1,50 @@
+package app
+
+import (
+	"fmt"
+
+	"notify/internal/config"
+	"notify/internal/notifier"
+	"notify/internal/source"
+	"notify/internal/utils"
+)
+
+// renderSeverity 渲染消息级别和优先级
+// 模板未配置时使用请求数据中的 severity（或 level）和 priority 字段，未指定优先级时按级别推导
+func (app *NotificationApp) renderSeverity(templateID string, tpl *config.MessageTemplate, req *map[string]any) (string, int, error) {
+	severity := utils.FirstNonEmpty(utils.GetString(*req, "severity"), utils.GetString(*req, "level"))
+	if tpl.Severity != "" {
+		rendered, err := app.renderTemplate(templateID+"_severity", tpl.Severity, req)
+		if err != nil {
+			return "", 0, fmt.Errorf("渲染消息级别失败: %w", err)
+		}
+		severity = rendered
+	}
+	severity = source.NormalizeSeverity(severity)
+
+	priority := utils.GetString(*req, "priority")
+	if tpl.Priority != "" {
+		rendered, err := app.renderTemplate(templateID+"_priority", tpl.Priority, req)
+		if err != nil {
+			return "", 0, fmt.Errorf("渲染消息优先级失败: %w", err)
+		}
+		priority = rendered
+	}
+	level := notifier.ParsePriority(priority)
+	if level == 0 {
+		level = notifier.SeverityPriority(severity)
+	}
+	return severity, level, nil
+}
+
+// belowMinSeverity 判断消息级别是否低于应用配置的最低级别
+func belowMinSeverity(appConfig config.NotificationApp, message *notifier.NotificationMessage) bool {
+	if appConfig.MinSeverity == "" {
+		return false
+	}
+	severity := message.Severity
+	if severity == "" {
+		severity = notifier.SeverityInfo
+	}
+	return notifier.SeverityRank(severity) < notifier.SeverityRank(appConfig.MinSeverity)
+}
@@ -0,0 +1,73 @@
+package app
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"strings"
+
+	"notify/internal/config"
+	"notify/internal/logger"
+	"notify/internal/store"
+)
+
+// signingKeyStoreName 自动生成的签名密钥在数据目录中的文件名
+const signingKeyStoreName = "signing_key"
+
+// Signer 为确认链接等公开地址生成 HMAC 签名
+type Signer struct {
+	key []byte
+}
+
+// newSigner 创建签名器，优先使用 SIGNING_SECRET，未配置时使用数据目录中自动生成的密钥
+// 已有密钥读取失败或新密钥生成、保存失败时返回错误，避免覆盖原密钥导致已发出的链接失效
+func newSigner(dataStore *store.Store) (*Signer, error) {
+	if secret := config.EnvCfg.SIGNING_SECRET; secret != "" {
+		return &Signer{key: []byte(secret)}, nil
+	}
+
+	var saved struct {
+		Key string `json:"key"`
+	}
+	if err := dataStore.Load(signingKeyStoreName, &saved); err != nil {
+		return nil, fmt.Errorf("读取签名密钥失败: %w", err)
+	}
+	if saved.Key != "" {
+		key, err := hex.DecodeString(saved.Key)
+		if err != nil || len(key) == 0 {
+			return nil, fmt.Errorf("签名密钥格式错误")
+		}
+		return &Signer{key: key}, nil
+	}
+
+	key := make([]byte, 32)
+	if _, err := rand.Read(key); err != nil {
+		return nil, fmt.Errorf("生成签名密钥失败: %w", err)
+	}
+	saved.Key = hex.EncodeToString(key)
+	if err := dataStore.Save(signingKeyStoreName, saved); err != nil {
+		return nil, fmt.Errorf("保存签名密钥失败: %w", err)
+	}
+	logger.Info("已生成签名密钥")
+	return &Signer{key: key}, nil
+}
+
+// Sign 对各部分内容签名，返回十六进制字符串
+func (s *Signer) Sign(parts ...string) string {
+	mac := hmac.New(sha256.New, s.key)
+	mac.Write([]byte(strings.Join(parts, "\n")))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// Verify 校验签名
+func (s *Signer) Verify(signature string, parts ...string) bool {
+	expected, err := hex.DecodeString(signature)
+	if err != nil {
+		return false
+	}
+	mac := hmac.New(sha256.New, s.key)
+	mac.Write([]byte(strings.Join(parts, "\n")))
+	return hmac.Equal(mac.Sum(nil), expected)
+}
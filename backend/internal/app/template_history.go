@@ -0,0 +1,173 @@
+package app
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"notify/internal/config"
+	"notify/internal/logger"
+	"notify/internal/store"
+	"notify/internal/utils"
+
+	"gopkg.in/yaml.v3"
+)
+
+// templateHistoryStoreName 模板历史在数据目录中的文件名
+const templateHistoryStoreName = "template_history"
+
+// TemplateVersion 模板的一个历史版本
+type TemplateVersion struct {
+	Version   int                    `json:"version"`
+	Template  config.MessageTemplate `json:"template"`
+	Author    string                 `json:"author"`
+	Note      string                 `json:"note,omitempty"`
+	CreatedAt time.Time              `json:"createdAt"`
+}
+
+// TemplateHistory 模板版本历史管理
+type TemplateHistory struct {
+	configManager *config.ConfigManager
+	store         *store.Store
+	limit         int
+
+	mu       sync.RWMutex
+	versions map[string][]TemplateVersion // key 为模板ID，按版本号升序
+}
+
+// NewTemplateHistory 创建模板版本历史管理器
+func NewTemplateHistory(configManager *config.ConfigManager, dataStore *store.Store, limit int) *TemplateHistory {
+	h := &TemplateHistory{
+		configManager: configManager,
+		store:         dataStore,
+		limit:         limit,
+		versions:      make(map[string][]TemplateVersion),
+	}
+	if err := dataStore.Load(templateHistoryStoreName, &h.versions); err != nil {
+		// 历史损坏时不影响服务启动，从空历史开始
+		logger.Error("读取模板历史失败", "error", err)
+		h.versions = make(map[string][]TemplateVersion)
+	}
+	return h
+}
+
+// Record 记录模板的新版本
+func (h *TemplateHistory) Record(templateID string, tpl config.MessageTemplate, author, note string) (TemplateVersion, error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	versions := h.versions[templateID]
+	next := 1
+	if len(versions) > 0 {
+		next = versions[len(versions)-1].Version + 1
+	}
+	version := TemplateVersion{
+		Version:   next,
+		Template:  tpl,
+		Author:    author,
+		Note:      note,
+		CreatedAt: time.Now(),
+	}
+	h.versions[templateID] = h.prune(templateID, append(versions, version))
+
+	return version, h.store.Save(templateHistoryStoreName, h.versions)
+}
+
+// EnsureBaseline 模板还没有任何历史时，把当前内容记为初始版本
+func (h *TemplateHistory) EnsureBaseline(templateID string, tpl config.MessageTemplate) error {
+	h.mu.RLock()
+	exists := len(h.versions[templateID]) > 0
+	h.mu.RUnlock()
+	if exists {
+		return nil
+	}
+	_, err := h.Record(templateID, tpl, "system", "初始版本")
+	return err
+}
+
+// List 返回模板的所有历史版本，最新的在前
+func (h *TemplateHistory) List(templateID string) []TemplateVersion {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	versions := make([]TemplateVersion, len(h.versions[templateID]))
+	copy(versions, h.versions[templateID])
+	sort.Slice(versions, func(i, j int) bool {
+		return versions[i].Version > versions[j].Version
+	})
+	return versions
+}
+
+// Get 获取模板的指定版本
+func (h *TemplateHistory) Get(templateID string, version int) (TemplateVersion, error) {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	for _, v := range h.versions[templateID] {
+		if v.Version == version {
+			return v, nil
+		}
+	}
+	return TemplateVersion{}, fmt.Errorf("模板 %s 的版本 %d 不存在", templateID, version)
+}
+
+// Diff 生成模板两个版本之间的统一diff
+func (h *TemplateHistory) Diff(templateID string, from, to int) (string, error) {
+	fromVersion, err := h.Get(templateID, from)
+	if err != nil {
+		return "", err
+	}
+	toVersion, err := h.Get(templateID, to)
+	if err != nil {
+		return "", err
+	}
+
+	fromText, err := yaml.Marshal(fromVersion.Template)
+	if err != nil {
+		return "", fmt.Errorf("序列化模板失败: %w", err)
+	}
+	toText, err := yaml.Marshal(toVersion.Template)
+	if err != nil {
+		return "", fmt.Errorf("序列化模板失败: %w", err)
+	}
+
+	return utils.UnifiedDiff(
+		fmt.Sprintf("%s@v%d", templateID, from),
+		fmt.Sprintf("%s@v%d", templateID, to),
+		string(fromText),
+		string(toText),
+	), nil
+}
+
+// Remove 删除模板的全部历史
+func (h *TemplateHistory) Remove(templateID string) error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	delete(h.versions, templateID)
+	return h.store.Save(templateHistoryStoreName, h.versions)
+}
+
+// prune 只保留最近 limit 个版本，被应用固定的版本不会被清理
+func (h *TemplateHistory) prune(templateID string, versions []TemplateVersion) []TemplateVersion {
+	if h.limit <= 0 || len(versions) <= h.limit {
+		return versions
+	}
+
+	pinned := make(map[int]bool)
+	for _, app := range h.configManager.GetConfig().NotificationApps {
+		if app.TemplateID == templateID && app.TemplateVersion > 0 {
+			pinned[app.TemplateVersion] = true
+		}
+	}
+
+	kept := make([]TemplateVersion, 0, h.limit)
+	cutoff := len(versions) - h.limit
+	for i, v := range versions {
+		if i >= cutoff || pinned[v.Version] {
+			kept = append(kept, v)
+		}
+	}
+	return kept
+}
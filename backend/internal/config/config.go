@@ -3,6 +3,7 @@ package config
 import (
 	"fmt"
 	"os"
+	"sync"
 
 	"gopkg.in/yaml.v3"
 )
@@ -29,6 +30,14 @@ type Config struct {
 	Notifiers        map[string]NotifierInstance `yaml:"notifiers" json:"notifiers"`
 	Templates        map[string]MessageTemplate  `yaml:"templates" json:"templates"` // 消息模板配置
 	NotificationApps map[string]NotificationApp  `yaml:"notification_apps" json:"notificationApps"`
+	Contacts         map[string]Contact          `yaml:"contacts,omitempty" json:"contacts,omitempty"`            // 联系人
+	ContactGroups    map[string]ContactGroup     `yaml:"contact_groups,omitempty" json:"contactGroups,omitempty"` // 联系人组
+
+	Schedules          map[string]Schedule         `yaml:"schedules,omitempty" json:"schedules,omitempty"`                    // 值班表
+	EscalationPolicies map[string]EscalationPolicy `yaml:"escalation_policies,omitempty" json:"escalationPolicies,omitempty"` // 升级策略
+	QuietHours         map[string]QuietHours       `yaml:"quiet_hours,omitempty" json:"quietHours,omitempty"`                 // 免打扰策略
+	ScheduledJobs      map[string]ScheduledJob     `yaml:"scheduled_jobs,omitempty" json:"scheduledJobs,omitempty"`           // 定时任务
+	Heartbeats         map[string]Heartbeat        `yaml:"heartbeats,omitempty" json:"heartbeats,omitempty"`                  // 心跳监控
 }
 
 // NotifierInstance 通知服务实例配置
@@ -70,6 +79,10 @@ type DingTalkConfig struct {
 	Secret      string `yaml:"secret" json:"secret"`
 	Targets     string `yaml:"targets" json:"targets"`
 	Proxy       string `yaml:"proxy" json:"proxy"` // 代理服务器地址，格式: http://proxy.example.com:8080
+
+	// AppKey、AppSecret 可选的企业内部应用凭证，配置后图片附件通过 media/upload 上传并以 media_id 发送
+	AppKey    string `yaml:"app_key,omitempty" json:"appKey,omitempty"`
+	AppSecret string `yaml:"app_secret,omitempty" json:"appSecret,omitempty"`
 }
 
 // FeishuConfig 飞书配置
@@ -92,6 +105,73 @@ type NotificationApp struct {
 	TemplateID   string   `yaml:"template_id" json:"templateId"`        // 关联的模板ID
 	DefaultImage string   `yaml:"default_image" json:"defaultImage"`    // 默认图片URL
 	Auth         *AppAuth `yaml:"auth,omitempty" json:"auth,omitempty"` // 可选字段
+
+	// TemplateVersion 固定使用的模板版本号，0 表示始终使用最新模板
+	TemplateVersion int `yaml:"template_version,omitempty" json:"templateVersion,omitempty"`
+
+	// Rules 按顺序匹配的路由规则，为空时使用上面的 Notifiers 和 TemplateID
+	Rules []RoutingRule `yaml:"rules,omitempty" json:"rules,omitempty"`
+
+	// Source 入站数据源适配，为空时直接使用请求数据渲染模板
+	Source *SourceConfig `yaml:"source,omitempty" json:"source,omitempty"`
+
+	// FormJSONFields 表单和 multipart 请求中按 JSON 解析的字段，如 Emby 的 data 字段
+	FormJSONFields []string `yaml:"form_json_fields,omitempty" json:"formJsonFields,omitempty"`
+
+	// MediaHosting 把远程图片下载到内置媒体存储后再发送，需要配置 PUBLIC_BASE_URL
+	MediaHosting bool `yaml:"media_hosting,omitempty" json:"mediaHosting,omitempty"`
+
+	// MinSeverity 最低通知级别，低于该级别的消息不发送，未指定级别的消息视为 info
+	MinSeverity string `yaml:"min_severity,omitempty" json:"minSeverity,omitempty"`
+
+	// EscalationPolicy 升级策略，配置后消息按策略步骤发送，未确认时逐级升级
+	EscalationPolicy string `yaml:"escalation_policy,omitempty" json:"escalationPolicy,omitempty"`
+
+	// AckLinks 消息带签名的确认和解决按钮，需要配置 PUBLIC_BASE_URL
+	AckLinks bool `yaml:"ack_links,omitempty" json:"ackLinks,omitempty"`
+
+	// AckNotify 消息被确认或解决后，向原通知服务发送一条“已由 X 确认”的通知
+	AckNotify bool `yaml:"ack_notify,omitempty" json:"ackNotify,omitempty"`
+
+	// Dedup 按指纹去重，为空时不去重
+	Dedup *DedupConfig `yaml:"dedup,omitempty" json:"dedup,omitempty"`
+
+	// Grouping 按分组键合并消息，为空时不分组
+	Grouping *GroupingConfig `yaml:"grouping,omitempty" json:"grouping,omitempty"`
+
+	// Digest 摘要模式，消息缓存后按计划合并发送，为空时直接发送
+	Digest *DigestConfig `yaml:"digest,omitempty" json:"digest,omitempty"`
+
+	// QuietHours 免打扰策略 ID，通知服务也可以单独配置 quiet_hours，两者都处于免打扰时使用应用的策略
+	QuietHours string `yaml:"quiet_hours,omitempty" json:"quietHours,omitempty"`
+
+	// NotifierGroups 通知服务组，notifiers 中引用组名时按组的投递策略发送
+	NotifierGroups map[string]NotifierGroup `yaml:"notifier_groups,omitempty" json:"notifierGroups,omitempty"`
+
+	// RateLimit 入站请求的频率限制和每日配额，超出时返回 429
+	RateLimit *AppRateLimit `yaml:"rate_limit,omitempty" json:"rateLimit,omitempty"`
+}
+
+// SourceConfig 入站数据源适配配置
+type SourceConfig struct {
+	Type    string   `yaml:"type" json:"type"`                            // 数据源类型，如 alertmanager、grafana、uptimekuma、zabbix、github
+	Group   bool     `yaml:"group,omitempty" json:"group,omitempty"`      // 多条告警合并为一条通知，默认每条告警单独发送
+	Secret  string   `yaml:"secret,omitempty" json:"secret,omitempty"`    // webhook 密钥，用于校验签名或令牌
+	Events  []string `yaml:"events,omitempty" json:"events,omitempty"`    // 只通知这些事件，为空时通知所有事件
+	BaseURL string   `yaml:"base_url,omitempty" json:"baseUrl,omitempty"` // 媒体服务器地址，用于拼接海报图片链接
+}
+
+// RoutingRule 根据请求内容选择通知服务、目标和模板的路由规则
+type RoutingRule struct {
+	Name       string   `yaml:"name" json:"name"`
+	Condition  string   `yaml:"condition,omitempty" json:"condition,omitempty"`    // 模板布尔表达式，如 {{ eq .severity "error" }}
+	Match      string   `yaml:"match,omitempty" json:"match,omitempty"`            // 字段匹配，如 severity in [error, critical]
+	Notifiers  []string `yaml:"notifiers,omitempty" json:"notifiers,omitempty"`    // 命中后使用的通知服务，为空时使用应用的通知服务
+	Targets    string   `yaml:"targets,omitempty" json:"targets,omitempty"`        // 命中后使用的目标，支持模板语法，为空时使用模板的目标
+	TemplateID string   `yaml:"template_id,omitempty" json:"templateId,omitempty"` // 命中后使用的模板，为空时使用应用的模板
+	Continue   bool     `yaml:"continue,omitempty" json:"continue,omitempty"`      // 命中后是否继续匹配后续规则
+
+	Digest *DigestConfig `yaml:"digest,omitempty" json:"digest,omitempty"` // 命中后使用摘要模式，覆盖应用的摘要配置
 }
 
 // AppAuth 通知应用的认证配置
@@ -109,12 +189,29 @@ type MessageTemplate struct {
 	Image   string `yaml:"image" json:"image"`     // 图片
 	URL     string `yaml:"url" json:"url"`         // 链接
 	Targets string `yaml:"targets" json:"targets"` // 目标
+
+	Mentions string `yaml:"mentions,omitempty" json:"mentions,omitempty"` // 提及对象，逗号分隔，支持模板语法，为空时使用请求数据中的 mentions
+	Severity string `yaml:"severity,omitempty" json:"severity,omitempty"` // 级别，支持模板语法，为空时使用请求数据中的 severity 或 level
+	Priority string `yaml:"priority,omitempty" json:"priority,omitempty"` // 优先级 1-5，支持模板语法，为空时使用请求数据中的 priority 或按级别推导
+
+	Actions []ActionTemplate `yaml:"actions,omitempty" json:"actions,omitempty"` // 按钮，渲染后标签或链接为空的按钮会被忽略
+}
+
+// ActionTemplate 消息按钮模板，各字段均支持模板语法
+type ActionTemplate struct {
+	Label string `yaml:"label" json:"label"`
+	URL   string `yaml:"url" json:"url"`
+	Style string `yaml:"style,omitempty" json:"style,omitempty"` // primary、danger 或 default
 }
 
 // ConfigManager 配置管理器
 type ConfigManager struct {
 	configFile string
 	config     *Config
+
+	// mu 保护后台任务持续读取的配置，如定时任务和心跳监控
+	// 修改时替换为新的 map，读取方通过访问方法获取副本
+	mu sync.RWMutex
 }
 
 // NewConfigManager 创建配置管理器
@@ -223,6 +320,13 @@ func (cm *ConfigManager) GetAppsUsingTemplate(templateID string) []string {
 	for appID, app := range cm.config.NotificationApps {
 		if app.TemplateID == templateID {
 			apps = append(apps, appID)
+			continue
+		}
+		for _, rule := range app.Rules {
+			if rule.TemplateID == templateID {
+				apps = append(apps, appID)
+				break
+			}
 		}
 	}
 	return apps
@@ -260,6 +364,9 @@ func (cm *ConfigManager) UpdateApp(appName string, updates map[string]interface{
 	if templateID, ok := updates["template_id"].(string); ok {
 		app.TemplateID = templateID
 	}
+	if templateVersion, ok := updates["template_version"].(float64); ok {
+		app.TemplateVersion = int(templateVersion)
+	}
 	if authData, ok := updates["auth"].(map[string]interface{}); ok {
 		// 如果Auth字段为nil，先初始化
 		if app.Auth == nil {
@@ -352,12 +459,33 @@ func (cm *ConfigManager) GetAppsUsingNotifier(notifierName string) []string {
 
 	appsUsingNotifier := []string{}
 	for appName, appConfig := range cm.config.NotificationApps {
-		for _, notifier := range appConfig.Notifiers {
+		if appUsesNotifier(appConfig, notifierName) {
+			appsUsingNotifier = append(appsUsingNotifier, appName)
+		}
+	}
+	return appsUsingNotifier
+}
+
+// appUsesNotifier 检查应用、路由规则或通知服务组是否引用了指定通知服务
+func appUsesNotifier(appConfig NotificationApp, notifierName string) bool {
+	for _, group := range appConfig.NotifierGroups {
+		for _, notifier := range append(append([]string{}, group.Notifiers...), group.Fallback...) {
 			if notifier == notifierName {
-				appsUsingNotifier = append(appsUsingNotifier, appName)
-				break
+				return true
 			}
 		}
 	}
-	return appsUsingNotifier
+	for _, notifier := range appConfig.Notifiers {
+		if notifier == notifierName {
+			return true
+		}
+	}
+	for _, rule := range appConfig.Rules {
+		for _, notifier := range rule.Notifiers {
+			if notifier == notifierName {
+				return true
+			}
+		}
+	}
+	return false
 }
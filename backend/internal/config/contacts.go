@@ -0,0 +1,213 @@
+package config
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// 联系人引用前缀，可用于模板和路由规则的 targets、mentions
+const (
+	ContactUserPrefix  = "user:"
+	ContactGroupPrefix = "group:"
+)
+
+// ContactChannels 联系人在各通知渠道的身份
+type ContactChannels struct {
+	WechatWork string `yaml:"wechat_work,omitempty" json:"wechatWork,omitempty"` // 企业微信 userid
+	DingTalk   string `yaml:"dingtalk,omitempty" json:"dingtalk,omitempty"`      // 钉钉 userId，未配置时使用手机号
+	Feishu     string `yaml:"feishu,omitempty" json:"feishu,omitempty"`          // 飞书 open_id、user_id 或群 chat_id，未配置时使用邮箱
+	Telegram   string `yaml:"telegram,omitempty" json:"telegram,omitempty"`      // Telegram chat_id 或用户名
+}
+
+// Contact 联系人
+type Contact struct {
+	Name   string `yaml:"name" json:"name"`
+	Email  string `yaml:"email,omitempty" json:"email,omitempty"`
+	Mobile string `yaml:"mobile,omitempty" json:"mobile,omitempty"` // 手机号，用于钉钉和企业微信群机器人提及
+
+	ContactChannels `yaml:",inline"`
+}
+
+// ContactGroup 联系人组，作为发送目标时优先使用组自身的渠道身份（如群聊 ID），未配置时展开为成员
+type ContactGroup struct {
+	Name    string   `yaml:"name" json:"name"`
+	Members []string `yaml:"members" json:"members"` // 联系人 ID
+
+	ContactChannels `yaml:",inline"`
+}
+
+// Channel 返回指定通知服务类型的身份
+func (c ContactChannels) Channel(notifierType NotifiersType) string {
+	switch notifierType {
+	case WechatWorkAPPBot, WechatWorkWebhookBot:
+		return c.WechatWork
+	case DingTalkAppBot:
+		return c.DingTalk
+	case FeishuAppBot:
+		return c.Feishu
+	case TelegramAppBot:
+		return c.Telegram
+	}
+	return ""
+}
+
+// ContactRef 解析联系人引用，返回前缀（user: 或 group:）和 ID，不是联系人引用时返回空字符串
+func ContactRef(value string) (string, string) {
+	value = strings.TrimSpace(value)
+	for _, prefix := range []string{ContactUserPrefix, ContactGroupPrefix} {
+		if strings.HasPrefix(value, prefix) && len(value) > len(prefix) {
+			return prefix, strings.TrimPrefix(value, prefix)
+		}
+	}
+	return "", ""
+}
+
+// SaveContact 创建或更新联系人
+func (cm *ConfigManager) SaveContact(id string, contact Contact) error {
+	if cm.config == nil {
+		return fmt.Errorf("配置未初始化")
+	}
+	if cm.config.Contacts == nil {
+		cm.config.Contacts = make(map[string]Contact)
+	}
+	cm.config.Contacts[id] = contact
+	return cm.Save()
+}
+
+// DeleteContact 删除联系人
+func (cm *ConfigManager) DeleteContact(id string) error {
+	if cm.config == nil {
+		return fmt.Errorf("配置未初始化")
+	}
+	if _, exists := cm.config.Contacts[id]; !exists {
+		return fmt.Errorf("联系人 %s 不存在", id)
+	}
+	delete(cm.config.Contacts, id)
+	return cm.Save()
+}
+
+// SaveContactGroup 创建或更新联系人组，成员必须是已存在的联系人
+func (cm *ConfigManager) SaveContactGroup(id string, group ContactGroup) error {
+	if cm.config == nil {
+		return fmt.Errorf("配置未初始化")
+	}
+	for _, member := range group.Members {
+		if _, exists := cm.config.Contacts[member]; !exists {
+			return fmt.Errorf("联系人组 %s 的成员 %s 不存在", id, member)
+		}
+	}
+	if cm.config.ContactGroups == nil {
+		cm.config.ContactGroups = make(map[string]ContactGroup)
+	}
+	cm.config.ContactGroups[id] = group
+	return cm.Save()
+}
+
+// DeleteContactGroup 删除联系人组
+func (cm *ConfigManager) DeleteContactGroup(id string) error {
+	if cm.config == nil {
+		return fmt.Errorf("配置未初始化")
+	}
+	if _, exists := cm.config.ContactGroups[id]; !exists {
+		return fmt.Errorf("联系人组 %s 不存在", id)
+	}
+	delete(cm.config.ContactGroups, id)
+	return cm.Save()
+}
+
+// GetContactReferences 返回引用了指定联系人或联系人组的位置，ref 为 user:id 或 group:id
+func (cm *ConfigManager) GetContactReferences(ref string) []string {
+	if cm.config == nil {
+		return nil
+	}
+	var refs []string
+	cm.config.walkContactRefs(func(location, value string) {
+		if value == ref {
+			refs = append(refs, location)
+		}
+	})
+	sort.Strings(refs)
+	return refs
+}
+
+// DanglingContactRefs 返回引用了不存在的联系人或联系人组的位置
+func (c *Config) DanglingContactRefs() []string {
+	var dangling []string
+	c.walkContactRefs(func(location, value string) {
+		if !c.contactRefExists(value) {
+			dangling = append(dangling, fmt.Sprintf("%s: %s", location, value))
+		}
+	})
+	sort.Strings(dangling)
+	return dangling
+}
+
+// MissingContactRefs 返回逗号分隔列表中引用了不存在的联系人或联系人组的项，用于保存模板和应用前检查
+func (c *Config) MissingContactRefs(lists ...string) []string {
+	var missing []string
+	for _, list := range lists {
+		walkContactList("", list, func(_, value string) {
+			if !c.contactRefExists(value) {
+				missing = append(missing, value)
+			}
+		})
+	}
+	return missing
+}
+
+// contactRefExists 判断联系人引用是否存在
+func (c *Config) contactRefExists(value string) bool {
+	prefix, id := ContactRef(value)
+	if prefix == ContactUserPrefix {
+		_, exists := c.Contacts[id]
+		return exists
+	}
+	_, exists := c.ContactGroups[id]
+	return exists
+}
+
+// walkContactRefs 遍历配置中所有联系人引用
+// 包括联系人组成员、值班表成员、升级策略步骤、模板和路由规则的 targets、mentions 中固定写出的引用，模板表达式生成的引用无法检查
+func (c *Config) walkContactRefs(fn func(location, value string)) {
+	for id, group := range c.ContactGroups {
+		for _, member := range group.Members {
+			fn(fmt.Sprintf("联系人组 %s", id), ContactUserPrefix+member)
+		}
+	}
+	for id, schedule := range c.Schedules {
+		for _, member := range schedule.Members {
+			fn(fmt.Sprintf("值班表 %s", id), ContactUserPrefix+member)
+		}
+		for _, override := range schedule.Overrides {
+			fn(fmt.Sprintf("值班表 %s", id), ContactUserPrefix+override.Contact)
+		}
+	}
+	for id, policy := range c.EscalationPolicies {
+		for i, step := range policy.Steps {
+			walkContactList(fmt.Sprintf("升级策略 %s 步骤 %d", id, i+1), strings.Join(step.Targets, ","), fn)
+		}
+	}
+	for id, tpl := range c.Templates {
+		walkContactList(fmt.Sprintf("模板 %s", id), tpl.Targets, fn)
+		walkContactList(fmt.Sprintf("模板 %s", id), tpl.Mentions, fn)
+	}
+	for id, app := range c.NotificationApps {
+		for i, rule := range app.Rules {
+			walkContactList(fmt.Sprintf("应用 %s 规则 %d", id, i+1), rule.Targets, fn)
+		}
+	}
+}
+
+// walkContactList 遍历逗号分隔列表中的联系人引用，跳过包含模板表达式的项
+func walkContactList(location, list string, fn func(location, value string)) {
+	for _, item := range strings.Split(list, ",") {
+		item = strings.TrimSpace(item)
+		if strings.Contains(item, "{{") {
+			continue
+		}
+		if prefix, _ := ContactRef(item); prefix != "" {
+			fn(location, item)
+		}
+	}
+}
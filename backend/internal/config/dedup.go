@@ -0,0 +1,77 @@
+package config
+
+import (
+	"fmt"
+	"time"
+)
+
+// 分组默认等待时间
+const (
+	DefaultGroupWait     = 30 * time.Second
+	DefaultGroupInterval = 5 * time.Minute
+)
+
+// DedupConfig 去重配置，抑制窗口内指纹相同的消息只发送第一条，窗口结束时发送重复次数
+type DedupConfig struct {
+	Fingerprint string `yaml:"fingerprint,omitempty" json:"fingerprint,omitempty"` // 指纹模板，如 {{.groupKey}}，为空时使用标题、内容和发送目标
+	Window      string `yaml:"window" json:"window"`                               // 抑制窗口，如 10m
+}
+
+// GroupingConfig 分组配置，分组键相同的消息先缓存，等待一段时间后合并为一条发送
+type GroupingConfig struct {
+	GroupBy       string `yaml:"group_by" json:"groupBy"`                                 // 分组键模板，如 {{.labels.alertname}}
+	GroupWait     string `yaml:"group_wait,omitempty" json:"groupWait,omitempty"`         // 新分组第一次发送前的等待时间，默认 30s
+	GroupInterval string `yaml:"group_interval,omitempty" json:"groupInterval,omitempty"` // 分组再次发送的最小间隔，默认 5m
+}
+
+// WindowDuration 返回抑制窗口时长
+func (d DedupConfig) WindowDuration() (time.Duration, error) {
+	window, err := time.ParseDuration(d.Window)
+	if err != nil || window <= 0 {
+		return 0, fmt.Errorf("无效的去重窗口 %q", d.Window)
+	}
+	return window, nil
+}
+
+// WaitDuration 返回新分组的等待时间
+func (g GroupingConfig) WaitDuration() (time.Duration, error) {
+	return parseOptionalDuration(g.GroupWait, DefaultGroupWait, "group_wait")
+}
+
+// IntervalDuration 返回分组再次发送的最小间隔
+func (g GroupingConfig) IntervalDuration() (time.Duration, error) {
+	return parseOptionalDuration(g.GroupInterval, DefaultGroupInterval, "group_interval")
+}
+
+// parseOptionalDuration 解析可选的时长，为空时返回默认值
+func parseOptionalDuration(value string, defaultValue time.Duration, name string) (time.Duration, error) {
+	if value == "" {
+		return defaultValue, nil
+	}
+	duration, err := time.ParseDuration(value)
+	if err != nil || duration < 0 {
+		return 0, fmt.Errorf("无效的 %s %q", name, value)
+	}
+	return duration, nil
+}
+
+// ValidateAggregation 检查应用的去重和分组配置
+func (a NotificationApp) ValidateAggregation() error {
+	if a.Dedup != nil {
+		if _, err := a.Dedup.WindowDuration(); err != nil {
+			return err
+		}
+	}
+	if a.Grouping != nil {
+		if a.Grouping.GroupBy == "" {
+			return fmt.Errorf("分组需要配置 group_by")
+		}
+		if _, err := a.Grouping.WaitDuration(); err != nil {
+			return err
+		}
+		if _, err := a.Grouping.IntervalDuration(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
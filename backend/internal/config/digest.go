@@ -0,0 +1,64 @@
+package config
+
+import (
+	"fmt"
+
+	"notify/internal/utils"
+)
+
+// DigestConfig 摘要配置，消息先缓存，按 cron 计划或达到条数上限时合并为一条摘要发送
+type DigestConfig struct {
+	Schedule   string `yaml:"schedule,omitempty" json:"schedule,omitempty"`      // cron 表达式，如 0 * * * * 每小时发送一次
+	MaxItems   int    `yaml:"max_items,omitempty" json:"maxItems,omitempty"`     // 缓存达到该条数时立即发送，0 表示不限制
+	TemplateID string `yaml:"template_id,omitempty" json:"templateId,omitempty"` // 摘要模板，模板数据为 items、count 等，为空时使用内置模板
+}
+
+// Validate 检查摘要配置
+func (d DigestConfig) Validate() error {
+	if d.Schedule == "" && d.MaxItems <= 0 {
+		return fmt.Errorf("摘要需要配置 schedule 或 max_items")
+	}
+	if d.MaxItems < 0 {
+		return fmt.Errorf("max_items 不能小于 0")
+	}
+	if d.Schedule != "" {
+		if _, err := utils.ParseCron(d.Schedule); err != nil {
+			return fmt.Errorf("无效的摘要计划: %w", err)
+		}
+	}
+	return nil
+}
+
+// DigestFor 返回路由使用的摘要配置，命中的规则配置了摘要时优先使用规则的配置
+func (a NotificationApp) DigestFor(ruleName string) *DigestConfig {
+	if ruleName != "" {
+		for _, rule := range a.Rules {
+			if rule.Name == ruleName && rule.Digest != nil {
+				return rule.Digest
+			}
+		}
+	}
+	return a.Digest
+}
+
+// ValidateDigest 检查应用和路由规则的摘要配置及引用的模板
+func (c *Config) ValidateDigest(app NotificationApp) error {
+	digests := []*DigestConfig{app.Digest}
+	for _, rule := range app.Rules {
+		digests = append(digests, rule.Digest)
+	}
+	for _, digest := range digests {
+		if digest == nil {
+			continue
+		}
+		if err := digest.Validate(); err != nil {
+			return err
+		}
+		if digest.TemplateID != "" {
+			if _, exists := c.Templates[digest.TemplateID]; !exists {
+				return fmt.Errorf("摘要模板 %s 不存在", digest.TemplateID)
+			}
+		}
+	}
+	return nil
+}
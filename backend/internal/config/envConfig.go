@@ -2,19 +2,38 @@ package config
 
 import (
 	"fmt"
+	"time"
 
 	"github.com/kelseyhightower/envconfig"
 )
 
 type EnvConfig struct {
-	VERSION         string `default:"v0.0.9"`
-	NOTIFY_USERNAME string
-	NOTIFY_PASSWORD string
-	LOG_LEVEL       string `default:"info"`
-	LOG_FORMAT      string `default:"text"`
-	CONFIG_FILE     string `default:"config/config.yaml"`
-	PORT            string `default:":8088"`
-	STATIC_DIR      string `default:"/app/static"`
+	VERSION                string `default:"v0.0.9"`
+	NOTIFY_USERNAME        string
+	NOTIFY_PASSWORD        string
+	LOG_LEVEL              string `default:"info"`
+	LOG_FORMAT             string `default:"text"`
+	CONFIG_FILE            string `default:"config/config.yaml"`
+	PORT                   string `default:":8088"`
+	STATIC_DIR             string `default:"/app/static"`
+	DATA_DIR               string
+	TEMPLATE_HISTORY_LIMIT int `default:"20"`
+	PUBLIC_BASE_URL        string
+	MEDIA_TTL              time.Duration `default:"168h"`
+	SIGNING_SECRET         string
+	ACK_LINK_TTL           time.Duration `default:"72h"`
+	MESSAGE_HISTORY_LIMIT  int           `default:"1000"`
+
+	// 可信的反向代理地址或网段，逗号分隔，只有来自这些地址的 X-Forwarded-For 才用于确定客户端 IP
+	TRUSTED_PROXIES []string
+
+	// 通知服务熔断：统计窗口内失败次数达到阈值且多于成功次数时熔断，冷却后试探恢复，阈值为 0 表示不熔断
+	CIRCUIT_BREAKER_THRESHOLD int           `default:"5"`
+	CIRCUIT_BREAKER_WINDOW    time.Duration `default:"1m"`
+	CIRCUIT_BREAKER_COOLDOWN  time.Duration `default:"30s"`
+
+	// 发送接口幂等键的有效期，有效期内相同幂等键的请求返回第一次的结果，0 表示不保存结果
+	IDEMPOTENCY_TTL time.Duration `default:"24h"`
 }
 
 func NewEnvConfig() *EnvConfig {
@@ -0,0 +1,160 @@
+package config
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"regexp"
+	"time"
+)
+
+// heartbeatTokenPattern ping 地址中的令牌格式
+var heartbeatTokenPattern = regexp.MustCompile(`^[A-Za-z0-9_-]{8,64}$`)
+
+// Heartbeat 心跳监控，定时任务按间隔 ping，超过间隔加宽限时间仍未收到时发送通知
+type Heartbeat struct {
+	Name     string `yaml:"name" json:"name"`
+	Token    string `yaml:"token" json:"token"`                       // ping 地址中的令牌，为空时自动生成
+	Interval string `yaml:"interval" json:"interval"`                 // 预期的 ping 间隔，如 1h
+	Grace    string `yaml:"grace,omitempty" json:"grace,omitempty"`   // 宽限时间，如 10m，收到 start 后需要在宽限时间内收到结束 ping
+	AppID    string `yaml:"app_id" json:"appId"`                      // 发送通知使用的应用
+	Paused   bool   `yaml:"paused,omitempty" json:"paused,omitempty"` // 暂停后不再检查
+}
+
+// IntervalDuration 返回预期的 ping 间隔
+func (h Heartbeat) IntervalDuration() (time.Duration, error) {
+	interval, err := time.ParseDuration(h.Interval)
+	if err != nil || interval <= 0 {
+		return 0, fmt.Errorf("无效的 ping 间隔 %q", h.Interval)
+	}
+	return interval, nil
+}
+
+// GraceDuration 返回宽限时间，未配置时为 0
+func (h Heartbeat) GraceDuration() (time.Duration, error) {
+	if h.Grace == "" {
+		return 0, nil
+	}
+	grace, err := time.ParseDuration(h.Grace)
+	if err != nil || grace < 0 {
+		return 0, fmt.Errorf("无效的宽限时间 %q", h.Grace)
+	}
+	return grace, nil
+}
+
+// ValidateHeartbeat 检查心跳监控配置、引用的应用和令牌是否与其他监控重复
+func (c *Config) ValidateHeartbeat(id string, heartbeat Heartbeat) error {
+	if _, err := heartbeat.IntervalDuration(); err != nil {
+		return err
+	}
+	if _, err := heartbeat.GraceDuration(); err != nil {
+		return err
+	}
+	if _, exists := c.NotificationApps[heartbeat.AppID]; !exists {
+		return fmt.Errorf("通知应用 %s 不存在", heartbeat.AppID)
+	}
+	if !heartbeatTokenPattern.MatchString(heartbeat.Token) {
+		return fmt.Errorf("令牌只能包含字母、数字、下划线和连字符，长度 8-64")
+	}
+	for otherID, other := range c.Heartbeats {
+		if otherID != id && other.Token == heartbeat.Token {
+			return fmt.Errorf("令牌与心跳监控 %s 重复", otherID)
+		}
+	}
+	return nil
+}
+
+// FindHeartbeatByToken 根据 ping 令牌查找心跳监控
+func (c *Config) FindHeartbeatByToken(token string) (string, Heartbeat, bool) {
+	for id, heartbeat := range c.Heartbeats {
+		if heartbeat.Token == token {
+			return id, heartbeat, true
+		}
+	}
+	return "", Heartbeat{}, false
+}
+
+// Heartbeats 返回心跳监控配置的副本，供后台任务和 ping 请求并发读取
+func (cm *ConfigManager) Heartbeats() map[string]Heartbeat {
+	cm.mu.RLock()
+	defer cm.mu.RUnlock()
+
+	heartbeats := make(map[string]Heartbeat, len(cm.config.Heartbeats))
+	for id, heartbeat := range cm.config.Heartbeats {
+		heartbeats[id] = heartbeat
+	}
+	return heartbeats
+}
+
+// FindHeartbeatByToken 根据 ping 令牌查找心跳监控
+func (cm *ConfigManager) FindHeartbeatByToken(token string) (string, Heartbeat, bool) {
+	cm.mu.RLock()
+	defer cm.mu.RUnlock()
+	return cm.config.FindHeartbeatByToken(token)
+}
+
+// SaveHeartbeat 创建或更新心跳监控，未指定令牌时沿用原令牌或生成新令牌
+func (cm *ConfigManager) SaveHeartbeat(id string, heartbeat Heartbeat) (Heartbeat, error) {
+	if cm.config == nil {
+		return heartbeat, fmt.Errorf("配置未初始化")
+	}
+
+	cm.mu.Lock()
+	if heartbeat.Token == "" {
+		if existing, exists := cm.config.Heartbeats[id]; exists {
+			heartbeat.Token = existing.Token
+		} else {
+			b := make([]byte, 16)
+			if _, err := rand.Read(b); err != nil {
+				cm.mu.Unlock()
+				return heartbeat, fmt.Errorf("生成令牌失败: %w", err)
+			}
+			heartbeat.Token = hex.EncodeToString(b)
+		}
+	}
+	if err := cm.config.ValidateHeartbeat(id, heartbeat); err != nil {
+		cm.mu.Unlock()
+		return heartbeat, err
+	}
+	heartbeats := make(map[string]Heartbeat, len(cm.config.Heartbeats)+1)
+	for k, v := range cm.config.Heartbeats {
+		heartbeats[k] = v
+	}
+	heartbeats[id] = heartbeat
+	cm.config.Heartbeats = heartbeats
+	cm.mu.Unlock()
+	return heartbeat, cm.Save()
+}
+
+// DeleteHeartbeat 删除心跳监控
+func (cm *ConfigManager) DeleteHeartbeat(id string) error {
+	if cm.config == nil {
+		return fmt.Errorf("配置未初始化")
+	}
+
+	cm.mu.Lock()
+	if _, exists := cm.config.Heartbeats[id]; !exists {
+		cm.mu.Unlock()
+		return fmt.Errorf("心跳监控 %s 不存在", id)
+	}
+	heartbeats := make(map[string]Heartbeat, len(cm.config.Heartbeats))
+	for k, v := range cm.config.Heartbeats {
+		if k != id {
+			heartbeats[k] = v
+		}
+	}
+	cm.config.Heartbeats = heartbeats
+	cm.mu.Unlock()
+	return cm.Save()
+}
+
+// GetHeartbeatsUsingApp 返回使用指定应用的心跳监控
+func (cm *ConfigManager) GetHeartbeatsUsingApp(appID string) []string {
+	var heartbeats []string
+	for id, heartbeat := range cm.Heartbeats() {
+		if heartbeat.AppID == appID {
+			heartbeats = append(heartbeats, id)
+		}
+	}
+	return heartbeats
+}
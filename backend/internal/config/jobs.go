@@ -0,0 +1,95 @@
+package config
+
+import (
+	"fmt"
+
+	"notify/internal/utils"
+)
+
+// ScheduledJob 定时任务，按 cron 计划使用固定的请求数据发送通知
+type ScheduledJob struct {
+	Name     string         `yaml:"name" json:"name"`
+	Schedule string         `yaml:"schedule" json:"schedule"`                   // cron 表达式，如 0 9 * * mon 每周一 9 点
+	AppID    string         `yaml:"app_id" json:"appId"`                        // 发送通知使用的应用
+	Payload  map[string]any `yaml:"payload,omitempty" json:"payload,omitempty"` // 请求数据，与调用通知接口时的 JSON 相同
+	Paused   bool           `yaml:"paused,omitempty" json:"paused,omitempty"`   // 暂停后不再执行
+}
+
+// ValidateScheduledJob 检查定时任务的 cron 表达式和引用的应用
+func (c *Config) ValidateScheduledJob(job ScheduledJob) error {
+	if job.Schedule == "" {
+		return fmt.Errorf("定时任务需要配置 schedule")
+	}
+	if _, err := utils.ParseCron(job.Schedule); err != nil {
+		return fmt.Errorf("无效的执行计划: %w", err)
+	}
+	if _, exists := c.NotificationApps[job.AppID]; !exists {
+		return fmt.Errorf("通知应用 %s 不存在", job.AppID)
+	}
+	return nil
+}
+
+// ScheduledJobs 返回定时任务配置的副本，供后台任务并发读取
+func (cm *ConfigManager) ScheduledJobs() map[string]ScheduledJob {
+	cm.mu.RLock()
+	defer cm.mu.RUnlock()
+
+	jobs := make(map[string]ScheduledJob, len(cm.config.ScheduledJobs))
+	for id, job := range cm.config.ScheduledJobs {
+		jobs[id] = job
+	}
+	return jobs
+}
+
+// SaveScheduledJob 创建或更新定时任务
+func (cm *ConfigManager) SaveScheduledJob(id string, job ScheduledJob) error {
+	if cm.config == nil {
+		return fmt.Errorf("配置未初始化")
+	}
+	if err := cm.config.ValidateScheduledJob(job); err != nil {
+		return err
+	}
+
+	cm.mu.Lock()
+	jobs := make(map[string]ScheduledJob, len(cm.config.ScheduledJobs)+1)
+	for k, v := range cm.config.ScheduledJobs {
+		jobs[k] = v
+	}
+	jobs[id] = job
+	cm.config.ScheduledJobs = jobs
+	cm.mu.Unlock()
+	return cm.Save()
+}
+
+// DeleteScheduledJob 删除定时任务
+func (cm *ConfigManager) DeleteScheduledJob(id string) error {
+	if cm.config == nil {
+		return fmt.Errorf("配置未初始化")
+	}
+
+	cm.mu.Lock()
+	if _, exists := cm.config.ScheduledJobs[id]; !exists {
+		cm.mu.Unlock()
+		return fmt.Errorf("定时任务 %s 不存在", id)
+	}
+	jobs := make(map[string]ScheduledJob, len(cm.config.ScheduledJobs))
+	for k, v := range cm.config.ScheduledJobs {
+		if k != id {
+			jobs[k] = v
+		}
+	}
+	cm.config.ScheduledJobs = jobs
+	cm.mu.Unlock()
+	return cm.Save()
+}
+
+// GetJobsUsingApp 返回使用指定应用的定时任务
+func (cm *ConfigManager) GetJobsUsingApp(appID string) []string {
+	var jobs []string
+	for id, job := range cm.ScheduledJobs() {
+		if job.AppID == appID {
+			jobs = append(jobs, id)
+		}
+	}
+	return jobs
+}
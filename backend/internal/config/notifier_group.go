@@ -0,0 +1,69 @@
+package config
+
+import "fmt"
+
+// 通知服务组的投递策略
+const (
+	DeliveryAll          = "all"           // 并发发送到所有通知服务
+	DeliveryFirstSuccess = "first-success" // 按顺序尝试，直到一个发送成功
+	DeliveryFallback     = "fallback"      // 先并发发送到主通知服务，全部失败后再发送到备用通知服务
+)
+
+// NotifierGroup 通知服务组，应用和路由规则的 notifiers 中可以用组名代替通知服务实例
+type NotifierGroup struct {
+	Strategy  string   `yaml:"strategy,omitempty" json:"strategy,omitempty"` // all、first-success 或 fallback，默认 all
+	Notifiers []string `yaml:"notifiers" json:"notifiers"`                   // 组内的通知服务，fallback 策略下为主通知服务
+	Fallback  []string `yaml:"fallback,omitempty" json:"fallback,omitempty"` // fallback 策略下主通知服务全部失败后使用的通知服务
+}
+
+// EffectiveStrategy 返回投递策略，未配置时为 all
+func (g NotifierGroup) EffectiveStrategy() string {
+	if g.Strategy == "" {
+		return DeliveryAll
+	}
+	return g.Strategy
+}
+
+// ValidateNotifierGroups 检查应用的通知服务组，以及应用和路由规则引用的通知服务或组是否存在
+func (c *Config) ValidateNotifierGroups(app NotificationApp) error {
+	for name, group := range app.NotifierGroups {
+		if _, exists := c.Notifiers[name]; exists {
+			return fmt.Errorf("通知服务组 %s 与通知服务实例重名", name)
+		}
+		switch group.EffectiveStrategy() {
+		case DeliveryAll, DeliveryFirstSuccess:
+			if len(group.Fallback) > 0 {
+				return fmt.Errorf("通知服务组 %s 的 fallback 只能用于 fallback 策略", name)
+			}
+		case DeliveryFallback:
+			if len(group.Fallback) == 0 {
+				return fmt.Errorf("通知服务组 %s 使用 fallback 策略时需要配置 fallback", name)
+			}
+		default:
+			return fmt.Errorf("通知服务组 %s 的投递策略 %s 无效，可选 all、first-success、fallback", name, group.Strategy)
+		}
+		if len(group.Notifiers) == 0 {
+			return fmt.Errorf("通知服务组 %s 未配置任何通知服务", name)
+		}
+		for _, notifierName := range append(append([]string{}, group.Notifiers...), group.Fallback...) {
+			if _, exists := c.Notifiers[notifierName]; !exists {
+				return fmt.Errorf("通知服务组 %s 引用了不存在的通知服务实例: %s", name, notifierName)
+			}
+		}
+	}
+
+	lists := [][]string{app.Notifiers}
+	for _, rule := range app.Rules {
+		lists = append(lists, rule.Notifiers)
+	}
+	for _, list := range lists {
+		for _, name := range list {
+			_, isNotifier := c.Notifiers[name]
+			_, isGroup := app.NotifierGroups[name]
+			if !isNotifier && !isGroup {
+				return fmt.Errorf("引用了不存在的通知服务实例或组: %s", name)
+			}
+		}
+	}
+	return nil
+}
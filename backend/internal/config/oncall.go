@@ -0,0 +1,248 @@
+package config
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// SchedulePrefix 值班表引用前缀，可用于升级策略步骤的目标
+const SchedulePrefix = "schedule:"
+
+// 值班轮换方式
+const (
+	RotationDaily  = "daily"
+	RotationWeekly = "weekly"
+	RotationCustom = "custom"
+)
+
+// Schedule 值班表，成员按班次轮流值班，临时调班优先
+type Schedule struct {
+	Name        string             `yaml:"name" json:"name"`
+	Rotation    string             `yaml:"rotation" json:"rotation"`                            // daily、weekly 或 custom
+	ShiftLength string             `yaml:"shift_length,omitempty" json:"shiftLength,omitempty"` // custom 轮换的班次时长，如 12h
+	Start       time.Time          `yaml:"start" json:"start"`                                  // 第一个班次的开始时间（带时区），之后按班次时长交接
+	Members     []string           `yaml:"members" json:"members"`                              // 按顺序轮流值班的联系人 ID
+	Overrides   []ScheduleOverride `yaml:"overrides,omitempty" json:"overrides,omitempty"`      // 临时调班
+}
+
+// ScheduleOverride 临时调班，时间段内由指定联系人值班
+type ScheduleOverride struct {
+	Contact string    `yaml:"contact" json:"contact"`
+	Start   time.Time `yaml:"start" json:"start"`
+	End     time.Time `yaml:"end" json:"end"`
+}
+
+// EscalationPolicy 升级策略，告警未被确认时按步骤依次通知
+type EscalationPolicy struct {
+	Name   string           `yaml:"name" json:"name"`
+	Steps  []EscalationStep `yaml:"steps" json:"steps"`
+	Repeat int              `yaml:"repeat,omitempty" json:"repeat,omitempty"` // 所有步骤执行完仍未确认时从头重复的次数
+}
+
+// EscalationStep 升级步骤
+type EscalationStep struct {
+	Targets   []string `yaml:"targets" json:"targets"`                         // schedule:、user:、group: 引用或渠道 ID，为空时使用路由的目标
+	Notifiers []string `yaml:"notifiers,omitempty" json:"notifiers,omitempty"` // 为空时使用路由的通知服务
+	Delay     string   `yaml:"delay" json:"delay"`                             // 等待确认的时间，超时后升级到下一步，如 10m
+}
+
+// ShiftDuration 返回值班表的班次时长
+func (s Schedule) ShiftDuration() (time.Duration, error) {
+	switch s.Rotation {
+	case RotationDaily:
+		return 24 * time.Hour, nil
+	case RotationWeekly:
+		return 7 * 24 * time.Hour, nil
+	case RotationCustom:
+		shift, err := time.ParseDuration(s.ShiftLength)
+		if err != nil || shift <= 0 {
+			return 0, fmt.Errorf("无效的班次时长 %q", s.ShiftLength)
+		}
+		return shift, nil
+	}
+	return 0, fmt.Errorf("无效的轮换方式 %q，可选 daily、weekly、custom", s.Rotation)
+}
+
+// OnCall 返回指定时间的值班联系人 ID
+func (s Schedule) OnCall(at time.Time) (string, error) {
+	for _, override := range s.Overrides {
+		if !at.Before(override.Start) && at.Before(override.End) {
+			return override.Contact, nil
+		}
+	}
+	if len(s.Members) == 0 {
+		return "", fmt.Errorf("值班表没有成员")
+	}
+	shift, err := s.ShiftDuration()
+	if err != nil {
+		return "", err
+	}
+	// 向下取整，开始时间之前按轮换倒推
+	elapsed := at.Sub(s.Start)
+	n := int(elapsed / shift)
+	if elapsed%shift < 0 {
+		n--
+	}
+	index := (n%len(s.Members) + len(s.Members)) % len(s.Members)
+	return s.Members[index], nil
+}
+
+// StepDelay 返回步骤的等待时间
+func (s EscalationStep) StepDelay() (time.Duration, error) {
+	if s.Delay == "" {
+		return 0, nil
+	}
+	delay, err := time.ParseDuration(s.Delay)
+	if err != nil || delay < 0 {
+		return 0, fmt.Errorf("无效的等待时间 %q", s.Delay)
+	}
+	return delay, nil
+}
+
+// ValidateSchedule 检查值班表配置和引用的联系人
+func (c *Config) ValidateSchedule(schedule Schedule) error {
+	if _, err := schedule.ShiftDuration(); err != nil {
+		return err
+	}
+	if schedule.Start.IsZero() {
+		return fmt.Errorf("值班表需要配置开始时间")
+	}
+	if len(schedule.Members) == 0 {
+		return fmt.Errorf("值班表至少需要一个成员")
+	}
+	for _, member := range schedule.Members {
+		if _, exists := c.Contacts[member]; !exists {
+			return fmt.Errorf("联系人 %s 不存在", member)
+		}
+	}
+	for _, override := range schedule.Overrides {
+		if _, exists := c.Contacts[override.Contact]; !exists {
+			return fmt.Errorf("联系人 %s 不存在", override.Contact)
+		}
+		if !override.End.After(override.Start) {
+			return fmt.Errorf("调班的结束时间必须晚于开始时间")
+		}
+	}
+	return nil
+}
+
+// ValidateEscalationPolicy 检查升级策略配置和引用的值班表、联系人、通知服务
+func (c *Config) ValidateEscalationPolicy(policy EscalationPolicy) error {
+	if len(policy.Steps) == 0 {
+		return fmt.Errorf("升级策略至少需要一个步骤")
+	}
+	if policy.Repeat < 0 {
+		return fmt.Errorf("重复次数不能小于 0")
+	}
+	for i, step := range policy.Steps {
+		if _, err := step.StepDelay(); err != nil {
+			return fmt.Errorf("步骤 %d: %w", i+1, err)
+		}
+		for _, target := range step.Targets {
+			if id, ok := strings.CutPrefix(target, SchedulePrefix); ok {
+				if _, exists := c.Schedules[id]; !exists {
+					return fmt.Errorf("步骤 %d: 值班表 %s 不存在", i+1, id)
+				}
+			}
+		}
+		if missing := c.MissingContactRefs(strings.Join(step.Targets, ",")); len(missing) > 0 {
+			return fmt.Errorf("步骤 %d: 引用了不存在的联系人 %v", i+1, missing)
+		}
+		for _, name := range step.Notifiers {
+			if _, exists := c.Notifiers[name]; !exists {
+				return fmt.Errorf("步骤 %d: 通知服务 %s 不存在", i+1, name)
+			}
+		}
+	}
+	return nil
+}
+
+// SaveSchedule 创建或更新值班表
+func (cm *ConfigManager) SaveSchedule(id string, schedule Schedule) error {
+	if cm.config == nil {
+		return fmt.Errorf("配置未初始化")
+	}
+	if err := cm.config.ValidateSchedule(schedule); err != nil {
+		return err
+	}
+	if cm.config.Schedules == nil {
+		cm.config.Schedules = make(map[string]Schedule)
+	}
+	cm.config.Schedules[id] = schedule
+	return cm.Save()
+}
+
+// DeleteSchedule 删除值班表
+func (cm *ConfigManager) DeleteSchedule(id string) error {
+	if cm.config == nil {
+		return fmt.Errorf("配置未初始化")
+	}
+	if _, exists := cm.config.Schedules[id]; !exists {
+		return fmt.Errorf("值班表 %s 不存在", id)
+	}
+	delete(cm.config.Schedules, id)
+	return cm.Save()
+}
+
+// SaveEscalationPolicy 创建或更新升级策略
+func (cm *ConfigManager) SaveEscalationPolicy(id string, policy EscalationPolicy) error {
+	if cm.config == nil {
+		return fmt.Errorf("配置未初始化")
+	}
+	if err := cm.config.ValidateEscalationPolicy(policy); err != nil {
+		return err
+	}
+	if cm.config.EscalationPolicies == nil {
+		cm.config.EscalationPolicies = make(map[string]EscalationPolicy)
+	}
+	cm.config.EscalationPolicies[id] = policy
+	return cm.Save()
+}
+
+// DeleteEscalationPolicy 删除升级策略
+func (cm *ConfigManager) DeleteEscalationPolicy(id string) error {
+	if cm.config == nil {
+		return fmt.Errorf("配置未初始化")
+	}
+	if _, exists := cm.config.EscalationPolicies[id]; !exists {
+		return fmt.Errorf("升级策略 %s 不存在", id)
+	}
+	delete(cm.config.EscalationPolicies, id)
+	return cm.Save()
+}
+
+// GetPoliciesUsingSchedule 返回引用了指定值班表的升级策略
+func (cm *ConfigManager) GetPoliciesUsingSchedule(scheduleID string) []string {
+	var policies []string
+	for id, policy := range cm.config.EscalationPolicies {
+		for _, step := range policy.Steps {
+			if containsString(step.Targets, SchedulePrefix+scheduleID) {
+				policies = append(policies, id)
+				break
+			}
+		}
+	}
+	return policies
+}
+
+// GetAppsUsingEscalationPolicy 返回使用指定升级策略的应用
+func (cm *ConfigManager) GetAppsUsingEscalationPolicy(policyID string) []string {
+	var apps []string
+	for id, app := range cm.config.NotificationApps {
+		if app.EscalationPolicy == policyID {
+			apps = append(apps, id)
+		}
+	}
+	return apps
+}
+
+// containsString 判断列表是否包含指定字符串
+func containsString(list []string, value string) bool {
+	for _, item := range list {
+		if item == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,235 @@
+package config
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// 免打扰期间的处理方式
+const (
+	QuietModeDefer  = "defer"  // 暂存到免打扰结束后发送
+	QuietModeDrop   = "drop"   // 丢弃
+	QuietModeSilent = "silent" // 在支持的渠道上静默发送，不支持的渠道正常发送
+)
+
+// QuietHours 免打扰策略，可以被通知应用和通知服务引用
+type QuietHours struct {
+	Name           string        `yaml:"name" json:"name"`
+	Timezone       string        `yaml:"timezone,omitempty" json:"timezone,omitempty"`              // 时区，如 Asia/Shanghai，为空时使用服务器时区
+	Windows        []QuietWindow `yaml:"windows,omitempty" json:"windows,omitempty"`                // 每周重复的免打扰时段
+	Holidays       []string      `yaml:"holidays,omitempty" json:"holidays,omitempty"`              // 全天免打扰的日期，如 2026-10-01
+	Mode           string        `yaml:"mode,omitempty" json:"mode,omitempty"`                      // defer、drop 或 silent，默认 defer
+	BypassSeverity string        `yaml:"bypass_severity,omitempty" json:"bypassSeverity,omitempty"` // 达到该级别的消息不受免打扰限制，如 critical
+}
+
+// QuietWindow 免打扰时段，结束时间早于开始时间表示跨过午夜
+type QuietWindow struct {
+	Start    string   `yaml:"start" json:"start"`                           // 开始时间，如 22:00
+	End      string   `yaml:"end" json:"end"`                               // 结束时间，如 07:30，与开始时间相同表示全天
+	Weekdays []string `yaml:"weekdays,omitempty" json:"weekdays,omitempty"` // 时段开始的星期，如 mon、sat，为空时每天生效
+}
+
+// quietWeekdays 星期名称
+var quietWeekdays = map[string]time.Weekday{
+	"sun": time.Sunday, "mon": time.Monday, "tue": time.Tuesday, "wed": time.Wednesday,
+	"thu": time.Thursday, "fri": time.Friday, "sat": time.Saturday,
+}
+
+// Location 返回免打扰策略的时区
+func (q QuietHours) Location() (*time.Location, error) {
+	if q.Timezone == "" {
+		return time.Local, nil
+	}
+	loc, err := time.LoadLocation(q.Timezone)
+	if err != nil {
+		return nil, fmt.Errorf("无效的时区 %s", q.Timezone)
+	}
+	return loc, nil
+}
+
+// EffectiveMode 返回免打扰期间的处理方式
+func (q QuietHours) EffectiveMode() string {
+	if q.Mode == "" {
+		return QuietModeDefer
+	}
+	return q.Mode
+}
+
+// Validate 检查免打扰策略
+func (q QuietHours) Validate() error {
+	if _, err := q.Location(); err != nil {
+		return err
+	}
+	switch q.EffectiveMode() {
+	case QuietModeDefer, QuietModeDrop, QuietModeSilent:
+	default:
+		return fmt.Errorf("无效的处理方式 %q，可选 defer、drop、silent", q.Mode)
+	}
+	if len(q.Windows) == 0 && len(q.Holidays) == 0 {
+		return fmt.Errorf("免打扰策略至少需要一个时段或节假日")
+	}
+	for i, window := range q.Windows {
+		if _, err := parseClock(window.Start); err != nil {
+			return fmt.Errorf("时段 %d: %w", i+1, err)
+		}
+		if _, err := parseClock(window.End); err != nil {
+			return fmt.Errorf("时段 %d: %w", i+1, err)
+		}
+		for _, day := range window.Weekdays {
+			if _, ok := quietWeekdays[strings.ToLower(day)]; !ok {
+				return fmt.Errorf("时段 %d: 无效的星期 %q，可选 mon、tue、wed、thu、fri、sat、sun", i+1, day)
+			}
+		}
+	}
+	for _, day := range q.Holidays {
+		if _, err := time.Parse(time.DateOnly, day); err != nil {
+			return fmt.Errorf("无效的节假日 %q，格式为 2006-01-02", day)
+		}
+	}
+	return nil
+}
+
+// QuietUntil 判断指定时间是否处于免打扰，是则返回免打扰结束的时间，相连的时段和节假日合并计算
+func (q QuietHours) QuietUntil(at time.Time) (time.Time, bool) {
+	loc, err := q.Location()
+	if err != nil {
+		return time.Time{}, false
+	}
+	end := at.In(loc)
+	// 限制合并次数，避免全周免打扰时无限循环
+	for i := 0; i < 14; i++ {
+		next, ok := q.quietEnd(end, loc)
+		if !ok {
+			break
+		}
+		end = next
+	}
+	if !end.After(at) {
+		return time.Time{}, false
+	}
+	return end, true
+}
+
+// quietEnd 返回包含 t 的免打扰时段或节假日的结束时间
+func (q QuietHours) quietEnd(t time.Time, loc *time.Location) (time.Time, bool) {
+	var end time.Time
+	day := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, loc)
+	for _, holiday := range q.Holidays {
+		if holiday == day.Format(time.DateOnly) {
+			end = day.AddDate(0, 0, 1)
+		}
+	}
+	for _, window := range q.Windows {
+		start, _ := parseClock(window.Start)
+		stop, _ := parseClock(window.End)
+		// 结束时间不晚于开始时间时在第二天结束
+		endDays := 0
+		if stop <= start {
+			endDays = 1
+		}
+		// 跨过午夜的时段可能从前一天开始
+		for _, startDay := range []time.Time{day, day.AddDate(0, 0, -1)} {
+			if !window.matchWeekday(startDay.Weekday()) {
+				continue
+			}
+			// 按当地时间计算起止时间，夏令时切换当天时段仍在设定的钟点开始和结束
+			from := atClock(startDay, 0, start)
+			to := atClock(startDay, endDays, stop)
+			if !t.Before(from) && t.Before(to) && to.After(end) {
+				end = to
+			}
+		}
+	}
+	return end, !end.IsZero()
+}
+
+// atClock 返回 day 之后第 days 天的当地时间 clock
+func atClock(day time.Time, days int, clock time.Duration) time.Time {
+	return time.Date(day.Year(), day.Month(), day.Day()+days, int(clock/time.Hour), int(clock%time.Hour/time.Minute), 0, 0, day.Location())
+}
+
+// matchWeekday 判断时段是否在指定星期开始
+func (w QuietWindow) matchWeekday(weekday time.Weekday) bool {
+	if len(w.Weekdays) == 0 {
+		return true
+	}
+	for _, day := range w.Weekdays {
+		if quietWeekdays[strings.ToLower(day)] == weekday {
+			return true
+		}
+	}
+	return false
+}
+
+// parseClock 解析 HH:MM 格式的时间，返回距零点的时长
+func parseClock(value string) (time.Duration, error) {
+	hour, minute, ok := strings.Cut(value, ":")
+	h, err1 := strconv.Atoi(hour)
+	m, err2 := strconv.Atoi(minute)
+	if !ok || err1 != nil || err2 != nil || h < 0 || h > 23 || m < 0 || m > 59 {
+		return 0, fmt.Errorf("无效的时间 %q，格式为 HH:MM", value)
+	}
+	return time.Duration(h)*time.Hour + time.Duration(m)*time.Minute, nil
+}
+
+// QuietHoursID 返回通知服务引用的免打扰策略
+func (n NotifierInstance) QuietHoursID() string {
+	id, _ := n.Config["quiet_hours"].(string)
+	return id
+}
+
+// ValidateQuietHoursRef 检查引用的免打扰策略是否存在
+func (c *Config) ValidateQuietHoursRef(id string) error {
+	if id == "" {
+		return nil
+	}
+	if _, exists := c.QuietHours[id]; !exists {
+		return fmt.Errorf("免打扰策略 %s 不存在", id)
+	}
+	return nil
+}
+
+// SaveQuietHours 创建或更新免打扰策略
+func (cm *ConfigManager) SaveQuietHours(id string, quietHours QuietHours) error {
+	if cm.config == nil {
+		return fmt.Errorf("配置未初始化")
+	}
+	if err := quietHours.Validate(); err != nil {
+		return err
+	}
+	if cm.config.QuietHours == nil {
+		cm.config.QuietHours = make(map[string]QuietHours)
+	}
+	cm.config.QuietHours[id] = quietHours
+	return cm.Save()
+}
+
+// DeleteQuietHours 删除免打扰策略
+func (cm *ConfigManager) DeleteQuietHours(id string) error {
+	if cm.config == nil {
+		return fmt.Errorf("配置未初始化")
+	}
+	if _, exists := cm.config.QuietHours[id]; !exists {
+		return fmt.Errorf("免打扰策略 %s 不存在", id)
+	}
+	delete(cm.config.QuietHours, id)
+	return cm.Save()
+}
+
+// GetQuietHoursUsers 返回引用了指定免打扰策略的应用和通知服务
+func (cm *ConfigManager) GetQuietHoursUsers(id string) []string {
+	var users []string
+	for appID, app := range cm.config.NotificationApps {
+		if app.QuietHours == id {
+			users = append(users, "app:"+appID)
+		}
+	}
+	for name, instance := range cm.config.Notifiers {
+		if instance.QuietHoursID() == id {
+			users = append(users, "notifier:"+name)
+		}
+	}
+	return users
+}
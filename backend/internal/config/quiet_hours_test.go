@@ -0,0 +1,186 @@
+package config
+
+import (
+	"testing"
+	"time"
+)
+
+func TestQuietUntil(t *testing.T) {
+	shanghai, err := time.LoadLocation("Asia/Shanghai")
+	if err != nil {
+		t.Skip("缺少时区数据:", err)
+	}
+	newYork, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Skip("缺少时区数据:", err)
+	}
+
+	night := []QuietWindow{{Start: "22:00", End: "07:00"}}
+	tests := []struct {
+		name   string
+		quiet  QuietHours
+		at     time.Time
+		want   time.Time
+		wantOK bool
+	}{
+		{
+			name:   "跨午夜时段的前半段",
+			quiet:  QuietHours{Timezone: "UTC", Windows: night},
+			at:     time.Date(2026, 10, 19, 23, 0, 0, 0, time.UTC),
+			want:   time.Date(2026, 10, 20, 7, 0, 0, 0, time.UTC),
+			wantOK: true,
+		},
+		{
+			name:   "跨午夜时段的后半段",
+			quiet:  QuietHours{Timezone: "UTC", Windows: night},
+			at:     time.Date(2026, 10, 20, 3, 0, 0, 0, time.UTC),
+			want:   time.Date(2026, 10, 20, 7, 0, 0, 0, time.UTC),
+			wantOK: true,
+		},
+		{
+			name:   "开始时间包含在时段内",
+			quiet:  QuietHours{Timezone: "UTC", Windows: night},
+			at:     time.Date(2026, 10, 19, 22, 0, 0, 0, time.UTC),
+			want:   time.Date(2026, 10, 20, 7, 0, 0, 0, time.UTC),
+			wantOK: true,
+		},
+		{
+			name:  "结束时间不在时段内",
+			quiet: QuietHours{Timezone: "UTC", Windows: night},
+			at:    time.Date(2026, 10, 20, 7, 0, 0, 0, time.UTC),
+		},
+		{
+			name:  "时段之外",
+			quiet: QuietHours{Timezone: "UTC", Windows: night},
+			at:    time.Date(2026, 10, 20, 12, 0, 0, 0, time.UTC),
+		},
+		{
+			name:   "星期按时段开始的日期判断",
+			quiet:  QuietHours{Timezone: "UTC", Windows: []QuietWindow{{Start: "22:00", End: "07:00", Weekdays: []string{"fri"}}}},
+			at:     time.Date(2026, 10, 24, 3, 0, 0, 0, time.UTC),
+			want:   time.Date(2026, 10, 24, 7, 0, 0, 0, time.UTC),
+			wantOK: true,
+		},
+		{
+			name:  "其他星期不生效",
+			quiet: QuietHours{Timezone: "UTC", Windows: []QuietWindow{{Start: "22:00", End: "07:00", Weekdays: []string{"fri"}}}},
+			at:    time.Date(2026, 10, 25, 3, 0, 0, 0, time.UTC),
+		},
+		{
+			name:   "相连的时段合并",
+			quiet:  QuietHours{Timezone: "UTC", Windows: []QuietWindow{{Start: "09:00", End: "12:00"}, {Start: "12:00", End: "13:30"}}},
+			at:     time.Date(2026, 10, 19, 10, 0, 0, 0, time.UTC),
+			want:   time.Date(2026, 10, 19, 13, 30, 0, 0, time.UTC),
+			wantOK: true,
+		},
+		{
+			name:   "重叠的时段取最晚的结束时间",
+			quiet:  QuietHours{Timezone: "UTC", Windows: []QuietWindow{{Start: "09:00", End: "12:00"}, {Start: "10:00", End: "11:00"}}},
+			at:     time.Date(2026, 10, 19, 10, 30, 0, 0, time.UTC),
+			want:   time.Date(2026, 10, 19, 12, 0, 0, 0, time.UTC),
+			wantOK: true,
+		},
+		{
+			name:   "节假日全天",
+			quiet:  QuietHours{Timezone: "UTC", Holidays: []string{"2026-10-01"}},
+			at:     time.Date(2026, 10, 1, 15, 0, 0, 0, time.UTC),
+			want:   time.Date(2026, 10, 2, 0, 0, 0, 0, time.UTC),
+			wantOK: true,
+		},
+		{
+			name:   "连续的节假日合并",
+			quiet:  QuietHours{Timezone: "UTC", Holidays: []string{"2026-10-01", "2026-10-02", "2026-10-03"}},
+			at:     time.Date(2026, 10, 1, 15, 0, 0, 0, time.UTC),
+			want:   time.Date(2026, 10, 4, 0, 0, 0, 0, time.UTC),
+			wantOK: true,
+		},
+		{
+			name:   "夜间时段和节假日合并",
+			quiet:  QuietHours{Timezone: "UTC", Windows: night, Holidays: []string{"2026-10-01"}},
+			at:     time.Date(2026, 9, 30, 23, 0, 0, 0, time.UTC),
+			want:   time.Date(2026, 10, 2, 7, 0, 0, 0, time.UTC),
+			wantOK: true,
+		},
+		{
+			name:   "按策略的时区计算",
+			quiet:  QuietHours{Timezone: "Asia/Shanghai", Windows: night},
+			at:     time.Date(2026, 10, 19, 15, 0, 0, 0, time.UTC),
+			want:   time.Date(2026, 10, 20, 7, 0, 0, 0, shanghai),
+			wantOK: true,
+		},
+		{
+			name:  "服务器时区的夜间在策略时区不是免打扰",
+			quiet: QuietHours{Timezone: "Asia/Shanghai", Windows: night},
+			at:    time.Date(2026, 10, 19, 23, 0, 0, 0, time.UTC),
+		},
+		{
+			name:   "节假日按策略的时区判断",
+			quiet:  QuietHours{Timezone: "Asia/Shanghai", Holidays: []string{"2026-10-01"}},
+			at:     time.Date(2026, 9, 30, 17, 0, 0, 0, time.UTC),
+			want:   time.Date(2026, 10, 2, 0, 0, 0, 0, shanghai),
+			wantOK: true,
+		},
+		{
+			name:   "夏令时结束当天按当地时间结束",
+			quiet:  QuietHours{Timezone: "America/New_York", Windows: []QuietWindow{{Start: "00:00", End: "06:00"}}},
+			at:     time.Date(2026, 11, 1, 0, 30, 0, 0, newYork),
+			want:   time.Date(2026, 11, 1, 6, 0, 0, 0, newYork),
+			wantOK: true,
+		},
+		{
+			name:   "夏令时开始当天按当地时间开始",
+			quiet:  QuietHours{Timezone: "America/New_York", Windows: night},
+			at:     time.Date(2026, 3, 8, 22, 0, 0, 0, newYork),
+			want:   time.Date(2026, 3, 9, 7, 0, 0, 0, newYork),
+			wantOK: true,
+		},
+		{
+			name:  "夏令时开始当天时段开始前",
+			quiet: QuietHours{Timezone: "America/New_York", Windows: night},
+			at:    time.Date(2026, 3, 8, 21, 30, 0, 0, newYork),
+		},
+		{
+			name:  "无效的时区不生效",
+			quiet: QuietHours{Timezone: "Nowhere/Invalid", Windows: night},
+			at:    time.Date(2026, 10, 19, 23, 0, 0, 0, time.UTC),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := tt.quiet.QuietUntil(tt.at)
+			if ok != tt.wantOK {
+				t.Fatalf("QuietUntil(%v) ok = %v, want %v", tt.at, ok, tt.wantOK)
+			}
+			if ok && !got.Equal(tt.want) {
+				t.Errorf("QuietUntil(%v) = %v, want %v", tt.at, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestQuietHoursValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		quiet   QuietHours
+		wantErr bool
+	}{
+		{name: "时段", quiet: QuietHours{Windows: []QuietWindow{{Start: "22:00", End: "07:00", Weekdays: []string{"Mon", "sat"}}}}},
+		{name: "节假日", quiet: QuietHours{Holidays: []string{"2026-10-01"}, Mode: QuietModeDrop}},
+		{name: "没有时段和节假日", quiet: QuietHours{}, wantErr: true},
+		{name: "无效的时间", quiet: QuietHours{Windows: []QuietWindow{{Start: "24:00", End: "07:00"}}}, wantErr: true},
+		{name: "无效的星期", quiet: QuietHours{Windows: []QuietWindow{{Start: "22:00", End: "07:00", Weekdays: []string{"monday"}}}}, wantErr: true},
+		{name: "无效的节假日", quiet: QuietHours{Holidays: []string{"2026/10/01"}}, wantErr: true},
+		{name: "无效的处理方式", quiet: QuietHours{Holidays: []string{"2026-10-01"}, Mode: "mute"}, wantErr: true},
+		{name: "无效的时区", quiet: QuietHours{Timezone: "Nowhere/Invalid", Holidays: []string{"2026-10-01"}}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.quiet.Validate()
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,157 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"time"
+)
+
+// RateLimit 令牌桶限流，每个周期补充 rate 个令牌，最多积累 burst 个
+type RateLimit struct {
+	Rate  float64 `json:"rate"`            // 每个周期允许发送的消息数
+	Per   string  `json:"per,omitempty"`   // 周期，默认 1s
+	Burst int     `json:"burst,omitempty"` // 桶容量，默认为 rate 向上取整
+}
+
+// NotifierRateLimit 通知服务的发送限流，超出限制的消息排队等待，等待超过 max_wait 时发送失败
+type NotifierRateLimit struct {
+	RateLimit
+	PerTarget *RateLimit `json:"per_target,omitempty"` // 每个发送目标单独的限流
+	MaxWait   string     `json:"max_wait,omitempty"`   // 排队等待的最长时间，默认 30s
+	Disabled  bool       `json:"disabled,omitempty"`   // 关闭该通知服务的限流，包括类型默认的限流
+}
+
+// defaultRateLimits 各类通知服务按平台配额设置的默认限流
+var defaultRateLimits = map[NotifiersType]NotifierRateLimit{
+	// 钉钉机器人每分钟最多 20 条
+	DingTalkAppBot: {RateLimit: RateLimit{Rate: 20, Per: "1m"}},
+	// 企业微信群机器人每分钟最多 20 条
+	WechatWorkWebhookBot: {RateLimit: RateLimit{Rate: 20, Per: "1m"}},
+	// Telegram 全局每秒约 30 条，同一个聊天每秒 1 条
+	TelegramAppBot: {RateLimit: RateLimit{Rate: 30, Per: "1s"}, PerTarget: &RateLimit{Rate: 1, Per: "1s"}},
+	// 飞书发送消息接口每秒 50 次
+	FeishuAppBot: {RateLimit: RateLimit{Rate: 50, Per: "1s"}},
+}
+
+// PerSecond 返回每秒补充的令牌数和桶容量
+func (r RateLimit) PerSecond() (float64, float64, error) {
+	if r.Rate <= 0 {
+		return 0, 0, fmt.Errorf("限流的 rate 必须大于 0")
+	}
+	per := time.Second
+	if r.Per != "" {
+		parsed, err := time.ParseDuration(r.Per)
+		if err != nil || parsed <= 0 {
+			return 0, 0, fmt.Errorf("无效的限流周期 %q", r.Per)
+		}
+		per = parsed
+	}
+	if r.Burst < 0 {
+		return 0, 0, fmt.Errorf("限流的 burst 不能小于 0")
+	}
+	burst := float64(r.Burst)
+	if burst == 0 {
+		burst = math.Ceil(r.Rate)
+	}
+	return r.Rate / per.Seconds(), burst, nil
+}
+
+// MaxWaitDuration 返回排队等待的最长时间
+func (r NotifierRateLimit) MaxWaitDuration() (time.Duration, error) {
+	if r.MaxWait == "" {
+		return 30 * time.Second, nil
+	}
+	wait, err := time.ParseDuration(r.MaxWait)
+	if err != nil || wait < 0 {
+		return 0, fmt.Errorf("无效的最长等待时间 %q", r.MaxWait)
+	}
+	return wait, nil
+}
+
+// RateLimit 返回通知服务的限流配置，配置了 rate_limit 时替换类型的默认限流，没有限流时返回 nil
+func (n NotifierInstance) RateLimit() (*NotifierRateLimit, error) {
+	raw, exists := n.Config["rate_limit"]
+	if !exists || raw == nil {
+		if limit, exists := defaultRateLimits[n.Type]; exists {
+			return &limit, nil
+		}
+		return nil, nil
+	}
+
+	// 配置文件和管理接口中的 rate_limit 都是 map，按 JSON 转换为结构体
+	data, err := json.Marshal(raw)
+	if err != nil {
+		return nil, fmt.Errorf("解析 rate_limit 失败: %w", err)
+	}
+	var limit NotifierRateLimit
+	if err := json.Unmarshal(data, &limit); err != nil {
+		return nil, fmt.Errorf("解析 rate_limit 失败: %w", err)
+	}
+	if limit.Disabled {
+		return nil, nil
+	}
+	if _, _, err := limit.PerSecond(); err != nil {
+		return nil, err
+	}
+	if limit.PerTarget != nil {
+		if _, _, err := limit.PerTarget.PerSecond(); err != nil {
+			return nil, fmt.Errorf("per_target: %w", err)
+		}
+	}
+	if _, err := limit.MaxWaitDuration(); err != nil {
+		return nil, err
+	}
+	return &limit, nil
+}
+
+// RequestLimit 入站请求限制，rps 为 0 时不限制频率，daily_quota 为 0 时不限制每日请求数
+type RequestLimit struct {
+	RPS        float64 `yaml:"rps,omitempty" json:"rps,omitempty"`                // 每秒允许的请求数
+	Burst      int     `yaml:"burst,omitempty" json:"burst,omitempty"`            // 允许的突发请求数，默认为 rps 向上取整
+	DailyQuota int     `yaml:"daily_quota,omitempty" json:"dailyQuota,omitempty"` // 每天允许的请求数，按服务器时区的自然日计算
+}
+
+// AppRateLimit 通知应用的入站请求限制，应用整体和每个客户端 IP 分别计算
+type AppRateLimit struct {
+	RequestLimit `yaml:",inline"`
+	PerIP        *RequestLimit `yaml:"per_ip,omitempty" json:"perIp,omitempty"`
+}
+
+// Bucket 返回令牌桶配置，没有频率限制时返回 nil
+func (r RequestLimit) Bucket() *RateLimit {
+	if r.RPS <= 0 {
+		return nil
+	}
+	return &RateLimit{Rate: r.RPS, Per: "1s", Burst: r.Burst}
+}
+
+// validate 检查请求限制的取值
+func (r RequestLimit) validate() error {
+	if r.RPS < 0 {
+		return fmt.Errorf("rps 不能小于 0")
+	}
+	if r.Burst < 0 {
+		return fmt.Errorf("burst 不能小于 0")
+	}
+	if r.DailyQuota < 0 {
+		return fmt.Errorf("daily_quota 不能小于 0")
+	}
+	return nil
+}
+
+// ValidateRateLimit 检查应用的入站请求限制
+func (a NotificationApp) ValidateRateLimit() error {
+	if a.RateLimit == nil {
+		return nil
+	}
+	if err := a.RateLimit.validate(); err != nil {
+		return fmt.Errorf("请求限制配置错误: %w", err)
+	}
+	if a.RateLimit.PerIP != nil {
+		if err := a.RateLimit.PerIP.validate(); err != nil {
+			return fmt.Errorf("请求限制配置错误: per_ip %w", err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,200 @@
+package media
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"mime"
+	"net/http"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	"notify/internal/logger"
+)
+
+// namePattern 媒体文件名格式：sha256 哈希加可选的扩展名
+var namePattern = regexp.MustCompile(`^[0-9a-f]{64}(\.[0-9a-z]+)?$`)
+
+// Store 本地媒体存储，按内容哈希保存图片和文件，通过 /media/:name 对外提供访问
+type Store struct {
+	dir     string
+	baseURL string
+	ttl     time.Duration
+	mu      sync.Mutex
+	stop    chan struct{}
+}
+
+// Object 已保存的媒体文件
+type Object struct {
+	Name        string    `json:"name"`
+	URL         string    `json:"url"`
+	ContentType string    `json:"contentType"`
+	Size        int       `json:"size"`
+	ExpiresAt   time.Time `json:"expiresAt"`
+}
+
+// New 创建媒体存储，baseURL 为服务的公网地址，为空时不启用媒体托管
+func New(dir, baseURL string, ttl time.Duration) (*Store, error) {
+	if baseURL == "" {
+		return nil, nil
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, fmt.Errorf("创建媒体目录失败: %w", err)
+	}
+	return &Store{
+		dir:     dir,
+		baseURL: strings.TrimSuffix(baseURL, "/"),
+		ttl:     ttl,
+	}, nil
+}
+
+// inlineExtensions 可以在浏览器中直接显示的文件扩展名，其他文件都以下载方式返回
+var inlineExtensions = map[string]bool{".jpg": true, ".png": true, ".gif": true, ".webp": true}
+
+// IsInline 判断媒体文件是否可以在浏览器中直接显示，只允许不能执行脚本的位图格式
+func IsInline(name string) bool {
+	return inlineExtensions[filepath.Ext(name)]
+}
+
+// Put 保存内容，相同内容只保存一份并刷新过期时间
+// SVG 可以包含脚本，按普通二进制文件保存，避免被当作图片在本服务域名下渲染
+func (s *Store) Put(data []byte, contentType string) (*Object, error) {
+	if contentType == "" {
+		contentType = http.DetectContentType(data)
+	}
+	if mediaType, _, _ := mime.ParseMediaType(contentType); mediaType == "image/svg+xml" {
+		contentType = "application/octet-stream"
+	}
+	sum := sha256.Sum256(data)
+	name := hex.EncodeToString(sum[:]) + extension(contentType)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	path := filepath.Join(s.dir, name)
+	now := time.Now()
+	if _, err := os.Stat(path); err == nil {
+		if err := os.Chtimes(path, now, now); err != nil {
+			return nil, fmt.Errorf("更新媒体文件失败: %w", err)
+		}
+	} else {
+		tmp := path + ".tmp"
+		if err := os.WriteFile(tmp, data, 0644); err != nil {
+			return nil, fmt.Errorf("保存媒体文件失败: %w", err)
+		}
+		if err := os.Rename(tmp, path); err != nil {
+			return nil, fmt.Errorf("保存媒体文件失败: %w", err)
+		}
+	}
+
+	return &Object{
+		Name:        name,
+		URL:         s.URL(name),
+		ContentType: contentType,
+		Size:        len(data),
+		ExpiresAt:   now.Add(s.ttl),
+	}, nil
+}
+
+// URL 返回媒体文件的公网地址
+func (s *Store) URL(name string) string {
+	return fmt.Sprintf("%s/media/%s", s.baseURL, name)
+}
+
+// IsHosted 判断地址是否已经指向本服务的媒体文件
+func (s *Store) IsHosted(url string) bool {
+	return strings.HasPrefix(url, s.baseURL+"/media/")
+}
+
+// Open 返回媒体文件路径和过期时间，文件不存在或已过期时返回错误
+func (s *Store) Open(name string) (string, time.Time, error) {
+	if !namePattern.MatchString(name) {
+		return "", time.Time{}, os.ErrNotExist
+	}
+	path := filepath.Join(s.dir, name)
+	info, err := os.Stat(path)
+	if err != nil {
+		return "", time.Time{}, err
+	}
+	expiresAt := info.ModTime().Add(s.ttl)
+	if time.Now().After(expiresAt) {
+		return "", time.Time{}, os.ErrNotExist
+	}
+	return path, expiresAt, nil
+}
+
+// Cleanup 删除过期的媒体文件
+func (s *Store) Cleanup() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	entries, err := os.ReadDir(s.dir)
+	if err != nil {
+		logger.Error("读取媒体目录失败", "error", err)
+		return
+	}
+	deadline := time.Now().Add(-s.ttl)
+	for _, entry := range entries {
+		info, err := entry.Info()
+		if err != nil || entry.IsDir() || info.ModTime().After(deadline) {
+			continue
+		}
+		if err := os.Remove(filepath.Join(s.dir, entry.Name())); err != nil {
+			logger.Error("删除过期媒体文件失败", "name", entry.Name(), "error", err)
+		}
+	}
+}
+
+// Start 启动定期清理过期文件
+func (s *Store) Start(interval time.Duration) {
+	s.stop = make(chan struct{})
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				s.Cleanup()
+			case <-s.stop:
+				return
+			}
+		}
+	}()
+}
+
+// Stop 停止定期清理
+func (s *Store) Stop() {
+	if s.stop != nil {
+		close(s.stop)
+	}
+}
+
+// extension 根据内容类型返回文件扩展名
+func extension(contentType string) string {
+	mediaType, _, _ := mime.ParseMediaType(contentType)
+	switch mediaType {
+	case "image/jpeg":
+		return ".jpg"
+	case "image/png":
+		return ".png"
+	case "image/gif":
+		return ".gif"
+	case "image/webp":
+		return ".webp"
+	case "text/plain":
+		return ".txt"
+	case "application/pdf":
+		return ".pdf"
+	case "application/json":
+		return ".json"
+	}
+	exts, _ := mime.ExtensionsByType(mediaType)
+	if len(exts) > 0 {
+		return strings.ToLower(exts[0])
+	}
+	return ""
+}
@@ -0,0 +1,114 @@
+package metrics
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// 指标类型
+const (
+	TypeCounter = "counter"
+	TypeGauge   = "gauge"
+)
+
+// Sample 指标的一个取值
+type Sample struct {
+	Labels map[string]string
+	Value  float64
+}
+
+// Family 同名指标的说明和所有取值
+type Family struct {
+	Name    string
+	Help    string
+	Type    string
+	Samples []Sample
+}
+
+// Write 按 Prometheus 文本格式输出指标，标签按名称排序
+func Write(w io.Writer, families []Family) error {
+	buf := bufio.NewWriter(w)
+	for _, family := range families {
+		fmt.Fprintf(buf, "# HELP %s %s\n", family.Name, family.Help)
+		fmt.Fprintf(buf, "# TYPE %s %s\n", family.Name, family.Type)
+		for _, sample := range family.Samples {
+			buf.WriteString(family.Name)
+			if len(sample.Labels) > 0 {
+				names := make([]string, 0, len(sample.Labels))
+				for name := range sample.Labels {
+					names = append(names, name)
+				}
+				sort.Strings(names)
+				pairs := make([]string, 0, len(names))
+				for _, name := range names {
+					pairs = append(pairs, fmt.Sprintf("%s=%s", name, strconv.Quote(sample.Labels[name])))
+				}
+				buf.WriteString("{" + strings.Join(pairs, ",") + "}")
+			}
+			buf.WriteString(" " + strconv.FormatFloat(sample.Value, 'g', -1, 64) + "\n")
+		}
+	}
+	return buf.Flush()
+}
+
+// CounterVec 按标签分别计数的计数器
+type CounterVec struct {
+	name   string
+	help   string
+	labels []string
+
+	mu     sync.Mutex
+	values map[string]float64 // 标签值用 \xff 连接作为键
+}
+
+// NewCounterVec 创建计数器，labels 为标签名
+func NewCounterVec(name, help string, labels ...string) *CounterVec {
+	return &CounterVec{
+		name:   name,
+		help:   help,
+		labels: labels,
+		values: make(map[string]float64),
+	}
+}
+
+// Inc 计数加一，values 与创建时的标签名一一对应
+func (c *CounterVec) Inc(values ...string) {
+	c.Add(1, values...)
+}
+
+// Add 计数增加 delta
+func (c *CounterVec) Add(delta float64, values ...string) {
+	c.mu.Lock()
+	c.values[strings.Join(values, "\xff")] += delta
+	c.mu.Unlock()
+}
+
+// Family 返回计数器的所有取值，按标签值排序
+func (c *CounterVec) Family() Family {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	keys := make([]string, 0, len(c.values))
+	for key := range c.values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	family := Family{Name: c.name, Help: c.help, Type: TypeCounter, Samples: make([]Sample, 0, len(keys))}
+	for _, key := range keys {
+		values := strings.Split(key, "\xff")
+		labels := make(map[string]string, len(c.labels))
+		for i, name := range c.labels {
+			if i < len(values) {
+				labels[name] = values[i]
+			}
+		}
+		family.Samples = append(family.Samples, Sample{Labels: labels, Value: c.values[key]})
+	}
+	return family
+}
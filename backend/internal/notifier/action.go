@@ -0,0 +1,24 @@
+package notifier
+
+// 按钮样式
+const (
+	ActionStyleDefault = "default"
+	ActionStylePrimary = "primary"
+	ActionStyleDanger  = "danger"
+)
+
+// Action 消息按钮
+type Action struct {
+	Label string `json:"label"`
+	URL   string `json:"url"`
+	Style string `json:"style,omitempty"` // primary、danger 或 default
+}
+
+// buttons 返回消息的全部按钮，消息 URL 作为第一个“查看详情”按钮
+func buttons(message *NotificationMessage) []Action {
+	actions := make([]Action, 0, len(message.Actions)+1)
+	if message.URL != "" {
+		actions = append(actions, Action{Label: "🔗 查看详情", URL: message.URL})
+	}
+	return append(actions, message.Actions...)
+}
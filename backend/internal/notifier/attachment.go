@@ -0,0 +1,122 @@
+package notifier
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"path"
+	"strings"
+	"time"
+)
+
+// MaxAttachmentSize 单个附件的最大大小
+const MaxAttachmentSize = 20 << 20
+
+// downloadClient 下载远程附件使用的客户端
+var downloadClient = &http.Client{Timeout: 30 * time.Second}
+
+// Attachment 消息附件（图片或文件）
+type Attachment struct {
+	Name        string `json:"name"`
+	ContentType string `json:"contentType,omitempty"`
+	URL         string `json:"url,omitempty"`  // 远程地址，没有内容时由通知服务按需下载
+	Size        int    `json:"size,omitempty"` // 内容大小
+	Data        []byte `json:"-"`              // 文件内容，base64 和上传的文件已解码
+
+	Stored string `json:"stored,omitempty"` // 消息需要持久化时内容保存到数据目录的文件名，重启后从数据目录读取
+}
+
+// IsImage 判断附件是否为图片
+func (a *Attachment) IsImage() bool {
+	if a.ContentType != "" {
+		return strings.HasPrefix(a.ContentType, "image/")
+	}
+	return strings.HasPrefix(mime.TypeByExtension(path.Ext(a.Name)), "image/")
+}
+
+// FileName 返回附件文件名，未指定时根据 URL 或类型生成
+func (a *Attachment) FileName() string {
+	if a.Name != "" {
+		return a.Name
+	}
+	if a.URL != "" {
+		if name := path.Base(strings.SplitN(a.URL, "?", 2)[0]); name != "" && name != "/" && name != "." {
+			return name
+		}
+	}
+	if exts, _ := mime.ExtensionsByType(a.ContentType); len(exts) > 0 {
+		return "attachment" + exts[0]
+	}
+	return "attachment"
+}
+
+// Load 返回附件内容，只有 URL 的附件会被下载
+func (a *Attachment) Load(ctx context.Context) ([]byte, error) {
+	if a.Data != nil {
+		return a.Data, nil
+	}
+	if a.URL == "" {
+		return nil, fmt.Errorf("附件 %s 没有内容", a.FileName())
+	}
+
+	data, contentType, err := downloadFile(ctx, a.URL)
+	if err != nil {
+		return nil, err
+	}
+	a.Data = data
+	a.Size = len(data)
+	if a.ContentType == "" {
+		a.ContentType = contentType
+	}
+	return data, nil
+}
+
+// downloadFile 下载远程文件，超过 MaxAttachmentSize 时返回错误
+func downloadFile(ctx context.Context, url string) ([]byte, string, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, "", fmt.Errorf("下载文件失败: %w", err)
+	}
+	resp, err := downloadClient.Do(req)
+	if err != nil {
+		return nil, "", fmt.Errorf("下载文件失败: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, "", fmt.Errorf("下载文件失败，状态码: %d", resp.StatusCode)
+	}
+	data, err := io.ReadAll(io.LimitReader(resp.Body, MaxAttachmentSize+1))
+	if err != nil {
+		return nil, "", fmt.Errorf("下载文件失败: %w", err)
+	}
+	if len(data) > MaxAttachmentSize {
+		return nil, "", fmt.Errorf("文件超过 %d MB", MaxAttachmentSize>>20)
+	}
+
+	contentType := resp.Header.Get("Content-Type")
+	if contentType == "" {
+		contentType = http.DetectContentType(data)
+	}
+	return data, contentType, nil
+}
+
+// attachmentLinks 生成附件的 Markdown 链接，没有 URL 的附件只列出文件名
+// 用于不支持上传文件的通知服务
+func attachmentLinks(attachments []Attachment) string {
+	if len(attachments) == 0 {
+		return ""
+	}
+	lines := make([]string, 0, len(attachments))
+	for i := range attachments {
+		attachment := &attachments[i]
+		if attachment.URL != "" {
+			lines = append(lines, fmt.Sprintf("📎 [%s](%s)", attachment.FileName(), attachment.URL))
+		} else {
+			lines = append(lines, fmt.Sprintf("📎 %s", attachment.FileName()))
+		}
+	}
+	return strings.Join(lines, "\n")
+}
@@ -1,6 +1,7 @@
 package notifier
 
 import (
+	"bytes"
 	"context"
 	"crypto/hmac"
 	"crypto/sha256"
@@ -11,7 +12,7 @@ import (
 	"time"
 
 	"notify/internal/config"
-	"notify/internal/utils"
+	"notify/internal/logger"
 
 	"github.com/go-resty/resty/v2"
 )
@@ -117,6 +118,14 @@ func (d *DingTalkNotifier) Send(ctx context.Context, message *NotificationMessag
 	// }
 	//全部用 markdown
 	requestBody = d.buildMarkdownMessage(message, targets)
+	if len(message.Attachments) > 0 {
+		markdown := requestBody["markdown"].(map[string]interface{})
+		markdown["text"] = fmt.Sprintf("%s\n\n%s", markdown["text"], d.attachmentMarkdown(ctx, message.Attachments))
+	}
+	// 有按钮时使用 ActionCard
+	if len(message.Actions) > 0 {
+		requestBody = d.buildActionCardMessage(message, requestBody)
+	}
 
 	return d.sendMessage(ctx, queryParams, requestBody)
 }
@@ -135,28 +144,140 @@ func (d *DingTalkNotifier) buildMarkdownMessage(message *NotificationMessage, ta
 			"text":  content,
 		},
 	}
-	// 使用正则表达式检测 target 是不是一个有效的手机号，是手机号的放在一起，其他类型的放在一起
-	phoneTargets := []string{}
-	otherTargets := []string{}
-	for _, target := range targets {
-		if utils.IsMobilePhone(target) {
-			phoneTargets = append(phoneTargets, target)
-		} else {
-			otherTargets = append(otherTargets, target)
-		}
+	// 自定义机器人没有发送目标，兼容旧配置把 targets 也作为@对象
+	mentions := splitMentions(append(append([]string(nil), message.Mentions...), targets...))
+	// 严重级别的消息@所有人
+	if message.Severity == SeverityCritical {
+		mentions.All = true
 	}
-	// 如果指定了@用户
-	if len(targets) > 0 {
+	if !mentions.IsEmpty() {
 		requestBody["at"] = map[string]interface{}{
-			"atMobiles": phoneTargets,
-			"atUserIds": otherTargets,
-			"isAtAll":   false,
+			"atMobiles": mentions.Mobiles,
+			"atUserIds": mentions.Users,
+			"isAtAll":   mentions.All,
+		}
+		// Markdown 消息需要在正文中包含 @手机号 或 @userId 才会显示提及
+		var names []string
+		for _, id := range append(append([]string(nil), mentions.Mobiles...), mentions.Users...) {
+			names = append(names, "@"+id)
+		}
+		if len(names) > 0 {
+			markdown := requestBody["markdown"].(map[string]interface{})
+			markdown["text"] = fmt.Sprintf("%s\n\n%s", content, strings.Join(names, " "))
 		}
 	}
 
 	return requestBody
 }
 
+// attachmentMarkdown 把附件转换为 Markdown
+// 配置了应用凭证时图片上传后以 media_id 显示，自定义机器人不支持发送文件，其他附件显示为链接
+func (d *DingTalkNotifier) attachmentMarkdown(ctx context.Context, attachments []Attachment) string {
+	var (
+		lines []string
+		links []Attachment
+	)
+	for i := range attachments {
+		attachment := &attachments[i]
+		if !attachment.IsImage() {
+			links = append(links, *attachment)
+			continue
+		}
+		if d.config.AppKey != "" && d.config.AppSecret != "" {
+			mediaID, err := d.uploadMedia(ctx, attachment)
+			if err == nil {
+				lines = append(lines, fmt.Sprintf("![%s](%s)", attachment.FileName(), mediaID))
+				continue
+			}
+			logger.Error("上传钉钉图片失败", "name", attachment.FileName(), "error", err)
+		}
+		if attachment.URL != "" {
+			lines = append(lines, fmt.Sprintf("![%s](%s)", attachment.FileName(), attachment.URL))
+			continue
+		}
+		links = append(links, *attachment)
+	}
+	if text := attachmentLinks(links); text != "" {
+		lines = append(lines, text)
+	}
+	return strings.Join(lines, "\n\n")
+}
+
+// uploadMedia 使用应用凭证上传图片，返回 media_id
+func (d *DingTalkNotifier) uploadMedia(ctx context.Context, attachment *Attachment) (string, error) {
+	data, err := attachment.Load(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	var token struct {
+		DingTalkResponse
+		AccessToken string `json:"access_token"`
+	}
+	resp, err := d.client.R().
+		SetContext(ctx).
+		SetQueryParams(map[string]string{
+			"appkey":    d.config.AppKey,
+			"appsecret": d.config.AppSecret,
+		}).
+		SetResult(&token).
+		Get("https://oapi.dingtalk.com/gettoken")
+	if err != nil {
+		return "", fmt.Errorf("获取访问令牌失败: %w", err)
+	}
+	if !resp.IsSuccess() || token.ErrCode != 0 {
+		return "", fmt.Errorf("获取访问令牌失败: %s", token.ErrMsg)
+	}
+
+	var result struct {
+		DingTalkResponse
+		MediaID string `json:"media_id"`
+	}
+	resp, err = d.client.R().
+		SetContext(ctx).
+		SetQueryParams(map[string]string{
+			"access_token": token.AccessToken,
+			"type":         "image",
+		}).
+		SetFileReader("media", attachment.FileName(), bytes.NewReader(data)).
+		SetResult(&result).
+		Post("https://oapi.dingtalk.com/media/upload")
+	if err != nil {
+		return "", fmt.Errorf("上传图片失败: %w", err)
+	}
+	if !resp.IsSuccess() || result.ErrCode != 0 {
+		return "", fmt.Errorf("上传图片失败: %s", result.ErrMsg)
+	}
+	return result.MediaID, nil
+}
+
+// buildActionCardMessage 把 Markdown 消息转换为带按钮的 ActionCard 消息，ActionCard 不支持@功能
+func (d *DingTalkNotifier) buildActionCardMessage(message *NotificationMessage, markdownBody map[string]interface{}) map[string]interface{} {
+	markdown := markdownBody["markdown"].(map[string]interface{})
+	btns := []map[string]interface{}{}
+	for _, action := range buttons(message) {
+		btns = append(btns, map[string]interface{}{
+			"title":     action.Label,
+			"actionURL": action.URL,
+		})
+	}
+
+	// 按钮超过两个时竖直排列
+	orientation := "1"
+	if len(btns) <= 2 {
+		orientation = "0"
+	}
+	return map[string]interface{}{
+		"msgtype": "actionCard",
+		"actionCard": map[string]interface{}{
+			"title":          message.Title,
+			"text":           markdown["text"],
+			"btnOrientation": orientation,
+			"btns":           btns,
+		},
+	}
+}
+
 // buildFeedCardMessage 构建FeedCard消息（支持图片）
 func (d *DingTalkNotifier) buildFeedCardMessage(message *NotificationMessage, targets []string) map[string]interface{} {
 	links := []map[string]interface{}{
@@ -1,6 +1,7 @@
 package notifier
 
 import (
+	"bytes"
 	"context"
 	"encoding/json"
 	"fmt"
@@ -9,7 +10,6 @@ import (
 	"notify/internal/config"
 	"notify/internal/logger"
 
-	"github.com/go-resty/resty/v2"
 	lark "github.com/larksuite/oapi-sdk-go/v3"
 	larkim "github.com/larksuite/oapi-sdk-go/v3/service/im/v1"
 )
@@ -85,6 +85,17 @@ func (f *FeishuNotifier) sendAPIMessage(ctx context.Context, message *Notificati
 		return fmt.Errorf("未指定消息发送目标")
 	}
 
+	// 构建消息内容，图片和文件只上传一次，有按钮或级别不低于 warning 时使用带颜色标题的消息卡片
+	imageKeys := f.uploadMessageImages(ctx, message)
+	msgType, content := "post", f.buildAPIMessageContent(message, imageKeys)
+	if len(message.Actions) > 0 || SeverityRank(message.Severity) >= SeverityRank(SeverityWarning) {
+		msgType, content = "interactive", f.buildCardContent(message, imageKeys)
+	}
+	fileKeys, err := f.uploadFiles(ctx, message.Attachments)
+	if err != nil {
+		return err
+	}
+
 	// 为每个目标发送消息
 	for _, target := range targets {
 		target = strings.TrimSpace(target)
@@ -92,47 +103,60 @@ func (f *FeishuNotifier) sendAPIMessage(ctx context.Context, message *Notificati
 			continue
 		}
 
-		// 构建消息内容
-		content := f.buildAPIMessageContent(message)
+		// 使用rich_text支持更丰富的格式
+		if err := f.createMessage(ctx, target, msgType, content); err != nil {
+			return err
+		}
 
-		// 判断目标类型并设置接收者ID类型
-		receiveIdType := f.getReceiveIdType(target)
+		for _, fileKey := range fileKeys {
+			fileContent, _ := json.Marshal(map[string]string{"file_key": fileKey})
+			if err := f.createMessage(ctx, target, "file", string(fileContent)); err != nil {
+				return err
+			}
+		}
+	}
 
-		// 创建请求
-		req := larkim.NewCreateMessageReqBuilder().
-			ReceiveIdType(receiveIdType).
-			Body(larkim.NewCreateMessageReqBodyBuilder().
-				ReceiveId(target).
-				MsgType("post"). // 使用rich_text支持更丰富的格式
-				Content(content).
-				Build()).
-			Build()
+	return nil
+}
 
-		// 发起请求
-		resp, err := f.larkClient.Im.V1.Message.Create(ctx, req)
-		if err != nil {
-			return fmt.Errorf("发送消息到 %s 失败: %w", target, err)
-		}
+// createMessage 向单个目标发送消息
+func (f *FeishuNotifier) createMessage(ctx context.Context, target, msgType, content string) error {
+	// 判断目标类型并设置接收者ID类型
+	receiveIdType := f.getReceiveIdType(target)
+
+	// 创建请求
+	req := larkim.NewCreateMessageReqBuilder().
+		ReceiveIdType(receiveIdType).
+		Body(larkim.NewCreateMessageReqBodyBuilder().
+			ReceiveId(target).
+			MsgType(msgType).
+			Content(content).
+			Build()).
+		Build()
 
-		// 检查响应
-		if !resp.Success() {
-			logger.Error("resp", resp.Err)
-			logger.Error("err", resp.Code)
-			logger.Error("err", resp.Msg)
-			return fmt.Errorf("发送消息到 %s 失败: %s", target, resp.Msg)
-		}
+	// 发起请求
+	resp, err := f.larkClient.Im.V1.Message.Create(ctx, req)
+	if err != nil {
+		return fmt.Errorf("发送消息到 %s 失败: %w", target, err)
 	}
 
+	// 检查响应
+	if !resp.Success() {
+		logger.Error("resp", resp.Err)
+		logger.Error("err", resp.Code)
+		logger.Error("err", resp.Msg)
+		return fmt.Errorf("发送消息到 %s 失败: %s", target, resp.Msg)
+	}
 	return nil
 }
 
 // buildAPIMessageContent 构建API消息内容（rich_text格式）
-func (f *FeishuNotifier) buildAPIMessageContent(message *NotificationMessage) string {
+func (f *FeishuNotifier) buildAPIMessageContent(message *NotificationMessage, imageKeys []string) string {
 	// 构建富文本内容
 	content := map[string]interface{}{
 		"zh_cn": map[string]interface{}{
 			"title":   message.Title,
-			"content": f.buildRichTextElements(message),
+			"content": f.buildRichTextElements(message, imageKeys),
 		},
 	}
 
@@ -141,19 +165,16 @@ func (f *FeishuNotifier) buildAPIMessageContent(message *NotificationMessage) st
 	return string(contentBytes)
 }
 
-func (f *FeishuNotifier) buildImageElement(message *NotificationMessage) (string, error) {
-	image, err := resty.New().R().SetDoNotParseResponse(true).Get(message.Image)
-	if err != nil {
-		return "", err
-	}
+// uploadImage 上传图片，返回 image_key
+func (f *FeishuNotifier) uploadImage(ctx context.Context, data []byte) (string, error) {
 	req := larkim.NewCreateImageReqBuilder().
 		Body(larkim.NewCreateImageReqBodyBuilder().
 			ImageType("message").
-			Image(image.RawBody()).
+			Image(bytes.NewReader(data)).
 			Build()).
 		Build()
 
-	resp, err := f.larkClient.Im.Image.Create(context.Background(), req)
+	resp, err := f.larkClient.Im.Image.Create(ctx, req)
 	if err != nil {
 		return "", err
 	}
@@ -164,14 +185,163 @@ func (f *FeishuNotifier) buildImageElement(message *NotificationMessage) (string
 	return *resp.Data.ImageKey, nil
 }
 
-// buildRichTextElements 构建富文本元素
-func (f *FeishuNotifier) buildRichTextElements(message *NotificationMessage) [][]map[string]interface{} {
-	elements := [][]map[string]interface{}{}
+// uploadFiles 上传非图片附件，返回 file_key 列表
+func (f *FeishuNotifier) uploadFiles(ctx context.Context, attachments []Attachment) ([]string, error) {
+	var fileKeys []string
+	for i := range attachments {
+		attachment := &attachments[i]
+		if attachment.IsImage() {
+			continue
+		}
+		data, err := attachment.Load(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("读取附件 %s 失败: %w", attachment.FileName(), err)
+		}
+
+		req := larkim.NewCreateFileReqBuilder().
+			Body(larkim.NewCreateFileReqBodyBuilder().
+				FileType("stream").
+				FileName(attachment.FileName()).
+				File(bytes.NewReader(data)).
+				Build()).
+			Build()
+		resp, err := f.larkClient.Im.File.Create(ctx, req)
+		if err != nil {
+			return nil, fmt.Errorf("上传附件 %s 失败: %w", attachment.FileName(), err)
+		}
+		if !resp.Success() {
+			return nil, fmt.Errorf("上传附件 %s 失败: %s", attachment.FileName(), resp.Msg)
+		}
+		fileKeys = append(fileKeys, *resp.Data.FileKey)
+	}
+	return fileKeys, nil
+}
+
+// uploadMessageImages 上传消息图片和图片附件，返回 image_key 列表，上传失败的图片会被忽略
+func (f *FeishuNotifier) uploadMessageImages(ctx context.Context, message *NotificationMessage) []string {
+	images := []*Attachment{}
 	if message.Image != "" {
-		imageKey, err := f.buildImageElement(message)
+		images = append(images, &Attachment{URL: message.Image})
+	}
+	for i := range message.Attachments {
+		if message.Attachments[i].IsImage() {
+			images = append(images, &message.Attachments[i])
+		}
+	}
+
+	var imageKeys []string
+	for _, image := range images {
+		data, err := image.Load(ctx)
 		if err != nil {
-			logger.Error("buildImageElement", err)
+			logger.Error("上传图片失败", "error", err)
+			continue
+		}
+		imageKey, err := f.uploadImage(ctx, data)
+		if err != nil {
+			logger.Error("上传图片失败", "error", err)
+			continue
+		}
+		imageKeys = append(imageKeys, imageKey)
+	}
+	return imageKeys
+}
+
+// buildCardContent 构建消息卡片内容，按钮显示在卡片底部
+func (f *FeishuNotifier) buildCardContent(message *NotificationMessage, imageKeys []string) string {
+	elements := []map[string]interface{}{}
+	for _, imageKey := range imageKeys {
+		elements = append(elements, map[string]interface{}{
+			"tag":     "img",
+			"img_key": imageKey,
+			"alt":     map[string]interface{}{"tag": "plain_text", "content": ""},
+		})
+	}
+	if message.Content != "" {
+		elements = append(elements, map[string]interface{}{
+			"tag":     "markdown",
+			"content": message.Content,
+		})
+	}
+	if ids := feishuMentionIDs(message); len(ids) > 0 {
+		var at strings.Builder
+		for _, id := range ids {
+			at.WriteString(fmt.Sprintf("<at id=%s></at> ", id))
+		}
+		elements = append(elements, map[string]interface{}{
+			"tag":     "markdown",
+			"content": strings.TrimSpace(at.String()),
+		})
+	}
+	if message.Timestamp != "" {
+		elements = append(elements, map[string]interface{}{
+			"tag": "note",
+			"elements": []map[string]interface{}{
+				{"tag": "plain_text", "content": "时间: " + message.Timestamp},
+			},
+		})
+	}
+
+	actions := []map[string]interface{}{}
+	for _, action := range buttons(message) {
+		buttonType := action.Style
+		if buttonType != ActionStylePrimary && buttonType != ActionStyleDanger {
+			buttonType = ActionStyleDefault
 		}
+		actions = append(actions, map[string]interface{}{
+			"tag":  "button",
+			"text": map[string]interface{}{"tag": "plain_text", "content": action.Label},
+			"url":  action.URL,
+			"type": buttonType,
+		})
+	}
+	if len(actions) > 0 {
+		elements = append(elements, map[string]interface{}{
+			"tag":     "action",
+			"actions": actions,
+		})
+	}
+
+	card := map[string]interface{}{
+		"config": map[string]interface{}{"wide_screen_mode": true},
+		"header": map[string]interface{}{
+			"title":    map[string]interface{}{"tag": "plain_text", "content": message.Title},
+			"template": cardTemplate(message.Severity),
+		},
+		"elements": elements,
+	}
+	contentBytes, _ := json.Marshal(card)
+	return string(contentBytes)
+}
+
+// feishuMentionIDs 返回消息中需要提及的用户 ID（open_id 或 user_id），所有人为 all
+func feishuMentionIDs(message *NotificationMessage) []string {
+	mentions := splitMentions(message.Mentions)
+	ids := append([]string(nil), mentions.Users...)
+	if mentions.All {
+		ids = append(ids, "all")
+	}
+	return ids
+}
+
+// cardTemplate 返回消息级别对应的卡片标题颜色
+func cardTemplate(severity string) string {
+	switch severity {
+	case SeverityCritical:
+		return "carmine"
+	case SeverityError:
+		return "red"
+	case SeverityWarning:
+		return "orange"
+	case SeverityNotice:
+		return "wathet"
+	}
+	return "blue"
+}
+
+// buildRichTextElements 构建富文本元素
+func (f *FeishuNotifier) buildRichTextElements(message *NotificationMessage, imageKeys []string) [][]map[string]interface{} {
+	elements := [][]map[string]interface{}{}
+	for _, imageKey := range imageKeys {
 		elements = append(elements, []map[string]interface{}{
 			{
 				"tag":       "img",
@@ -191,6 +361,18 @@ func (f *FeishuNotifier) buildRichTextElements(message *NotificationMessage) [][
 		elements = append(elements, content)
 	}
 
+	// 添加提及，飞书不支持通过手机号提及
+	if ids := feishuMentionIDs(message); len(ids) > 0 {
+		atElement := []map[string]interface{}{}
+		for _, id := range ids {
+			atElement = append(atElement, map[string]interface{}{
+				"tag":     "at",
+				"user_id": id,
+			})
+		}
+		elements = append(elements, atElement)
+	}
+
 	// 添加时间戳
 	if message.Timestamp != "" {
 		timeElement := []map[string]interface{}{
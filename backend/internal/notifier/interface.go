@@ -4,11 +4,28 @@ import "context"
 
 // NotificationMessage 通知消息结构
 type NotificationMessage struct {
+	ID        string `json:"id,omitempty"` // 消息 ID，记录到消息历史后生成
 	Title     string `json:"title"`
 	Content   string `json:"content"`
 	Timestamp string `json:"timestamp"`
-	Image     string `json:"image"` // 图片URL或路径
-	URL       string `json:"url"`   // 点击跳转的URL
+	Image     string `json:"image"`              // 图片URL或路径
+	URL       string `json:"url"`                // 点击跳转的URL
+	Severity  string `json:"severity,omitempty"` // 级别：info、notice、warning、error、critical，未指定时为空
+	Priority  int    `json:"priority,omitempty"` // 优先级 1-5，未指定时按级别推导
+	Silent    bool   `json:"silent,omitempty"`   // 静默推送，免打扰期间由通知应用设置
+
+	Attachments []Attachment `json:"attachments,omitempty"` // 附件，通知服务优先通过原生接口上传
+	Actions     []Action     `json:"actions,omitempty"`     // 按钮，不支持按钮的通知服务显示为链接
+	Mentions    []string     `json:"mentions,omitempty"`    // 提及的用户 ID、用户名、手机号或 @all，与发送目标无关
+}
+
+// Clone 复制消息，附件、按钮和提及列表单独复制
+func (m *NotificationMessage) Clone() *NotificationMessage {
+	clone := *m
+	clone.Attachments = append([]Attachment(nil), m.Attachments...)
+	clone.Actions = append([]Action(nil), m.Actions...)
+	clone.Mentions = append([]string(nil), m.Mentions...)
+	return &clone
 }
 
 // Notifier 通知服务接口
@@ -0,0 +1,40 @@
+package notifier
+
+import (
+	"strings"
+
+	"notify/internal/utils"
+)
+
+// MentionAll 提及所有人
+const MentionAll = "@all"
+
+// mentionSet 按类型拆分后的提及对象
+type mentionSet struct {
+	All     bool     // 提及所有人
+	Users   []string // 用户 ID 或用户名，已去掉开头的 @
+	Mobiles []string // 手机号
+}
+
+// splitMentions 拆分提及列表，@all 和 all 表示所有人，手机号单独列出，其他作为用户 ID
+func splitMentions(mentions []string) mentionSet {
+	var set mentionSet
+	for _, mention := range mentions {
+		mention = strings.TrimSpace(mention)
+		switch {
+		case mention == "":
+		case strings.EqualFold(mention, MentionAll) || strings.EqualFold(mention, "all"):
+			set.All = true
+		case utils.IsMobilePhone(mention):
+			set.Mobiles = append(set.Mobiles, mention)
+		default:
+			set.Users = append(set.Users, strings.TrimPrefix(mention, "@"))
+		}
+	}
+	return set
+}
+
+// IsEmpty 判断是否没有任何提及对象
+func (s mentionSet) IsEmpty() bool {
+	return !s.All && len(s.Users) == 0 && len(s.Mobiles) == 0
+}
@@ -0,0 +1,95 @@
+package notifier
+
+import (
+	"strconv"
+	"strings"
+)
+
+// 消息级别，从低到高
+const (
+	SeverityInfo     = "info"
+	SeverityNotice   = "notice"
+	SeverityWarning  = "warning"
+	SeverityError    = "error"
+	SeverityCritical = "critical"
+)
+
+// 消息优先级，取值与 ntfy 一致
+const (
+	PriorityMin     = 1
+	PriorityLow     = 2
+	PriorityDefault = 3
+	PriorityHigh    = 4
+	PriorityUrgent  = 5
+)
+
+// SeverityRank 返回级别的排序权重，未知级别返回 0
+func SeverityRank(severity string) int {
+	switch severity {
+	case SeverityCritical:
+		return 5
+	case SeverityError:
+		return 4
+	case SeverityWarning:
+		return 3
+	case SeverityNotice:
+		return 2
+	case SeverityInfo:
+		return 1
+	}
+	return 0
+}
+
+// IsSeverity 判断是否为有效的级别名称
+func IsSeverity(severity string) bool {
+	return SeverityRank(severity) > 0
+}
+
+// SeverityPriority 返回级别对应的默认优先级，未指定级别时返回 0
+func SeverityPriority(severity string) int {
+	switch severity {
+	case SeverityCritical:
+		return PriorityUrgent
+	case SeverityError, SeverityWarning:
+		return PriorityHigh
+	case SeverityNotice:
+		return PriorityDefault
+	case SeverityInfo:
+		return PriorityLow
+	}
+	return 0
+}
+
+// ParsePriority 解析优先级，支持 1-5 的数字和 min、low、default、high、urgent 等名称，无法识别时返回 0
+func ParsePriority(priority string) int {
+	priority = strings.ToLower(strings.TrimSpace(priority))
+	if n, err := strconv.Atoi(priority); err == nil {
+		if n < PriorityMin || n > PriorityUrgent {
+			return 0
+		}
+		return n
+	}
+	switch priority {
+	case "min", "lowest":
+		return PriorityMin
+	case "low":
+		return PriorityLow
+	case "default", "normal", "medium":
+		return PriorityDefault
+	case "high":
+		return PriorityHigh
+	case "urgent", "max", "highest":
+		return PriorityUrgent
+	}
+	return 0
+}
+
+// IsLowPriority 判断消息是否为低优先级，低优先级消息在支持的渠道上静默推送
+func (m *NotificationMessage) IsLowPriority() bool {
+	return m.Priority > 0 && m.Priority <= PriorityLow
+}
+
+// IsSilent 判断消息是否需要静默推送，免打扰期间的消息和低优先级消息在支持的渠道上不发出提醒
+func (m *NotificationMessage) IsSilent() bool {
+	return m.Silent || m.IsLowPriority()
+}
@@ -1,8 +1,11 @@
 package notifier
 
 import (
+	"bytes"
 	"context"
 	"fmt"
+	"strconv"
+	"strings"
 	"time"
 
 	"notify/internal/config"
@@ -78,7 +81,6 @@ func (t *TelegramNotifier) Send(ctx context.Context, message *NotificationMessag
 		users = targets
 	}
 	for _, user := range users {
-		// 使用message.Targets作为@提及的用户名列表
 		if message.Image != "" {
 			err := t.sendPhotoMessage(ctx, user, message)
 			if err != nil {
@@ -90,6 +92,12 @@ func (t *TelegramNotifier) Send(ctx context.Context, message *NotificationMessag
 				return fmt.Errorf("发送文本消息失败: %w", err)
 			}
 		}
+
+		for i := range message.Attachments {
+			if err := t.sendAttachment(ctx, user, &message.Attachments[i], message.IsSilent()); err != nil {
+				return fmt.Errorf("发送附件 %s 失败: %w", message.Attachments[i].FileName(), err)
+			}
+		}
 	}
 
 	return nil
@@ -98,6 +106,9 @@ func (t *TelegramNotifier) Send(ctx context.Context, message *NotificationMessag
 // sendTextMessage 发送文本消息
 func (t *TelegramNotifier) sendTextMessage(ctx context.Context, chatID string, message *NotificationMessage) error {
 	content := fmt.Sprintf("*%s*\n\n%s", message.Title, message.Content)
+	if mentions := t.mentionText(message); mentions != "" {
+		content += "\n\n" + mentions
+	}
 
 	apiURL := fmt.Sprintf("https://api.telegram.org/bot%s/sendMessage", t.config.BotToken)
 	// TODO: 消息超过4096字符的处理
@@ -107,18 +118,14 @@ func (t *TelegramNotifier) sendTextMessage(ctx context.Context, chatID string, m
 		"parse_mode": "Markdown",
 	}
 
-	// 如果有URL，添加inline keyboard按钮
-	if message.URL != "" {
-		requestBody["reply_markup"] = map[string]interface{}{
-			"inline_keyboard": [][]map[string]interface{}{
-				{
-					{
-						"text": "🔗 查看详情",
-						"url":  message.URL,
-					},
-				},
-			},
-		}
+	// 低优先级和免打扰期间的消息静默推送
+	if message.IsSilent() {
+		requestBody["disable_notification"] = true
+	}
+
+	// 如果有URL或按钮，添加inline keyboard按钮
+	if markup := t.replyMarkup(message); markup != nil {
+		requestBody["reply_markup"] = markup
 	}
 
 	return t.sendRequest(ctx, apiURL, requestBody)
@@ -127,6 +134,9 @@ func (t *TelegramNotifier) sendTextMessage(ctx context.Context, chatID string, m
 // sendPhotoMessage 发送图片消息
 func (t *TelegramNotifier) sendPhotoMessage(ctx context.Context, chatID string, message *NotificationMessage) error {
 	caption := fmt.Sprintf("*%s*\n\n%s", message.Title, message.Content)
+	if mentions := t.mentionText(message); mentions != "" {
+		caption += "\n\n" + mentions
+	}
 	apiURL := fmt.Sprintf("https://api.telegram.org/bot%s/sendPhoto", t.config.BotToken)
 
 	requestBody := map[string]interface{}{
@@ -136,23 +146,99 @@ func (t *TelegramNotifier) sendPhotoMessage(ctx context.Context, chatID string,
 		"parse_mode": "Markdown",
 	}
 
-	// 如果有URL，添加inline keyboard按钮
-	if message.URL != "" {
-		requestBody["reply_markup"] = map[string]interface{}{
-			"inline_keyboard": [][]map[string]interface{}{
-				{
-					{
-						"text": "🔗 查看详情",
-						"url":  message.URL,
-					},
-				},
-			},
-		}
+	// 低优先级和免打扰期间的消息静默推送
+	if message.IsSilent() {
+		requestBody["disable_notification"] = true
+	}
+
+	// 如果有URL或按钮，添加inline keyboard按钮
+	if markup := t.replyMarkup(message); markup != nil {
+		requestBody["reply_markup"] = markup
 	}
 
 	return t.sendRequest(ctx, apiURL, requestBody)
 }
 
+// mentionText 生成提及文本，数字 ID 使用 tg://user?id= 链接，其他按用户名 @ 提及
+// Telegram 不支持提及所有人和手机号，@all 和手机号会被忽略
+func (t *TelegramNotifier) mentionText(message *NotificationMessage) string {
+	var parts []string
+	for _, user := range splitMentions(message.Mentions).Users {
+		if _, err := strconv.ParseInt(user, 10, 64); err == nil {
+			parts = append(parts, fmt.Sprintf("[@%s](tg://user?id=%s)", user, user))
+		} else {
+			parts = append(parts, "@"+strings.ReplaceAll(user, "_", "\\_"))
+		}
+	}
+	return strings.Join(parts, " ")
+}
+
+// replyMarkup 把消息链接和按钮转换为 inline keyboard，每行最多两个按钮
+func (t *TelegramNotifier) replyMarkup(message *NotificationMessage) map[string]interface{} {
+	actions := buttons(message)
+	if len(actions) == 0 {
+		return nil
+	}
+
+	var rows [][]map[string]interface{}
+	for i, action := range actions {
+		if i%2 == 0 {
+			rows = append(rows, []map[string]interface{}{})
+		}
+		rows[len(rows)-1] = append(rows[len(rows)-1], map[string]interface{}{
+			"text": action.Label,
+			"url":  action.URL,
+		})
+	}
+	return map[string]interface{}{
+		"inline_keyboard": rows,
+	}
+}
+
+// sendAttachment 发送附件，图片使用 sendPhoto，其他文件使用 sendDocument
+// 只有 URL 的附件由 Telegram 服务器下载，有内容的附件通过 multipart 上传，silent 为 true 时静默推送
+func (t *TelegramNotifier) sendAttachment(ctx context.Context, chatID string, attachment *Attachment, silent bool) error {
+	method, field := "sendDocument", "document"
+	if attachment.IsImage() {
+		method, field = "sendPhoto", "photo"
+	}
+	apiURL := fmt.Sprintf("https://api.telegram.org/bot%s/%s", t.config.BotToken, method)
+
+	if attachment.Data == nil && attachment.URL != "" {
+		return t.sendRequest(ctx, apiURL, map[string]interface{}{
+			"chat_id":              chatID,
+			field:                  attachment.URL,
+			"disable_notification": silent,
+		})
+	}
+
+	data, err := attachment.Load(ctx)
+	if err != nil {
+		return err
+	}
+
+	var result TelegramResponse
+	resp, err := t.client.R().
+		SetContext(ctx).
+		SetFormData(map[string]string{
+			"chat_id":              chatID,
+			"disable_notification": strconv.FormatBool(silent),
+		}).
+		SetFileReader(field, attachment.FileName(), bytes.NewReader(data)).
+		SetResult(&result).
+		Post(apiURL)
+	if err != nil {
+		return fmt.Errorf("发送请求失败: %w", err)
+	}
+	if !result.OK {
+		return fmt.Errorf("发送消息失败: %s (错误代码: %d)", result.Description, result.ErrorCode)
+	}
+	if !resp.IsSuccess() {
+		return fmt.Errorf("HTTP请求失败，状态码: %d", resp.StatusCode())
+	}
+	return nil
+}
+
 // sendRequest 发送HTTP请求
 func (t *TelegramNotifier) sendRequest(ctx context.Context, apiURL string, requestBody map[string]interface{}) error {
 	var result TelegramResponse
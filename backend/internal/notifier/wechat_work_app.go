@@ -1,6 +1,7 @@
 package notifier
 
 import (
+	"bytes"
 	"context"
 	"fmt"
 	"strings"
@@ -125,11 +126,88 @@ func (w *WechatWorkNotifier) Send(ctx context.Context, message *NotificationMess
 	}
 	// TODO: 消息超过2048字符的处理
 	// 如果有图片，发送图文消息，否则发送文本消息
-	if message.Image != "" {
-		return w.sendNewsMessage(ctx, message, targets)
+	// 有按钮时发送模板卡片
+	var err error
+	if len(message.Actions) > 0 {
+		err = w.sendTemplateCardMessage(ctx, message, targets)
+	} else if message.Image != "" {
+		err = w.sendNewsMessage(ctx, message, targets)
+	} else {
+		err = w.sendTextMessage(ctx, message, targets)
+	}
+	if err != nil {
+		return err
+	}
+
+	for i := range message.Attachments {
+		if err := w.sendAttachment(ctx, &message.Attachments[i], targets); err != nil {
+			return fmt.Errorf("发送附件 %s 失败: %w", message.Attachments[i].FileName(), err)
+		}
+	}
+	return nil
+}
+
+// MediaUploadResponse 临时素材上传响应结构
+type MediaUploadResponse struct {
+	ErrCode int    `json:"errcode"`
+	ErrMsg  string `json:"errmsg"`
+	Type    string `json:"type"`
+	MediaID string `json:"media_id"`
+}
+
+// uploadMedia 上传临时素材，返回 media_id
+func (w *WechatWorkNotifier) uploadMedia(ctx context.Context, attachment *Attachment, mediaType string) (string, error) {
+	data, err := attachment.Load(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	var result MediaUploadResponse
+	resp, err := w.client.R().
+		SetContext(ctx).
+		SetQueryParams(map[string]string{
+			"access_token": w.accessToken,
+			"type":         mediaType,
+		}).
+		SetFileReader("media", attachment.FileName(), bytes.NewReader(data)).
+		SetResult(&result).
+		Post(fmt.Sprintf("%s/cgi-bin/media/upload", w.baseURL))
+	if err != nil {
+		return "", fmt.Errorf("上传素材失败: %w", err)
+	}
+	if !resp.IsSuccess() {
+		return "", fmt.Errorf("HTTP请求失败，状态码: %d", resp.StatusCode())
+	}
+	if result.ErrCode != 0 {
+		return "", fmt.Errorf("上传素材失败: %s", result.ErrMsg)
+	}
+	return result.MediaID, nil
+}
+
+// sendAttachment 上传附件后发送图片或文件消息
+func (w *WechatWorkNotifier) sendAttachment(ctx context.Context, attachment *Attachment, targets []string) error {
+	mediaType := "file"
+	if attachment.IsImage() {
+		mediaType = "image"
+	}
+	mediaID, err := w.uploadMedia(ctx, attachment, mediaType)
+	if err != nil {
+		return err
 	}
 
-	return w.sendTextMessage(ctx, message, targets)
+	requestBody := map[string]interface{}{
+		"touser":  "@all",
+		"msgtype": mediaType,
+		"agentid": w.config.AgentID,
+		mediaType: map[string]string{
+			"media_id": mediaID,
+		},
+	}
+	if len(targets) > 0 {
+		requestBody["touser"] = joinStrings(targets, "|")
+	}
+
+	return w.sendMessage(ctx, requestBody)
 }
 
 // sendTextMessage 发送文本消息
@@ -184,6 +262,21 @@ func (w *WechatWorkNotifier) sendNewsMessage(ctx context.Context, message *Notif
 	return w.sendMessage(ctx, requestBody)
 }
 
+// sendTemplateCardMessage 发送带跳转列表的模板卡片消息
+func (w *WechatWorkNotifier) sendTemplateCardMessage(ctx context.Context, message *NotificationMessage, targets []string) error {
+	requestBody := map[string]interface{}{
+		"touser":        "@all",
+		"msgtype":       "template_card",
+		"agentid":       w.config.AgentID,
+		"template_card": wechatTemplateCard(message),
+	}
+	if len(targets) > 0 {
+		requestBody["touser"] = joinStrings(targets, "|")
+	}
+
+	return w.sendMessage(ctx, requestBody)
+}
+
 // sendMessage 发送消息到企业微信
 func (w *WechatWorkNotifier) sendMessage(ctx context.Context, requestBody map[string]interface{}) error {
 	var result MessageResponse
@@ -211,6 +304,40 @@ func (w *WechatWorkNotifier) sendMessage(ctx context.Context, requestBody map[st
 	return nil
 }
 
+// wechatTemplateCard 构建企业微信文本通知模板卡片，应用消息和群机器人通用
+// 跳转列表最多 3 项，多出的按钮以文本链接附在内容后
+func wechatTemplateCard(message *NotificationMessage) map[string]interface{} {
+	actions := buttons(message)
+	content := message.Content
+
+	jumpList := []map[string]interface{}{}
+	for i, action := range actions {
+		if i >= 3 {
+			content += fmt.Sprintf("\n%s: %s", action.Label, action.URL)
+			continue
+		}
+		jumpList = append(jumpList, map[string]interface{}{
+			"type":  1,
+			"title": action.Label,
+			"url":   action.URL,
+		})
+	}
+
+	return map[string]interface{}{
+		"card_type": "text_notice",
+		"main_title": map[string]interface{}{
+			"title": message.Title,
+			"desc":  message.Timestamp,
+		},
+		"sub_title_text": content,
+		"jump_list":      jumpList,
+		"card_action": map[string]interface{}{
+			"type": 1,
+			"url":  actions[0].URL,
+		},
+	}
+}
+
 // joinStrings 连接字符串切片
 func joinStrings(strs []string, sep string) string {
 	if len(strs) == 0 {
@@ -1,9 +1,14 @@
 package notifier
 
 import (
+	"bytes"
 	"context"
+	"crypto/md5"
+	"encoding/base64"
+	"encoding/hex"
 	"encoding/json"
 	"fmt"
+	"net/http"
 	"strings"
 	"time"
 
@@ -68,10 +73,149 @@ func (w *WechatWorkWebhookNotifier) Send(ctx context.Context, message *Notificat
 	}
 
 	// 发送消息
-	if message.Image != "" {
-		return w.SendNewsdownMessage(ctx, message)
+	var err error
+	if len(message.Actions) > 0 {
+		err = w.sendTemplateCardMessage(ctx, message)
+	} else if message.Image != "" {
+		err = w.SendNewsdownMessage(ctx, message)
+	} else {
+		err = w.sendMarkdownMessage(ctx, message)
+	}
+	if err != nil {
+		return err
+	}
+
+	// Markdown、图文和模板卡片消息都不支持提及，需要单独发送一条文本消息
+	if mentions := splitMentions(message.Mentions); !mentions.IsEmpty() {
+		if err := w.sendMentionMessage(ctx, message, mentions); err != nil {
+			return err
+		}
+	}
+
+	for i := range message.Attachments {
+		if err := w.sendAttachment(ctx, &message.Attachments[i]); err != nil {
+			return fmt.Errorf("发送附件 %s 失败: %w", message.Attachments[i].FileName(), err)
+		}
+	}
+	return nil
+}
+
+// sendTemplateCardMessage 发送带跳转列表的模板卡片消息
+func (w *WechatWorkWebhookNotifier) sendTemplateCardMessage(ctx context.Context, message *NotificationMessage) error {
+	webhookURL := fmt.Sprintf("%s/cgi-bin/webhook/send?key=%s", w.baseURL, w.config.Key)
+	payload := map[string]interface{}{
+		"msgtype":       "template_card",
+		"template_card": wechatTemplateCard(message),
+	}
+
+	resp, err := w.client.R().
+		SetContext(ctx).
+		SetHeader("Content-Type", "application/json").
+		SetBody(payload).
+		Post(webhookURL)
+	if err != nil {
+		return fmt.Errorf("发送企业微信群机器人消息失败: %w", err)
+	}
+	return w.checkResp(resp)
+}
+
+// sendMentionMessage 发送提及用户的文本消息，内容为消息标题
+func (w *WechatWorkWebhookNotifier) sendMentionMessage(ctx context.Context, message *NotificationMessage, mentions mentionSet) error {
+	users := append([]string(nil), mentions.Users...)
+	mobiles := append([]string(nil), mentions.Mobiles...)
+	if mentions.All {
+		users = append(users, MentionAll)
+	}
+	content := message.Title
+	if content == "" {
+		content = message.Content
+	}
+
+	webhookURL := fmt.Sprintf("%s/cgi-bin/webhook/send?key=%s", w.baseURL, w.config.Key)
+	payload := map[string]interface{}{
+		"msgtype": "text",
+		"text": map[string]interface{}{
+			"content":               content,
+			"mentioned_list":        users,
+			"mentioned_mobile_list": mobiles,
+		},
+	}
+
+	resp, err := w.client.R().
+		SetContext(ctx).
+		SetHeader("Content-Type", "application/json").
+		SetBody(payload).
+		Post(webhookURL)
+	if err != nil {
+		return fmt.Errorf("发送企业微信群机器人消息失败: %w", err)
+	}
+	return w.checkResp(resp)
+}
+
+// sendAttachment 发送附件，2MB 以内的 JPG/PNG 图片发送图片消息，其他上传后发送文件消息
+func (w *WechatWorkWebhookNotifier) sendAttachment(ctx context.Context, attachment *Attachment) error {
+	data, err := attachment.Load(ctx)
+	if err != nil {
+		return err
+	}
+
+	var payload map[string]interface{}
+	contentType := http.DetectContentType(data)
+	if len(data) <= 2<<20 && (contentType == "image/png" || contentType == "image/jpeg") {
+		sum := md5.Sum(data)
+		payload = map[string]interface{}{
+			"msgtype": "image",
+			"image": map[string]interface{}{
+				"base64": base64.StdEncoding.EncodeToString(data),
+				"md5":    hex.EncodeToString(sum[:]),
+			},
+		}
+	} else {
+		mediaID, err := w.uploadMedia(ctx, attachment, data)
+		if err != nil {
+			return err
+		}
+		payload = map[string]interface{}{
+			"msgtype": "file",
+			"file": map[string]interface{}{
+				"media_id": mediaID,
+			},
+		}
+	}
+
+	webhookURL := fmt.Sprintf("%s/cgi-bin/webhook/send?key=%s", w.baseURL, w.config.Key)
+	resp, err := w.client.R().
+		SetContext(ctx).
+		SetHeader("Content-Type", "application/json").
+		SetBody(payload).
+		Post(webhookURL)
+	if err != nil {
+		return fmt.Errorf("发送企业微信群机器人消息失败: %w", err)
+	}
+	return w.checkResp(resp)
+}
+
+// uploadMedia 通过群机器人接口上传文件，返回 media_id
+func (w *WechatWorkWebhookNotifier) uploadMedia(ctx context.Context, attachment *Attachment, data []byte) (string, error) {
+	uploadURL := fmt.Sprintf("%s/cgi-bin/webhook/upload_media?key=%s&type=file", w.baseURL, w.config.Key)
+	resp, err := w.client.R().
+		SetContext(ctx).
+		SetFileReader("media", attachment.FileName(), bytes.NewReader(data)).
+		Post(uploadURL)
+	if err != nil {
+		return "", fmt.Errorf("上传文件失败: %w", err)
+	}
+	if err := w.checkResp(resp); err != nil {
+		return "", err
+	}
+
+	var result struct {
+		MediaID string `json:"media_id"`
+	}
+	if err := json.Unmarshal(resp.Body(), &result); err != nil {
+		return "", fmt.Errorf("解析上传文件响应失败: %w", err)
 	}
-	return w.sendMarkdownMessage(ctx, message)
+	return result.MediaID, nil
 }
 
 // buildMessage 构建消息内容
@@ -2,9 +2,15 @@ package server
 
 import (
 	"fmt"
+	"io"
 	"net/http"
+	"strconv"
 
+	"notify/internal/app"
 	"notify/internal/config"
+	"notify/internal/logger"
+	"notify/internal/notifier"
+	"notify/internal/source"
 
 	"github.com/gin-gonic/gin"
 )
@@ -32,6 +38,36 @@ func (s *HTTPServer) setupAdminRoutes(api *gin.RouterGroup) {
 
 		// 通知服务管理
 		s.setupNotifierManagementRoutes(admin)
+
+		// 联系人管理 (定义在 contact_routes.go)
+		s.setupContactRoutes(admin)
+
+		// 值班表、升级策略和升级管理 (定义在 escalation_routes.go)
+		s.setupEscalationRoutes(admin)
+
+		// 消息历史和确认状态 (定义在 message_routes.go)
+		s.setupMessageRoutes(admin)
+
+		// 摘要缓存查看和立即发送 (定义在 digest_routes.go)
+		s.setupDigestRoutes(admin)
+
+		// 免打扰策略和暂存消息 (定义在 quiet_hours_routes.go)
+		s.setupQuietHoursRoutes(admin)
+
+		// 定时通知和定时任务 (定义在 schedule_routes.go)
+		s.setupScheduleRoutes(admin)
+
+		// 心跳监控 (定义在 heartbeat_routes.go)
+		s.setupHeartbeatRoutes(admin)
+
+		// 入站数据源适配器
+		admin.GET("/sources", s.handleGetSources)
+
+		// 监控指标 (定义在 metrics_routes.go)
+		admin.GET("/metrics", s.handleMetrics)
+
+		// 上传媒体文件 (定义在 media_routes.go)
+		admin.POST("/media", s.handleUploadMedia)
 	}
 }
 
@@ -44,6 +80,8 @@ func (s *HTTPServer) setupAppManagementRoutes(admin *gin.RouterGroup) {
 		apps.GET("/:appid", s.handleGetAppConfig)    // 获取单个应用配置
 		apps.PUT("/:appid", s.handleUpdateAppConfig) // 更新应用配置
 		apps.DELETE("/:appid", s.handleDeleteApp)    // 删除应用
+
+		apps.POST("/:appid/rules/test", s.handleTestAppRules) // 路由规则试运行
 	}
 }
 
@@ -67,6 +105,12 @@ func (s *HTTPServer) setupTemplateManagementRoutes(admin *gin.RouterGroup) {
 		templates.POST("", s.handleCreateTemplate)               // 创建模板
 		templates.PUT("/:templateId", s.handleUpdateTemplate)    // 更新模板
 		templates.DELETE("/:templateId", s.handleDeleteTemplate) // 删除模板
+
+		// 模板版本历史
+		templates.GET("/:templateId/versions", s.handleGetTemplateVersions)         // 获取模板历史版本
+		templates.GET("/:templateId/versions/:version", s.handleGetTemplateVersion) // 获取模板指定版本
+		templates.GET("/:templateId/diff", s.handleDiffTemplateVersions)            // 对比两个版本
+		templates.POST("/:templateId/rollback", s.handleRollbackTemplate)           // 回滚到指定版本
 	}
 }
 
@@ -102,6 +146,47 @@ func (s *HTTPServer) handleUpdateAppConfig(c *gin.Context) {
 	// 确保 AppID 与路径参数一致
 	updateReq.AppID = appID
 
+	if err := s.validateTemplatePin(updateReq); err != nil {
+		c.JSON(http.StatusOK, NewErrorRes(TEMPLATE_VERSION_ERROR, err.Error()))
+		return
+	}
+	if err := s.validateAppContacts(updateReq); err != nil {
+		c.JSON(http.StatusBadRequest, NewErrorRes(CONTACT_DANGLING_REFS, err.Error()))
+		return
+	}
+	if _, exists := s.config.EscalationPolicies[updateReq.EscalationPolicy]; updateReq.EscalationPolicy != "" && !exists {
+		c.JSON(http.StatusBadRequest, NewErrorRes(ESCALATION_NOT_FOUND, fmt.Sprintf("升级策略 %s 不存在", updateReq.EscalationPolicy)))
+		return
+	}
+	if err := updateReq.ValidateAggregation(); err != nil {
+		c.JSON(http.StatusBadRequest, NewErrorRes(PARAM_ERROR, err.Error()))
+		return
+	}
+	if err := updateReq.ValidateRateLimit(); err != nil {
+		c.JSON(http.StatusBadRequest, NewErrorRes(PARAM_ERROR, err.Error()))
+		return
+	}
+	if err := s.app.ValidateRules(updateReq); err != nil {
+		c.JSON(http.StatusBadRequest, NewErrorRes(PARAM_ERROR, err.Error()))
+		return
+	}
+	if err := s.config.ValidateDigest(updateReq); err != nil {
+		c.JSON(http.StatusBadRequest, NewErrorRes(PARAM_ERROR, err.Error()))
+		return
+	}
+	if err := s.config.ValidateQuietHoursRef(updateReq.QuietHours); err != nil {
+		c.JSON(http.StatusBadRequest, NewErrorRes(QUIET_HOURS_NOT_FOUND, err.Error()))
+		return
+	}
+	if err := s.config.ValidateNotifierGroups(updateReq); err != nil {
+		c.JSON(http.StatusBadRequest, NewErrorRes(PARAM_ERROR, err.Error()))
+		return
+	}
+	if updateReq.MinSeverity != "" && !notifier.IsSeverity(updateReq.MinSeverity) {
+		c.JSON(http.StatusBadRequest, NewErrorRes(PARAM_ERROR, fmt.Sprintf("无效的最低级别 %s，可选 info、notice、warning、error、critical", updateReq.MinSeverity)))
+		return
+	}
+
 	// 直接使用 updateReq 参数更新应用配置
 	if err := s.configManager.UpdateAppConfig(updateReq); err != nil {
 		c.JSON(http.StatusOK, NewErrorRes(APP_CONFIG_ERROR, "更新应用配置失败"))
@@ -135,6 +220,47 @@ func (s *HTTPServer) handleCreateApp(c *gin.Context) {
 		return
 	}
 
+	if err := s.validateTemplatePin(createReq); err != nil {
+		c.JSON(http.StatusOK, NewErrorRes(TEMPLATE_VERSION_ERROR, err.Error()))
+		return
+	}
+	if err := s.validateAppContacts(createReq); err != nil {
+		c.JSON(http.StatusBadRequest, NewErrorRes(CONTACT_DANGLING_REFS, err.Error()))
+		return
+	}
+	if _, exists := s.config.EscalationPolicies[createReq.EscalationPolicy]; createReq.EscalationPolicy != "" && !exists {
+		c.JSON(http.StatusBadRequest, NewErrorRes(ESCALATION_NOT_FOUND, fmt.Sprintf("升级策略 %s 不存在", createReq.EscalationPolicy)))
+		return
+	}
+	if err := createReq.ValidateAggregation(); err != nil {
+		c.JSON(http.StatusBadRequest, NewErrorRes(PARAM_ERROR, err.Error()))
+		return
+	}
+	if err := createReq.ValidateRateLimit(); err != nil {
+		c.JSON(http.StatusBadRequest, NewErrorRes(PARAM_ERROR, err.Error()))
+		return
+	}
+	if err := s.app.ValidateRules(createReq); err != nil {
+		c.JSON(http.StatusBadRequest, NewErrorRes(PARAM_ERROR, err.Error()))
+		return
+	}
+	if err := s.config.ValidateDigest(createReq); err != nil {
+		c.JSON(http.StatusBadRequest, NewErrorRes(PARAM_ERROR, err.Error()))
+		return
+	}
+	if err := s.config.ValidateQuietHoursRef(createReq.QuietHours); err != nil {
+		c.JSON(http.StatusBadRequest, NewErrorRes(QUIET_HOURS_NOT_FOUND, err.Error()))
+		return
+	}
+	if err := s.config.ValidateNotifierGroups(createReq); err != nil {
+		c.JSON(http.StatusBadRequest, NewErrorRes(PARAM_ERROR, err.Error()))
+		return
+	}
+	if createReq.MinSeverity != "" && !notifier.IsSeverity(createReq.MinSeverity) {
+		c.JSON(http.StatusBadRequest, NewErrorRes(PARAM_ERROR, fmt.Sprintf("无效的最低级别 %s，可选 info、notice、warning、error、critical", createReq.MinSeverity)))
+		return
+	}
+
 	// 使用ConfigManager创建应用，直接使用 AppID 作为 map key
 	if err := s.configManager.CreateApp(createReq.AppID, createReq); err != nil {
 		if err.Error() == fmt.Sprintf("应用 %s 已存在", createReq.AppID) {
@@ -151,6 +277,27 @@ func (s *HTTPServer) handleCreateApp(c *gin.Context) {
 	c.JSON(http.StatusCreated, NewSuccessRes(createReq))
 }
 
+// validateAppContacts 检查路由规则的发送目标是否引用了不存在的联系人
+func (s *HTTPServer) validateAppContacts(appConfig config.NotificationApp) error {
+	var lists []string
+	for _, rule := range appConfig.Rules {
+		lists = append(lists, rule.Targets)
+	}
+	if missing := s.config.MissingContactRefs(lists...); len(missing) > 0 {
+		return fmt.Errorf("引用了不存在的联系人: %v", missing)
+	}
+	return nil
+}
+
+// validateTemplatePin 检查应用固定的模板版本是否存在
+func (s *HTTPServer) validateTemplatePin(appConfig config.NotificationApp) error {
+	if appConfig.TemplateVersion <= 0 {
+		return nil
+	}
+	_, err := s.app.TemplateHistory().Get(appConfig.TemplateID, appConfig.TemplateVersion)
+	return err
+}
+
 // handleDeleteApp 删除应用
 func (s *HTTPServer) handleDeleteApp(c *gin.Context) {
 	appID := c.Param("appid")
@@ -162,6 +309,16 @@ func (s *HTTPServer) handleDeleteApp(c *gin.Context) {
 		return
 	}
 
+	if jobs := s.configManager.GetJobsUsingApp(appID); len(jobs) > 0 {
+		c.JSON(http.StatusOK, NewErrorRes(CONFLICT_ERROR, fmt.Sprintf("应用 %s 正在被以下定时任务使用，不能删除: %v", appID, jobs)))
+		return
+	}
+
+	if heartbeats := s.configManager.GetHeartbeatsUsingApp(appID); len(heartbeats) > 0 {
+		c.JSON(http.StatusOK, NewErrorRes(CONFLICT_ERROR, fmt.Sprintf("应用 %s 正在被以下心跳监控使用，不能删除: %v", appID, heartbeats)))
+		return
+	}
+
 	// 使用ConfigManager删除应用（使用 mapKey）
 	if err := s.configManager.DeleteApp(mapKey); err != nil {
 		c.JSON(http.StatusOK, NewErrorRes(APP_CONFIG_ERROR, "删除应用失败"))
@@ -174,6 +331,44 @@ func (s *HTTPServer) handleDeleteApp(c *gin.Context) {
 	c.JSON(http.StatusOK, NewSuccessRes(fmt.Sprintf("应用 %s 删除成功", appID)))
 }
 
+// handleTestAppRules 使用示例数据试运行应用的路由规则，返回命中的规则和渲染结果，不会真正发送
+// 应用配置了数据源（或通过 ?source=、?_source= 指定）时先经过数据源适配，每条通知数据对应一个结果
+func (s *HTTPServer) handleTestAppRules(c *gin.Context) {
+	appID := c.Param("appid")
+
+	appConfig, _, found := s.findAppByID(appID)
+	if !found {
+		c.JSON(http.StatusOK, NewErrorRes(APP_NOT_FOUND, fmt.Sprintf("应用 %s 不存在", appID)))
+		return
+	}
+
+	body, _ := io.ReadAll(c.Request.Body)
+	sample, err := s.parseRequestBody(c, c.Request.Header.Get("Content-Type"), body, appConfig.FormJSONFields)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, NewErrorRes(PARAM_ERROR, err.Error()))
+		return
+	}
+
+	items, err := s.app.ApplySource(appConfig, requestedSource(c), &source.Request{
+		Method:     c.Request.Method,
+		Headers:    c.Request.Header,
+		Query:      c.Request.URL.Query(),
+		RemoteAddr: c.ClientIP(),
+		Body:       body,
+		Data:       sample,
+	})
+	if err != nil {
+		c.JSON(http.StatusOK, NewErrorRes(PARAM_ERROR, err.Error()))
+		return
+	}
+
+	results := make([]*app.RouteDryRunResult, 0, len(items))
+	for i := range items {
+		results = append(results, s.app.DryRunRoutes(appConfig, &items[i]))
+	}
+	c.JSON(http.StatusOK, NewSuccessRes(results))
+}
+
 // ===== 模板管理接口处理函数 =====
 
 // handleGetTemplates 获取所有模板
@@ -214,6 +409,10 @@ func (s *HTTPServer) handleCreateTemplate(c *gin.Context) {
 		c.JSON(http.StatusOK, NewErrorRes(TEMPLATE_ALREADY_EXISTS, fmt.Sprintf("模板 %s 已存在", createReq.ID)))
 		return
 	}
+	if missing := s.config.MissingContactRefs(createReq.Targets, createReq.Mentions); len(missing) > 0 {
+		c.JSON(http.StatusBadRequest, NewErrorRes(CONTACT_DANGLING_REFS, fmt.Sprintf("引用了不存在的联系人: %v", missing)))
+		return
+	}
 
 	// 使用ConfigManager创建模板
 	if err := s.configManager.CreateTemplate(createReq.ID, createReq); err != nil {
@@ -225,6 +424,11 @@ func (s *HTTPServer) handleCreateTemplate(c *gin.Context) {
 		return
 	}
 
+	// 记录初始版本
+	if _, err := s.app.TemplateHistory().Record(createReq.ID, createReq, currentAdminUser(c), "创建模板"); err != nil {
+		logger.Error("记录模板版本失败", "template", createReq.ID, "error", err)
+	}
+
 	// 更新内存中的配置
 	s.config = s.configManager.GetConfig()
 
@@ -236,7 +440,8 @@ func (s *HTTPServer) handleUpdateTemplate(c *gin.Context) {
 	templateId := c.Param("templateId")
 
 	// 检查模板是否存在
-	if _, exists := s.config.Templates[templateId]; !exists {
+	oldTemplate, exists := s.config.Templates[templateId]
+	if !exists {
 		c.JSON(http.StatusOK, NewErrorRes(TEMPLATE_NOT_FOUND, fmt.Sprintf("模板 %s 不存在", templateId)))
 		return
 	}
@@ -246,6 +451,10 @@ func (s *HTTPServer) handleUpdateTemplate(c *gin.Context) {
 		c.JSON(http.StatusOK, NewErrorRes(PARAM_ERROR, "解析请求失败"))
 		return
 	}
+	if missing := s.config.MissingContactRefs(updateReq.Targets, updateReq.Mentions); len(missing) > 0 {
+		c.JSON(http.StatusBadRequest, NewErrorRes(CONTACT_DANGLING_REFS, fmt.Sprintf("引用了不存在的联系人: %v", missing)))
+		return
+	}
 
 	// 更新配置
 	newTemplates := make(map[string]config.MessageTemplate)
@@ -259,6 +468,15 @@ func (s *HTTPServer) handleUpdateTemplate(c *gin.Context) {
 		return
 	}
 
+	// 记录版本历史，历史记录失败不影响模板本身的更新
+	history := s.app.TemplateHistory()
+	if err := history.EnsureBaseline(templateId, oldTemplate); err != nil {
+		logger.Error("记录模板初始版本失败", "template", templateId, "error", err)
+	}
+	if _, err := history.Record(templateId, updateReq, currentAdminUser(c), ""); err != nil {
+		logger.Error("记录模板版本失败", "template", templateId, "error", err)
+	}
+
 	// 更新内存中的配置
 	s.config = s.configManager.GetConfig()
 
@@ -285,6 +503,9 @@ func (s *HTTPServer) handleDeleteTemplate(c *gin.Context) {
 		c.JSON(http.StatusOK, NewErrorRes(TEMPLATE_CONFIG_ERROR, "删除模板失败"))
 		return
 	}
+	if err := s.app.TemplateHistory().Remove(templateId); err != nil {
+		logger.Error("删除模板历史失败", "template", templateId, "error", err)
+	}
 
 	// 更新内存中的配置
 	s.config = s.configManager.GetConfig()
@@ -292,11 +513,165 @@ func (s *HTTPServer) handleDeleteTemplate(c *gin.Context) {
 	c.JSON(http.StatusOK, NewSuccessRes(fmt.Sprintf("模板 %s 删除成功", templateId)))
 }
 
+// handleGetTemplateVersions 获取模板历史版本
+func (s *HTTPServer) handleGetTemplateVersions(c *gin.Context) {
+	templateId := c.Param("templateId")
+
+	if _, exists := s.config.Templates[templateId]; !exists {
+		c.JSON(http.StatusOK, NewErrorRes(TEMPLATE_NOT_FOUND, fmt.Sprintf("模板 %s 不存在", templateId)))
+		return
+	}
+
+	c.JSON(http.StatusOK, NewSuccessRes(s.app.TemplateHistory().List(templateId)))
+}
+
+// handleGetTemplateVersion 获取模板指定版本
+func (s *HTTPServer) handleGetTemplateVersion(c *gin.Context) {
+	templateId := c.Param("templateId")
+
+	version, err := strconv.Atoi(c.Param("version"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, NewErrorRes(PARAM_ERROR, "版本号格式错误"))
+		return
+	}
+
+	templateVersion, err := s.app.TemplateHistory().Get(templateId, version)
+	if err != nil {
+		c.JSON(http.StatusOK, NewErrorRes(TEMPLATE_VERSION_ERROR, err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, NewSuccessRes(templateVersion))
+}
+
+// TemplateDiffResponse 模板版本对比响应结构体（驼峰命名）
+type TemplateDiffResponse struct {
+	TemplateID string `json:"templateId"`
+	From       int    `json:"from"`
+	To         int    `json:"to"`
+	Diff       string `json:"diff"`
+}
+
+// handleDiffTemplateVersions 对比模板的两个版本 (GET /templates/:templateId/diff?from=1&to=2)
+func (s *HTTPServer) handleDiffTemplateVersions(c *gin.Context) {
+	templateId := c.Param("templateId")
+
+	from, err := strconv.Atoi(c.Query("from"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, NewErrorRes(PARAM_ERROR, "from 版本号格式错误"))
+		return
+	}
+	to, err := strconv.Atoi(c.Query("to"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, NewErrorRes(PARAM_ERROR, "to 版本号格式错误"))
+		return
+	}
+
+	diff, err := s.app.TemplateHistory().Diff(templateId, from, to)
+	if err != nil {
+		c.JSON(http.StatusOK, NewErrorRes(TEMPLATE_VERSION_ERROR, err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, NewSuccessRes(TemplateDiffResponse{
+		TemplateID: templateId,
+		From:       from,
+		To:         to,
+		Diff:       diff,
+	}))
+}
+
+// TemplateRollbackRequest 模板回滚请求结构体
+type TemplateRollbackRequest struct {
+	Version int `json:"version" binding:"required"`
+}
+
+// handleRollbackTemplate 回滚模板到指定版本，回滚本身也会记录为一个新版本
+func (s *HTTPServer) handleRollbackTemplate(c *gin.Context) {
+	templateId := c.Param("templateId")
+
+	if _, exists := s.config.Templates[templateId]; !exists {
+		c.JSON(http.StatusOK, NewErrorRes(TEMPLATE_NOT_FOUND, fmt.Sprintf("模板 %s 不存在", templateId)))
+		return
+	}
+
+	var rollbackReq TemplateRollbackRequest
+	if err := c.ShouldBindJSON(&rollbackReq); err != nil {
+		c.JSON(http.StatusBadRequest, NewErrorRes(PARAM_ERROR, "解析请求失败"))
+		return
+	}
+
+	history := s.app.TemplateHistory()
+	target, err := history.Get(templateId, rollbackReq.Version)
+	if err != nil {
+		c.JSON(http.StatusOK, NewErrorRes(TEMPLATE_VERSION_ERROR, err.Error()))
+		return
+	}
+
+	newTemplates := make(map[string]config.MessageTemplate)
+	for k, v := range s.config.Templates {
+		newTemplates[k] = v
+	}
+	newTemplates[templateId] = target.Template
+
+	if err := s.configManager.UpdateTemplatesConfig(newTemplates); err != nil {
+		c.JSON(http.StatusOK, NewErrorRes(TEMPLATE_CONFIG_ERROR, "回滚模板失败"))
+		return
+	}
+
+	version, err := history.Record(templateId, target.Template, currentAdminUser(c), fmt.Sprintf("回滚到版本 %d", target.Version))
+	if err != nil {
+		logger.Error("记录模板版本失败", "template", templateId, "error", err)
+	}
+
+	// 更新内存中的配置
+	s.config = s.configManager.GetConfig()
+
+	c.JSON(http.StatusOK, NewSuccessRes(version))
+}
+
+// ===== 数据源适配器接口处理函数 =====
+
+// SourceInfo 数据源适配器信息结构体（驼峰命名）
+type SourceInfo struct {
+	Name            string                 `json:"name"`
+	TemplateID      string                 `json:"templateId"`
+	DefaultTemplate config.MessageTemplate `json:"defaultTemplate"`
+}
+
+// handleGetSources 获取所有数据源适配器及其内置模板
+func (s *HTTPServer) handleGetSources(c *gin.Context) {
+	sources := make([]SourceInfo, 0)
+	for _, name := range source.Names() {
+		tpl, err := source.BuiltinTemplate(name)
+		if err != nil {
+			continue
+		}
+		sources = append(sources, SourceInfo{
+			Name:            name,
+			TemplateID:      tpl.ID,
+			DefaultTemplate: tpl,
+		})
+	}
+	c.JSON(http.StatusOK, NewSuccessRes(sources))
+}
+
 // ===== 通知服务管理接口处理函数 =====
 
-// handleGetNotifiers 获取所有通知服务实例
+// NotifierListItem 通知服务实例及其熔断状态
+type NotifierListItem struct {
+	config.NotifierInstance
+	Circuit app.CircuitStatus `json:"circuit"`
+}
+
+// handleGetNotifiers 获取所有通知服务实例及熔断状态
 func (s *HTTPServer) handleGetNotifiers(c *gin.Context) {
-	c.JSON(http.StatusOK, NewSuccessRes(s.config.Notifiers))
+	circuits := s.app.CircuitStatuses()
+	notifiers := make(map[string]NotifierListItem, len(s.config.Notifiers))
+	for name, instance := range s.config.Notifiers {
+		notifiers[name] = NotifierListItem{NotifierInstance: instance, Circuit: circuits[name]}
+	}
+	c.JSON(http.StatusOK, NewSuccessRes(notifiers))
 }
 
 // NotifierConfigResponse 通知服务配置响应结构体（驼峰命名）
@@ -353,6 +728,14 @@ func (s *HTTPServer) handleUpdateNotifierConfig(c *gin.Context) {
 		c.JSON(http.StatusBadRequest, NewErrorRes(PARAM_ERROR, "解析请求失败"))
 		return
 	}
+	if err := s.config.ValidateQuietHoursRef(updateReq.QuietHoursID()); err != nil {
+		c.JSON(http.StatusBadRequest, NewErrorRes(QUIET_HOURS_NOT_FOUND, err.Error()))
+		return
+	}
+	if _, err := updateReq.RateLimit(); err != nil {
+		c.JSON(http.StatusBadRequest, NewErrorRes(NOTIFIER_CONFIG_ERROR, fmt.Sprintf("限流配置无效: %v", err)))
+		return
+	}
 
 	// 更新配置
 	newNotifiers := make(map[string]config.NotifierInstance)
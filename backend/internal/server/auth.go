@@ -11,6 +11,9 @@ import (
 	"github.com/gin-gonic/gin"
 )
 
+// adminUserKey 上下文中保存当前管理员用户名的键
+const adminUserKey = "adminUser"
+
 // AuthMiddleware 认证中间件
 type AuthMiddleware struct {
 	config *config.Config
@@ -84,6 +87,9 @@ func (am *AuthMiddleware) BasicAuthMiddleware() gin.HandlerFunc {
 			return
 		}
 
+		// 记录当前管理员，供模板历史等功能记录操作人
+		c.Set(adminUserKey, username)
+
 		// 认证成功，记录日志
 		// logger.Info("管理接口认证成功",
 		// 	"method", c.Request.Method,
@@ -94,6 +100,14 @@ func (am *AuthMiddleware) BasicAuthMiddleware() gin.HandlerFunc {
 	}
 }
 
+// currentAdminUser 获取当前请求的管理员用户名，未启用认证时返回 anonymous
+func currentAdminUser(c *gin.Context) string {
+	if user := c.GetString(adminUserKey); user != "" {
+		return user
+	}
+	return "anonymous"
+}
+
 // validateAppToken 验证应用Token
 func (am *AuthMiddleware) validateAppToken(r *http.Request, expectedToken string) bool {
 	// 从Header中获取Token
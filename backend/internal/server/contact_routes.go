@@ -0,0 +1,187 @@
+package server
+
+import (
+	"fmt"
+	"net/http"
+	"regexp"
+
+	"notify/internal/config"
+
+	"github.com/gin-gonic/gin"
+)
+
+// contactIDPattern 联系人和联系人组 ID 格式
+var contactIDPattern = regexp.MustCompile(`^[A-Za-z0-9_.-]+$`)
+
+// setupContactRoutes 设置联系人管理路由
+func (s *HTTPServer) setupContactRoutes(admin *gin.RouterGroup) {
+	contacts := admin.Group("/contacts")
+	{
+		contacts.GET("", s.handleGetContacts)                  // 获取所有联系人
+		contacts.GET("/validate", s.handleValidateContactRefs) // 检查引用了不存在联系人的配置
+		contacts.GET("/:id", s.handleGetContact)               // 获取单个联系人
+		contacts.PUT("/:id", s.handleSaveContact)              // 创建或更新联系人
+		contacts.DELETE("/:id", s.handleDeleteContact)         // 删除联系人
+	}
+
+	groups := admin.Group("/contact-groups")
+	{
+		groups.GET("", s.handleGetContactGroups)          // 获取所有联系人组
+		groups.GET("/:id", s.handleGetContactGroup)       // 获取单个联系人组
+		groups.PUT("/:id", s.handleSaveContactGroup)      // 创建或更新联系人组
+		groups.DELETE("/:id", s.handleDeleteContactGroup) // 删除联系人组
+	}
+}
+
+// handleGetContacts 获取所有联系人
+func (s *HTTPServer) handleGetContacts(c *gin.Context) {
+	contacts := s.config.Contacts
+	if contacts == nil {
+		contacts = map[string]config.Contact{}
+	}
+	c.JSON(http.StatusOK, NewSuccessRes(contacts))
+}
+
+// handleGetContact 获取单个联系人
+func (s *HTTPServer) handleGetContact(c *gin.Context) {
+	id := c.Param("id")
+	contact, exists := s.config.Contacts[id]
+	if !exists {
+		c.JSON(http.StatusOK, NewErrorRes(CONTACT_NOT_FOUND, fmt.Sprintf("联系人 %s 不存在", id)))
+		return
+	}
+	c.JSON(http.StatusOK, NewSuccessRes(contact))
+}
+
+// handleSaveContact 创建或更新联系人
+func (s *HTTPServer) handleSaveContact(c *gin.Context) {
+	id := c.Param("id")
+	if !contactIDPattern.MatchString(id) {
+		c.JSON(http.StatusBadRequest, NewErrorRes(PARAM_ERROR, "联系人 ID 只能包含字母、数字、下划线、点和连字符"))
+		return
+	}
+
+	var contact config.Contact
+	if err := c.ShouldBindJSON(&contact); err != nil {
+		c.JSON(http.StatusBadRequest, NewErrorRes(PARAM_ERROR, "解析请求失败"))
+		return
+	}
+	if contact.Name == "" {
+		contact.Name = id
+	}
+
+	if err := s.configManager.SaveContact(id, contact); err != nil {
+		c.JSON(http.StatusOK, NewErrorRes(CONTACT_CONFIG_ERROR, err.Error()))
+		return
+	}
+
+	// 更新内存中的配置
+	s.config = s.configManager.GetConfig()
+	c.JSON(http.StatusOK, NewSuccessRes(contact))
+}
+
+// handleDeleteContact 删除联系人，被联系人组、模板或路由规则引用时不能删除
+func (s *HTTPServer) handleDeleteContact(c *gin.Context) {
+	id := c.Param("id")
+	if _, exists := s.config.Contacts[id]; !exists {
+		c.JSON(http.StatusOK, NewErrorRes(CONTACT_NOT_FOUND, fmt.Sprintf("联系人 %s 不存在", id)))
+		return
+	}
+
+	if refs := s.configManager.GetContactReferences(config.ContactUserPrefix + id); len(refs) > 0 {
+		c.JSON(http.StatusOK, NewErrorRes(CONTACT_IN_USE, fmt.Sprintf("联系人 %s 正在被以下配置引用，不能删除: %v", id, refs)))
+		return
+	}
+
+	if err := s.configManager.DeleteContact(id); err != nil {
+		c.JSON(http.StatusOK, NewErrorRes(CONTACT_CONFIG_ERROR, err.Error()))
+		return
+	}
+
+	// 更新内存中的配置
+	s.config = s.configManager.GetConfig()
+	c.JSON(http.StatusOK, NewSuccessRes(fmt.Sprintf("联系人 %s 删除成功", id)))
+}
+
+// handleGetContactGroups 获取所有联系人组
+func (s *HTTPServer) handleGetContactGroups(c *gin.Context) {
+	groups := s.config.ContactGroups
+	if groups == nil {
+		groups = map[string]config.ContactGroup{}
+	}
+	c.JSON(http.StatusOK, NewSuccessRes(groups))
+}
+
+// handleGetContactGroup 获取单个联系人组
+func (s *HTTPServer) handleGetContactGroup(c *gin.Context) {
+	id := c.Param("id")
+	group, exists := s.config.ContactGroups[id]
+	if !exists {
+		c.JSON(http.StatusOK, NewErrorRes(CONTACT_NOT_FOUND, fmt.Sprintf("联系人组 %s 不存在", id)))
+		return
+	}
+	c.JSON(http.StatusOK, NewSuccessRes(group))
+}
+
+// handleSaveContactGroup 创建或更新联系人组，成员必须是已存在的联系人
+func (s *HTTPServer) handleSaveContactGroup(c *gin.Context) {
+	id := c.Param("id")
+	if !contactIDPattern.MatchString(id) {
+		c.JSON(http.StatusBadRequest, NewErrorRes(PARAM_ERROR, "联系人组 ID 只能包含字母、数字、下划线、点和连字符"))
+		return
+	}
+
+	var group config.ContactGroup
+	if err := c.ShouldBindJSON(&group); err != nil {
+		c.JSON(http.StatusBadRequest, NewErrorRes(PARAM_ERROR, "解析请求失败"))
+		return
+	}
+	if group.Name == "" {
+		group.Name = id
+	}
+	if group.Members == nil {
+		group.Members = []string{}
+	}
+
+	if err := s.configManager.SaveContactGroup(id, group); err != nil {
+		c.JSON(http.StatusOK, NewErrorRes(CONTACT_CONFIG_ERROR, err.Error()))
+		return
+	}
+
+	// 更新内存中的配置
+	s.config = s.configManager.GetConfig()
+	c.JSON(http.StatusOK, NewSuccessRes(group))
+}
+
+// handleDeleteContactGroup 删除联系人组，被模板或路由规则引用时不能删除
+func (s *HTTPServer) handleDeleteContactGroup(c *gin.Context) {
+	id := c.Param("id")
+	if _, exists := s.config.ContactGroups[id]; !exists {
+		c.JSON(http.StatusOK, NewErrorRes(CONTACT_NOT_FOUND, fmt.Sprintf("联系人组 %s 不存在", id)))
+		return
+	}
+
+	if refs := s.configManager.GetContactReferences(config.ContactGroupPrefix + id); len(refs) > 0 {
+		c.JSON(http.StatusOK, NewErrorRes(CONTACT_IN_USE, fmt.Sprintf("联系人组 %s 正在被以下配置引用，不能删除: %v", id, refs)))
+		return
+	}
+
+	if err := s.configManager.DeleteContactGroup(id); err != nil {
+		c.JSON(http.StatusOK, NewErrorRes(CONTACT_CONFIG_ERROR, err.Error()))
+		return
+	}
+
+	// 更新内存中的配置
+	s.config = s.configManager.GetConfig()
+	c.JSON(http.StatusOK, NewSuccessRes(fmt.Sprintf("联系人组 %s 删除成功", id)))
+}
+
+// handleValidateContactRefs 检查模板、路由规则和联系人组中引用了不存在联系人的配置
+func (s *HTTPServer) handleValidateContactRefs(c *gin.Context) {
+	dangling := s.config.DanglingContactRefs()
+	if len(dangling) > 0 {
+		c.JSON(http.StatusOK, NewBaseRes(CONTACT_DANGLING_REFS, "存在引用了不存在联系人的配置", dangling))
+		return
+	}
+	c.JSON(http.StatusOK, NewSuccessRes([]string{}))
+}
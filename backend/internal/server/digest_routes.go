@@ -0,0 +1,31 @@
+package server
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// setupDigestRoutes 设置摘要管理路由
+func (s *HTTPServer) setupDigestRoutes(admin *gin.RouterGroup) {
+	digests := admin.Group("/digests")
+	{
+		digests.GET("", s.handleGetDigests)          // 查看等待发送的摘要
+		digests.POST("/flush", s.handleFlushDigests) // 立即发送摘要
+	}
+}
+
+// handleGetDigests 查看等待发送的摘要，可按应用过滤
+func (s *HTTPServer) handleGetDigests(c *gin.Context) {
+	c.JSON(http.StatusOK, NewSuccessRes(s.app.PendingDigests(c.Query("app"))))
+}
+
+// handleFlushDigests 立即发送摘要，可按应用过滤
+func (s *HTTPServer) handleFlushDigests(c *gin.Context) {
+	count, err := s.app.FlushDigests(c.Request.Context(), c.Query("app"))
+	if err != nil {
+		c.JSON(http.StatusOK, NewBaseRes(NOTIFICATION_SEND_FAILED, err.Error(), map[string]any{"flushed": count}))
+		return
+	}
+	c.JSON(http.StatusOK, NewSuccessRes(map[string]any{"flushed": count}))
+}
@@ -0,0 +1,264 @@
+package server
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+
+	"notify/internal/app"
+	"notify/internal/config"
+
+	"github.com/gin-gonic/gin"
+)
+
+// setupEscalationAckRoutes 设置升级确认链接路由，链接带签名，点击消息按钮即可确认，不做认证
+func (s *HTTPServer) setupEscalationAckRoutes(api *gin.RouterGroup) {
+	api.GET("/escalations/:id/ack", s.handleEscalationAckLink)
+}
+
+// setupEscalationRoutes 设置值班表、升级策略和升级管理路由
+func (s *HTTPServer) setupEscalationRoutes(admin *gin.RouterGroup) {
+	schedules := admin.Group("/schedules")
+	{
+		schedules.GET("", s.handleGetSchedules)          // 获取所有值班表
+		schedules.GET("/:id", s.handleGetSchedule)       // 获取单个值班表
+		schedules.GET("/:id/oncall", s.handleGetOnCall)  // 查询值班人
+		schedules.PUT("/:id", s.handleSaveSchedule)      // 创建或更新值班表
+		schedules.DELETE("/:id", s.handleDeleteSchedule) // 删除值班表
+	}
+
+	policies := admin.Group("/escalation-policies")
+	{
+		policies.GET("", s.handleGetEscalationPolicies)         // 获取所有升级策略
+		policies.GET("/:id", s.handleGetEscalationPolicy)       // 获取单个升级策略
+		policies.PUT("/:id", s.handleSaveEscalationPolicy)      // 创建或更新升级策略
+		policies.DELETE("/:id", s.handleDeleteEscalationPolicy) // 删除升级策略
+	}
+
+	escalations := admin.Group("/escalations")
+	{
+		escalations.GET("", s.handleGetEscalations)         // 获取升级列表
+		escalations.GET("/:id", s.handleGetEscalation)      // 获取单个升级
+		escalations.POST("/:id/ack", s.handleAckEscalation) // 确认升级
+	}
+}
+
+// handleGetSchedules 获取所有值班表
+func (s *HTTPServer) handleGetSchedules(c *gin.Context) {
+	schedules := s.config.Schedules
+	if schedules == nil {
+		schedules = map[string]config.Schedule{}
+	}
+	c.JSON(http.StatusOK, NewSuccessRes(schedules))
+}
+
+// handleGetSchedule 获取单个值班表
+func (s *HTTPServer) handleGetSchedule(c *gin.Context) {
+	id := c.Param("id")
+	schedule, exists := s.config.Schedules[id]
+	if !exists {
+		c.JSON(http.StatusOK, NewErrorRes(SCHEDULE_NOT_FOUND, fmt.Sprintf("值班表 %s 不存在", id)))
+		return
+	}
+	c.JSON(http.StatusOK, NewSuccessRes(schedule))
+}
+
+// handleGetOnCall 查询值班人，at 为 RFC3339 时间，默认当前时间
+func (s *HTTPServer) handleGetOnCall(c *gin.Context) {
+	id := c.Param("id")
+	schedule, exists := s.config.Schedules[id]
+	if !exists {
+		c.JSON(http.StatusOK, NewErrorRes(SCHEDULE_NOT_FOUND, fmt.Sprintf("值班表 %s 不存在", id)))
+		return
+	}
+
+	at := time.Now()
+	if value := c.Query("at"); value != "" {
+		parsed, err := time.Parse(time.RFC3339, value)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, NewErrorRes(PARAM_ERROR, "at 需要 RFC3339 格式的时间"))
+			return
+		}
+		at = parsed
+	}
+
+	contactID, err := schedule.OnCall(at)
+	if err != nil {
+		c.JSON(http.StatusOK, NewErrorRes(SCHEDULE_CONFIG_ERROR, err.Error()))
+		return
+	}
+	c.JSON(http.StatusOK, NewSuccessRes(map[string]any{
+		"at":      at,
+		"contact": contactID,
+		"name":    s.config.Contacts[contactID].Name,
+	}))
+}
+
+// handleSaveSchedule 创建或更新值班表
+func (s *HTTPServer) handleSaveSchedule(c *gin.Context) {
+	id := c.Param("id")
+	if !contactIDPattern.MatchString(id) {
+		c.JSON(http.StatusBadRequest, NewErrorRes(PARAM_ERROR, "值班表 ID 只能包含字母、数字、下划线、点和连字符"))
+		return
+	}
+
+	var schedule config.Schedule
+	if err := c.ShouldBindJSON(&schedule); err != nil {
+		c.JSON(http.StatusBadRequest, NewErrorRes(PARAM_ERROR, "解析请求失败"))
+		return
+	}
+	if schedule.Name == "" {
+		schedule.Name = id
+	}
+
+	if err := s.configManager.SaveSchedule(id, schedule); err != nil {
+		c.JSON(http.StatusOK, NewErrorRes(SCHEDULE_CONFIG_ERROR, err.Error()))
+		return
+	}
+
+	// 更新内存中的配置
+	s.config = s.configManager.GetConfig()
+	c.JSON(http.StatusOK, NewSuccessRes(schedule))
+}
+
+// handleDeleteSchedule 删除值班表，被升级策略引用时不能删除
+func (s *HTTPServer) handleDeleteSchedule(c *gin.Context) {
+	id := c.Param("id")
+	if _, exists := s.config.Schedules[id]; !exists {
+		c.JSON(http.StatusOK, NewErrorRes(SCHEDULE_NOT_FOUND, fmt.Sprintf("值班表 %s 不存在", id)))
+		return
+	}
+
+	if policies := s.configManager.GetPoliciesUsingSchedule(id); len(policies) > 0 {
+		c.JSON(http.StatusOK, NewErrorRes(SCHEDULE_IN_USE, fmt.Sprintf("值班表 %s 正在被以下升级策略引用，不能删除: %v", id, policies)))
+		return
+	}
+
+	if err := s.configManager.DeleteSchedule(id); err != nil {
+		c.JSON(http.StatusOK, NewErrorRes(SCHEDULE_CONFIG_ERROR, err.Error()))
+		return
+	}
+
+	// 更新内存中的配置
+	s.config = s.configManager.GetConfig()
+	c.JSON(http.StatusOK, NewSuccessRes(fmt.Sprintf("值班表 %s 删除成功", id)))
+}
+
+// handleGetEscalationPolicies 获取所有升级策略
+func (s *HTTPServer) handleGetEscalationPolicies(c *gin.Context) {
+	policies := s.config.EscalationPolicies
+	if policies == nil {
+		policies = map[string]config.EscalationPolicy{}
+	}
+	c.JSON(http.StatusOK, NewSuccessRes(policies))
+}
+
+// handleGetEscalationPolicy 获取单个升级策略
+func (s *HTTPServer) handleGetEscalationPolicy(c *gin.Context) {
+	id := c.Param("id")
+	policy, exists := s.config.EscalationPolicies[id]
+	if !exists {
+		c.JSON(http.StatusOK, NewErrorRes(ESCALATION_NOT_FOUND, fmt.Sprintf("升级策略 %s 不存在", id)))
+		return
+	}
+	c.JSON(http.StatusOK, NewSuccessRes(policy))
+}
+
+// handleSaveEscalationPolicy 创建或更新升级策略
+func (s *HTTPServer) handleSaveEscalationPolicy(c *gin.Context) {
+	id := c.Param("id")
+	if !contactIDPattern.MatchString(id) {
+		c.JSON(http.StatusBadRequest, NewErrorRes(PARAM_ERROR, "升级策略 ID 只能包含字母、数字、下划线、点和连字符"))
+		return
+	}
+
+	var policy config.EscalationPolicy
+	if err := c.ShouldBindJSON(&policy); err != nil {
+		c.JSON(http.StatusBadRequest, NewErrorRes(PARAM_ERROR, "解析请求失败"))
+		return
+	}
+	if policy.Name == "" {
+		policy.Name = id
+	}
+
+	if err := s.configManager.SaveEscalationPolicy(id, policy); err != nil {
+		c.JSON(http.StatusOK, NewErrorRes(ESCALATION_CONFIG_ERROR, err.Error()))
+		return
+	}
+
+	// 更新内存中的配置
+	s.config = s.configManager.GetConfig()
+	c.JSON(http.StatusOK, NewSuccessRes(policy))
+}
+
+// handleDeleteEscalationPolicy 删除升级策略，被应用使用时不能删除
+func (s *HTTPServer) handleDeleteEscalationPolicy(c *gin.Context) {
+	id := c.Param("id")
+	if _, exists := s.config.EscalationPolicies[id]; !exists {
+		c.JSON(http.StatusOK, NewErrorRes(ESCALATION_NOT_FOUND, fmt.Sprintf("升级策略 %s 不存在", id)))
+		return
+	}
+
+	if apps := s.configManager.GetAppsUsingEscalationPolicy(id); len(apps) > 0 {
+		c.JSON(http.StatusOK, NewErrorRes(ESCALATION_POLICY_IN_USE, fmt.Sprintf("升级策略 %s 正在被以下应用使用，不能删除: %v", id, apps)))
+		return
+	}
+
+	if err := s.configManager.DeleteEscalationPolicy(id); err != nil {
+		c.JSON(http.StatusOK, NewErrorRes(ESCALATION_CONFIG_ERROR, err.Error()))
+		return
+	}
+
+	// 更新内存中的配置
+	s.config = s.configManager.GetConfig()
+	c.JSON(http.StatusOK, NewSuccessRes(fmt.Sprintf("升级策略 %s 删除成功", id)))
+}
+
+// handleGetEscalations 获取升级列表，可按状态过滤
+func (s *HTTPServer) handleGetEscalations(c *gin.Context) {
+	status := c.Query("status")
+	switch status {
+	case "", app.EscalationActive, app.EscalationAcked, app.EscalationExhausted:
+	default:
+		c.JSON(http.StatusBadRequest, NewErrorRes(PARAM_ERROR, "无效的状态，可选 active、acked、exhausted"))
+		return
+	}
+	c.JSON(http.StatusOK, NewSuccessRes(s.app.ListEscalations(status)))
+}
+
+// handleGetEscalation 获取单个升级
+func (s *HTTPServer) handleGetEscalation(c *gin.Context) {
+	id := c.Param("id")
+	escalation, exists := s.app.GetEscalation(id)
+	if !exists {
+		c.JSON(http.StatusOK, NewErrorRes(ESCALATION_NOT_FOUND, fmt.Sprintf("升级 %s 不存在", id)))
+		return
+	}
+	c.JSON(http.StatusOK, NewSuccessRes(escalation))
+}
+
+// handleAckEscalation 管理员确认升级
+func (s *HTTPServer) handleAckEscalation(c *gin.Context) {
+	escalation, err := s.app.AckEscalation(c.Request.Context(), c.Param("id"), currentAdminUser(c))
+	if err != nil {
+		c.JSON(http.StatusOK, NewErrorRes(ESCALATION_ACK_FAILED, err.Error()))
+		return
+	}
+	c.JSON(http.StatusOK, NewSuccessRes(escalation))
+}
+
+// handleEscalationAckLink 处理消息中的确认链接，返回纯文本结果方便在浏览器中查看
+func (s *HTTPServer) handleEscalationAckLink(c *gin.Context) {
+	id := c.Param("id")
+	if !s.app.VerifyEscalationAck(id, c.Query("sig")) {
+		c.String(http.StatusForbidden, "确认链接无效")
+		return
+	}
+
+	escalation, err := s.app.AckEscalation(c.Request.Context(), id, "link")
+	if err != nil {
+		c.String(http.StatusOK, err.Error())
+		return
+	}
+	c.String(http.StatusOK, fmt.Sprintf("已确认: %s", escalation.Message.Title))
+}
@@ -3,7 +3,9 @@ package server
 import (
 	"fmt"
 	"net/http"
+	"notify/internal/app"
 	"notify/internal/config"
+	"time"
 
 	"github.com/gin-gonic/gin"
 )
@@ -37,6 +39,16 @@ type HealthData struct {
 	AdminEndpoints    AdminEndpoints `json:"adminEndpoints"`
 	AdminAuthRequired bool           `json:"adminAuthRequired"` // 新增：admin接口是否需要认证
 	Version           string         `json:"version"`
+
+	Notifiers map[string]NotifierHealth `json:"notifiers"` // 各通知服务的熔断状态
+}
+
+// NotifierHealth 健康检查中的通知服务熔断状态，不包含错误详情，错误详情只在需要认证的通知服务列表中返回
+type NotifierHealth struct {
+	State         string     `json:"state"`
+	LastErrorAt   *time.Time `json:"lastErrorAt,omitempty"`
+	LastSuccessAt *time.Time `json:"lastSuccessAt,omitempty"`
+	RetryAt       *time.Time `json:"retryAt,omitempty"`
 }
 
 // handleHealth 健康检查
@@ -68,8 +80,23 @@ func (s *HTTPServer) handleHealth(c *gin.Context) {
 	// 检查admin是否需要认证：如果环境变量设置了用户名和密码，则需要认证
 	adminAuthRequired := config.EnvCfg.NOTIFY_USERNAME != "" && config.EnvCfg.NOTIFY_PASSWORD != ""
 
+	// 有通知服务处于熔断时标记为 degraded，服务本身仍可用
+	status := "healthy"
+	notifiers := make(map[string]NotifierHealth)
+	for name, circuit := range s.app.CircuitStatuses() {
+		if circuit.State != app.CircuitClosed {
+			status = "degraded"
+		}
+		notifiers[name] = NotifierHealth{
+			State:         circuit.State,
+			LastErrorAt:   circuit.LastErrorAt,
+			LastSuccessAt: circuit.LastSuccessAt,
+			RetryAt:       circuit.RetryAt,
+		}
+	}
+
 	healthData := HealthData{
-		Status:            "healthy",
+		Status:            status,
 		SupportedApps:     supportedApps,
 		AdminAuthRequired: adminAuthRequired,
 		AdminEndpoints: AdminEndpoints{
@@ -77,7 +104,8 @@ func (s *HTTPServer) handleHealth(c *gin.Context) {
 			Notifiers: "/api/v1/admin/notifiers",
 			Config:    "/api/v1/admin/config",
 		},
-		Version: config.EnvCfg.VERSION,
+		Version:   config.EnvCfg.VERSION,
+		Notifiers: notifiers,
 	}
 
 	c.JSON(http.StatusOK, NewSuccessRes(healthData))
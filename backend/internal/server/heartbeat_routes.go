@@ -0,0 +1,124 @@
+package server
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+
+	"notify/internal/app"
+	"notify/internal/config"
+
+	"github.com/gin-gonic/gin"
+)
+
+// pingBodyLimit ping 请求体读取的最大字节数
+const pingBodyLimit = 10 << 10
+
+// setupPingRoutes 设置心跳 ping 路由，ping 地址中的令牌即凭证，不做认证
+func (s *HTTPServer) setupPingRoutes(api *gin.RouterGroup) {
+	for _, method := range []string{http.MethodGet, http.MethodPost, http.MethodHead} {
+		api.Handle(method, "/ping/:token", s.handlePing)       // 成功
+		api.Handle(method, "/ping/:token/:kind", s.handlePing) // start、success 或 fail
+	}
+}
+
+// setupHeartbeatRoutes 设置心跳监控管理路由
+func (s *HTTPServer) setupHeartbeatRoutes(admin *gin.RouterGroup) {
+	heartbeats := admin.Group("/heartbeats")
+	{
+		heartbeats.GET("", s.handleGetHeartbeats)          // 获取所有心跳监控及最近 ping 时间
+		heartbeats.GET("/:id", s.handleGetHeartbeat)       // 获取单个心跳监控
+		heartbeats.PUT("/:id", s.handleSaveHeartbeat)      // 创建或更新心跳监控
+		heartbeats.DELETE("/:id", s.handleDeleteHeartbeat) // 删除心跳监控
+	}
+}
+
+// handlePing 记录心跳监控的 ping，失败 ping 的请求体会附在通知中
+func (s *HTTPServer) handlePing(c *gin.Context) {
+	kind := c.Param("kind")
+	if kind == "" {
+		kind = app.PingSuccess
+	}
+	if kind != app.PingStart && kind != app.PingSuccess && kind != app.PingFail {
+		c.JSON(http.StatusNotFound, NewErrorRes(PARAM_ERROR, fmt.Sprintf("未知的 ping 类型 %s，可选 start、success、fail", kind)))
+		return
+	}
+
+	var body string
+	if c.Request.Body != nil {
+		data, _ := io.ReadAll(io.LimitReader(c.Request.Body, pingBodyLimit))
+		body = string(data)
+	}
+
+	if _, err := s.app.Ping(c.Param("token"), kind, body); err != nil {
+		c.JSON(http.StatusNotFound, NewErrorRes(HEARTBEAT_NOT_FOUND, err.Error()))
+		return
+	}
+	c.JSON(http.StatusOK, NewSuccessRes("OK"))
+}
+
+// handleGetHeartbeats 获取所有心跳监控及最近 ping 时间
+func (s *HTTPServer) handleGetHeartbeats(c *gin.Context) {
+	c.JSON(http.StatusOK, NewSuccessRes(s.app.HeartbeatStatuses()))
+}
+
+// handleGetHeartbeat 获取单个心跳监控及最近 ping 时间
+func (s *HTTPServer) handleGetHeartbeat(c *gin.Context) {
+	id := c.Param("id")
+	for _, heartbeat := range s.app.HeartbeatStatuses() {
+		if heartbeat.ID == id {
+			c.JSON(http.StatusOK, NewSuccessRes(heartbeat))
+			return
+		}
+	}
+	c.JSON(http.StatusOK, NewErrorRes(HEARTBEAT_NOT_FOUND, fmt.Sprintf("心跳监控 %s 不存在", id)))
+}
+
+// handleSaveHeartbeat 创建或更新心跳监控，未指定令牌时自动生成
+func (s *HTTPServer) handleSaveHeartbeat(c *gin.Context) {
+	id := c.Param("id")
+	if !contactIDPattern.MatchString(id) {
+		c.JSON(http.StatusBadRequest, NewErrorRes(PARAM_ERROR, "心跳监控 ID 只能包含字母、数字、下划线、点和连字符"))
+		return
+	}
+
+	var heartbeat config.Heartbeat
+	if err := c.ShouldBindJSON(&heartbeat); err != nil {
+		c.JSON(http.StatusBadRequest, NewErrorRes(PARAM_ERROR, "解析请求失败"))
+		return
+	}
+	if heartbeat.Name == "" {
+		heartbeat.Name = id
+	}
+
+	heartbeat, err := s.configManager.SaveHeartbeat(id, heartbeat)
+	if err != nil {
+		c.JSON(http.StatusOK, NewErrorRes(HEARTBEAT_CONFIG_ERROR, err.Error()))
+		return
+	}
+
+	// 更新内存中的配置
+	s.config = s.configManager.GetConfig()
+	c.JSON(http.StatusOK, NewSuccessRes(gin.H{
+		"heartbeat": heartbeat,
+		"pingUrl":   app.HeartbeatPingURL(heartbeat.Token),
+	}))
+}
+
+// handleDeleteHeartbeat 删除心跳监控
+func (s *HTTPServer) handleDeleteHeartbeat(c *gin.Context) {
+	id := c.Param("id")
+	if _, exists := s.configManager.Heartbeats()[id]; !exists {
+		c.JSON(http.StatusOK, NewErrorRes(HEARTBEAT_NOT_FOUND, fmt.Sprintf("心跳监控 %s 不存在", id)))
+		return
+	}
+
+	if err := s.configManager.DeleteHeartbeat(id); err != nil {
+		c.JSON(http.StatusOK, NewErrorRes(HEARTBEAT_CONFIG_ERROR, err.Error()))
+		return
+	}
+
+	// 更新内存中的配置
+	s.config = s.configManager.GetConfig()
+	c.JSON(http.StatusOK, NewSuccessRes(fmt.Sprintf("心跳监控 %s 删除成功", id)))
+}
@@ -41,6 +41,12 @@ func NewHTTPServer(notificationApp *app.NotificationApp, addr string, configMana
 		configManager:  configManager,
 	}
 
+	// 只信任配置的反向代理转发的 X-Forwarded-For，未配置时客户端 IP 为连接地址，避免伪造请求头绕过按 IP 的请求限制
+	if err := server.router.SetTrustedProxies(config.EnvCfg.TRUSTED_PROXIES); err != nil {
+		logger.Error("TRUSTED_PROXIES 配置无效，不信任任何代理", "error", err)
+		server.router.SetTrustedProxies(nil)
+	}
+
 	// 添加中间件
 	server.router.Use(gin.Logger())
 	server.router.Use(gin.Recovery())
@@ -89,11 +95,23 @@ func (s *HTTPServer) setupRoutes() {
 	// 设置通知路由 (定义在 notify_routes.go)
 	s.setupNotifyRoutes(api)
 
+	// 设置升级确认链接路由 (定义在 escalation_routes.go)
+	s.setupEscalationAckRoutes(api)
+
+	// 设置消息确认链接路由 (定义在 message_routes.go)
+	s.setupMessageLinkRoutes(api)
+
+	// 设置心跳 ping 路由 (定义在 heartbeat_routes.go)
+	s.setupPingRoutes(api)
+
 	// 设置管理路由 (定义在 admin_routes.go)
 	s.setupAdminRoutes(api)
 
 	// 设置日志流路由 (定义在 log_routes.go)
 	s.setupLogRoutes(api)
+
+	// 设置媒体文件路由 (定义在 media_routes.go)
+	s.setupMediaRoutes()
 }
 
 // setupStaticRoutes 设置静态文件路由 (前端资源)
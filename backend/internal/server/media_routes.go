@@ -0,0 +1,90 @@
+package server
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"notify/internal/logger"
+	"notify/internal/media"
+	"notify/internal/notifier"
+
+	"github.com/gin-gonic/gin"
+)
+
+// setupMediaRoutes 设置媒体文件路由，媒体文件需要能被通知渠道直接访问，不做认证
+func (s *HTTPServer) setupMediaRoutes() {
+	s.router.GET("/media/:name", s.handleGetMedia)
+	s.router.HEAD("/media/:name", s.handleGetMedia)
+}
+
+// handleGetMedia 返回媒体文件，按剩余有效期设置缓存头
+func (s *HTTPServer) handleGetMedia(c *gin.Context) {
+	mediaStore := s.app.MediaStore()
+	if mediaStore == nil {
+		c.Status(http.StatusNotFound)
+		return
+	}
+
+	name := c.Param("name")
+	path, expiresAt, err := mediaStore.Open(name)
+	if err != nil {
+		c.Status(http.StatusNotFound)
+		return
+	}
+
+	maxAge := int(time.Until(expiresAt).Seconds())
+	c.Header("Cache-Control", fmt.Sprintf("public, max-age=%d, immutable", maxAge))
+	c.Header("Expires", expiresAt.UTC().Format(http.TimeFormat))
+	c.Header("ETag", fmt.Sprintf(`"%s"`, name))
+	// 只有位图直接显示，其他文件以下载方式返回，并禁止脚本执行，避免在本服务域名下被浏览器渲染
+	c.Header("X-Content-Type-Options", "nosniff")
+	c.Header("Content-Security-Policy", "default-src 'none'; sandbox")
+	if !media.IsInline(name) {
+		c.Header("Content-Disposition", "attachment")
+	}
+	c.File(path)
+}
+
+// handleUploadMedia 上传媒体文件 (POST /api/v1/admin/media)，返回公网地址
+func (s *HTTPServer) handleUploadMedia(c *gin.Context) {
+	mediaStore := s.app.MediaStore()
+	if mediaStore == nil {
+		c.JSON(http.StatusOK, NewErrorRes(CONFIG_ERROR, "媒体存储未启用，请配置 PUBLIC_BASE_URL"))
+		return
+	}
+
+	header, err := c.FormFile("file")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, NewErrorRes(PARAM_ERROR, "缺少上传文件 file"))
+		return
+	}
+	if header.Size > notifier.MaxAttachmentSize {
+		c.JSON(http.StatusBadRequest, NewErrorRes(PARAM_ERROR, fmt.Sprintf("文件超过 %d MB", notifier.MaxAttachmentSize>>20)))
+		return
+	}
+	file, err := header.Open()
+	if err != nil {
+		c.JSON(http.StatusBadRequest, NewErrorRes(PARAM_ERROR, "读取上传文件失败"))
+		return
+	}
+	defer file.Close()
+	data, err := io.ReadAll(file)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, NewErrorRes(PARAM_ERROR, "读取上传文件失败"))
+		return
+	}
+
+	contentType := header.Header.Get("Content-Type")
+	if contentType == "application/octet-stream" {
+		contentType = ""
+	}
+	object, err := mediaStore.Put(data, contentType)
+	if err != nil {
+		logger.Error("保存媒体文件失败", "error", err)
+		c.JSON(http.StatusOK, NewErrorRes(SYSTEM_ERROR, err.Error()))
+		return
+	}
+	c.JSON(http.StatusOK, NewSuccessRes(object))
+}
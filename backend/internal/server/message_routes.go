@@ -0,0 +1,154 @@
+package server
+
+import (
+	"errors"
+	"fmt"
+	"html/template"
+	"net/http"
+	"strconv"
+
+	"notify/internal/app"
+	"notify/internal/config"
+
+	"github.com/gin-gonic/gin"
+)
+
+// messageActionPage 确认链接的确认页面，填写名字后提交，避免链接预览等自动访问误确认
+var messageActionPage = template.Must(template.New("ack").Parse(`<!DOCTYPE html>
+<html>
+<head><meta charset="utf-8"><meta name="viewport" content="width=device-width, initial-scale=1"><title>{{.Label}}</title></head>
+<body style="font-family: sans-serif; max-width: 480px; margin: 40px auto; padding: 0 16px">
+<h3>{{.Title}}</h3>
+<form method="get">
+<input type="hidden" name="expires" value="{{.Expires}}">
+<input type="hidden" name="sig" value="{{.Sig}}">
+<p><input name="by" placeholder="你的名字" required autofocus style="width: 100%; padding: 8px"></p>
+<p><button type="submit" style="padding: 8px 24px">{{.Label}}</button></p>
+</form>
+</body>
+</html>`))
+
+// setupMessageLinkRoutes 设置消息确认链接路由，链接带签名和有效期，不做认证
+func (s *HTTPServer) setupMessageLinkRoutes(api *gin.RouterGroup) {
+	api.GET("/messages/:id/:action", s.handleMessageActionLink)
+}
+
+// setupMessageRoutes 设置消息历史管理路由
+func (s *HTTPServer) setupMessageRoutes(admin *gin.RouterGroup) {
+	messages := admin.Group("/messages")
+	{
+		messages.GET("", s.handleGetMessages)                     // 获取消息历史
+		messages.GET("/:id", s.handleGetMessage)                  // 获取单条消息的确认状态
+		messages.POST("/:id/:action", s.handleAdminMessageAction) // 确认或解决消息
+	}
+}
+
+// messageActionLabel 返回确认操作的显示名称
+func messageActionLabel(action string) string {
+	if action == app.MessageActionResolve {
+		return "解决"
+	}
+	return "确认"
+}
+
+// handleMessageActionLink 处理消息中的确认链接，未填写名字时返回确认页面
+func (s *HTTPServer) handleMessageActionLink(c *gin.Context) {
+	id, action := c.Param("id"), c.Param("action")
+	expires, sig := c.Query("expires"), c.Query("sig")
+	if err := s.app.VerifyMessageAction(id, action, expires, sig); err != nil {
+		c.String(http.StatusForbidden, err.Error())
+		return
+	}
+	record, exists := s.app.GetMessage(id)
+	if !exists {
+		c.String(http.StatusNotFound, fmt.Sprintf("消息 %s 不存在", id))
+		return
+	}
+
+	by := c.Query("by")
+	if by == "" {
+		c.Header("Content-Type", "text/html; charset=utf-8")
+		c.Status(http.StatusOK)
+		messageActionPage.Execute(c.Writer, map[string]string{
+			"Title":   record.Title,
+			"Label":   messageActionLabel(action),
+			"Expires": expires,
+			"Sig":     sig,
+		})
+		return
+	}
+
+	if _, err := s.app.AckMessage(c.Request.Context(), id, action, by); err != nil {
+		status := http.StatusBadRequest
+		if errors.Is(err, app.ErrMessageAlreadyDone) {
+			status = http.StatusConflict
+		}
+		c.String(status, err.Error())
+		return
+	}
+	c.String(http.StatusOK, fmt.Sprintf("已%s: %s", messageActionLabel(action), record.Title))
+}
+
+// handleGetMessages 获取消息历史，可按应用和状态过滤
+func (s *HTTPServer) handleGetMessages(c *gin.Context) {
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "100"))
+	c.JSON(http.StatusOK, NewSuccessRes(s.app.ListMessages(c.Query("app"), c.Query("status"), limit)))
+}
+
+// handleGetMessage 获取单条消息的确认状态
+func (s *HTTPServer) handleGetMessage(c *gin.Context) {
+	id := c.Param("id")
+	record, exists := s.app.GetMessage(id)
+	if !exists {
+		c.JSON(http.StatusOK, NewErrorRes(MESSAGE_NOT_FOUND, fmt.Sprintf("消息 %s 不存在", id)))
+		return
+	}
+	c.JSON(http.StatusOK, NewSuccessRes(record))
+}
+
+// handleAdminMessageAction 管理员确认或解决消息
+func (s *HTTPServer) handleAdminMessageAction(c *gin.Context) {
+	record, err := s.app.AckMessage(c.Request.Context(), c.Param("id"), c.Param("action"), currentAdminUser(c))
+	if err != nil {
+		c.JSON(http.StatusOK, NewErrorRes(MESSAGE_ACK_FAILED, err.Error()))
+		return
+	}
+	c.JSON(http.StatusOK, NewSuccessRes(record))
+}
+
+// handleGetAppMessage 外部系统使用应用Token查询消息的确认状态
+func (s *HTTPServer) handleGetAppMessage(c *gin.Context) {
+	appConfig := c.MustGet("appConfig").(config.NotificationApp)
+	id := c.Param("id")
+	record, exists := s.app.GetMessage(id)
+	if !exists || record.AppID != appConfig.AppID {
+		c.JSON(http.StatusOK, NewErrorRes(MESSAGE_NOT_FOUND, fmt.Sprintf("消息 %s 不存在", id)))
+		return
+	}
+	c.JSON(http.StatusOK, NewSuccessRes(record))
+}
+
+// handleAppMessageAction 外部系统使用应用Token确认或解决消息，请求体 {"by": "名字"}
+func (s *HTTPServer) handleAppMessageAction(c *gin.Context) {
+	appConfig := c.MustGet("appConfig").(config.NotificationApp)
+	id := c.Param("id")
+	if record, exists := s.app.GetMessage(id); !exists || record.AppID != appConfig.AppID {
+		c.JSON(http.StatusOK, NewErrorRes(MESSAGE_NOT_FOUND, fmt.Sprintf("消息 %s 不存在", id)))
+		return
+	}
+
+	var req struct {
+		By string `json:"by"`
+	}
+	c.ShouldBindJSON(&req)
+	if req.By == "" {
+		req.By = appConfig.AppID
+	}
+
+	record, err := s.app.AckMessage(c.Request.Context(), id, c.Param("action"), req.By)
+	if err != nil {
+		c.JSON(http.StatusOK, NewErrorRes(MESSAGE_ACK_FAILED, err.Error()))
+		return
+	}
+	c.JSON(http.StatusOK, NewSuccessRes(record))
+}
@@ -0,0 +1,21 @@
+package server
+
+import (
+	"net/http"
+
+	"notify/internal/metrics"
+
+	"github.com/gin-gonic/gin"
+)
+
+// handleMetrics 按 Prometheus 文本格式输出监控指标
+func (s *HTTPServer) handleMetrics(c *gin.Context) {
+	families := s.app.RateLimitMetrics()
+	families = append(families, s.app.RequestLimitMetrics()...)
+
+	c.Header("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+	c.Status(http.StatusOK)
+	if err := metrics.Write(c.Writer, families); err != nil {
+		c.Error(err)
+	}
+}
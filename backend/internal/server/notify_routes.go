@@ -1,15 +1,28 @@
 package server
 
 import (
+	"bytes"
+	"context"
+	"encoding/base64"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
+	"math"
+	"mime/multipart"
 	"net/http"
 	"net/url"
+	"sort"
+	"strconv"
 	"strings"
+	"time"
 
+	"notify/internal/app"
 	"notify/internal/config"
 	"notify/internal/logger"
+	"notify/internal/notifier"
+	"notify/internal/source"
+	"notify/internal/utils"
 
 	"github.com/gin-gonic/gin"
 )
@@ -22,6 +35,13 @@ func (s *HTTPServer) setupNotifyRoutes(api *gin.RouterGroup) {
 		notify.POST("/:appid", s.appAuthMiddleware(), s.handleSendNotification)
 		notify.GET("/:appid", s.appAuthMiddleware(), s.handleSendNotificationByQuery)
 		notify.PUT("/:appid", s.appAuthMiddleware(), s.handleSendNotification)
+
+		// 查询和确认已发送的消息 (定义在 message_routes.go)
+		notify.GET("/:appid/messages/:id", s.appAuthMiddleware(), s.handleGetAppMessage)
+		notify.POST("/:appid/messages/:id/:action", s.appAuthMiddleware(), s.handleAppMessageAction)
+
+		// 取消定时发送的通知 (定义在 schedule_routes.go)
+		notify.DELETE("/:appid/scheduled/:id", s.appAuthMiddleware(), s.handleCancelAppScheduled)
 	}
 }
 
@@ -74,6 +94,17 @@ func (s *HTTPServer) appAuthMiddleware() gin.HandlerFunc {
 	}
 }
 
+// respondRateLimited 请求超过应用或客户端 IP 的频率限制或每日配额时返回 429，Retry-After 为建议的等待秒数
+func (s *HTTPServer) respondRateLimited(c *gin.Context, appConfig config.NotificationApp, rejection *app.RequestRejection) {
+	retryAfter := int(math.Ceil(rejection.RetryAfter.Seconds()))
+	if retryAfter < 1 {
+		retryAfter = 1
+	}
+	logger.Warn("通知请求超过限制", "app", appConfig.AppID, "client", c.ClientIP(), "scope", rejection.Scope, "reason", rejection.Reason)
+	c.Header("Retry-After", strconv.Itoa(retryAfter))
+	c.JSON(http.StatusTooManyRequests, NewErrorRes(APP_RATE_LIMITED, rejection.Error()))
+}
+
 // ===== 通知接口处理函数 =====
 
 // NotificationSendResponseData 通知发送响应数据结构体（驼峰命名）
@@ -85,6 +116,19 @@ type NotificationSendResponseData struct {
 	Image   string `json:"image"`
 	URL     string `json:"url"`
 	Method  string `json:"method"`
+	Count   int    `json:"count"`   // 请求拆分出的通知条数
+	Skipped int    `json:"skipped"` // 低于应用最低级别未发送的消息数
+
+	Suppressed int `json:"suppressed"` // 去重窗口内被抑制的消息数
+	Grouped    int `json:"grouped"`    // 加入分组等待合并发送的消息数
+	Digested   int `json:"digested"`   // 加入摘要缓存的消息数
+
+	MessageIDs []string             `json:"messageIds,omitempty"` // 已发送消息的 ID，可用于查询确认状态
+	Results    []app.NotifierResult `json:"results,omitempty"`    // 每个通知服务的投递结果
+	RuleErrors []app.RuleEvaluation `json:"ruleErrors,omitempty"` // 匹配出错的路由规则
+
+	ScheduledIDs []string   `json:"scheduledIds,omitempty"` // 定时发送的通知 ID，可用于取消
+	SendAt       *time.Time `json:"sendAt,omitempty"`       // 定时发送的时间
 }
 
 // handleSendNotification 发送通知 (POST /notify/:appname) - 从request body获取JSON数据
@@ -95,60 +139,118 @@ func (s *HTTPServer) handleSendNotification(c *gin.Context) {
 	contentType := c.Request.Header.Get("Content-Type")
 	logger.Debug("请求体", "body", string(body))
 	logger.Debug("请求头", "contentType", contentType)
-	var rawData map[string]interface{} = make(map[string]interface{})
-	if strings.Contains(strings.ToLower(contentType), "application/x-www-form-urlencoded") {
+	rawData, err := s.parseRequestBody(c, contentType, body, appConfig.FormJSONFields)
+	if err != nil {
+		logger.Error("解析请求失败", "error", err)
+		c.JSON(http.StatusBadRequest, NewErrorRes(PARAM_ERROR, err.Error()))
+		return
+	}
+
+	logger.Debug("发送通知原始参数", "data", rawData)
+
+	s.dispatchNotification(c, appConfig, &source.Request{
+		Method:     c.Request.Method,
+		Headers:    c.Request.Header,
+		Query:      c.Request.URL.Query(),
+		RemoteAddr: c.ClientIP(),
+		Body:       body,
+		Data:       rawData,
+	})
+}
+
+// parseRequestBody 根据 Content-Type 解析请求体
+// 支持 JSON、表单、multipart、XML 和纯文本，纯文本放在 content 字段中
+// 表单字段支持 a[b]、a.b 形式的嵌套和重复字段，jsonFields 中的字段按 JSON 解析，上传的文件放在 attachments 中
+func (s *HTTPServer) parseRequestBody(c *gin.Context, contentType string, body []byte, jsonFields []string) (map[string]interface{}, error) {
+	rawData := make(map[string]interface{})
+	mediaType := strings.ToLower(contentType)
+
+	switch {
+	case strings.Contains(mediaType, "application/x-www-form-urlencoded"):
 		formData, err := url.ParseQuery(string(body))
 		if err != nil {
-			logger.Error("解析请求失败", "error", err)
-			c.JSON(http.StatusBadRequest, NewErrorRes(PARAM_ERROR, "解析请求失败"))
-			return
+			return nil, fmt.Errorf("解析请求失败")
 		}
-		for key, values := range formData {
-			if len(values) > 0 {
-				rawData[key] = values[0] // 取第一个值
-			}
-		}
-	} else if strings.Contains(strings.ToLower(contentType), "multipart/form-data") {
-		// 解析 multipart/form-data
-		err := c.Request.ParseMultipartForm(32 << 20) // 32MB max memory
-		if err != nil {
-			logger.Error("解析 multipart/form-data 失败", "error", err)
-			c.JSON(http.StatusBadRequest, NewErrorRes(PARAM_ERROR, "解析 multipart/form-data 失败"))
-			return
+		rawData = utils.DecodeForm(formData, jsonFields)
+	case strings.Contains(mediaType, "multipart/form-data"):
+		// 解析 multipart/form-data，请求体已被读取，需要重新放回
+		c.Request.Body = io.NopCloser(bytes.NewReader(body))
+		if err := c.Request.ParseMultipartForm(32 << 20); err != nil { // 32MB max memory
+			return nil, fmt.Errorf("解析 multipart/form-data 失败")
 		}
 
-		// 获取表单字段
-		if c.Request.MultipartForm != nil && c.Request.MultipartForm.Value != nil {
-			for key, values := range c.Request.MultipartForm.Value {
-				if len(values) > 0 {
-					rawData[key] = values[0] // 取第一个值
-				}
+		form := c.Request.MultipartForm
+		if form != nil && form.Value != nil {
+			rawData = utils.DecodeForm(form.Value, jsonFields)
+		}
+		if form != nil && len(form.File) > 0 {
+			attachments, err := readMultipartFiles(form.File)
+			if err != nil {
+				return nil, err
+			}
+			if existing, ok := rawData["attachments"].([]interface{}); ok {
+				attachments = append(existing, attachments...)
 			}
+			rawData["attachments"] = attachments
 		}
-	} else {
-		// 从JSON body获取原始数据
-		err := json.Unmarshal(body, &rawData)
+	case strings.Contains(mediaType, "xml"):
+		data, err := utils.ParseXML(body)
 		if err != nil {
-			logger.Error("解析请求失败", "error", err)
-			c.JSON(http.StatusBadRequest, NewErrorRes(PARAM_ERROR, "解析请求失败"))
-			return
+			return nil, err
+		}
+		rawData = data
+	case strings.HasPrefix(mediaType, "text/"):
+		rawData["content"] = string(body)
+	default:
+		if len(bytes.TrimSpace(body)) == 0 {
+			return rawData, nil
+		}
+		// 从JSON body获取原始数据，未声明为 JSON 且无法解析时按纯文本处理
+		if err := json.Unmarshal(body, &rawData); err != nil {
+			if strings.Contains(mediaType, "json") {
+				return nil, fmt.Errorf("解析请求失败")
+			}
+			rawData = map[string]interface{}{"content": string(body)}
 		}
 	}
+	return rawData, nil
+}
 
-	logger.Debug("发送通知原始参数", "data", rawData)
-
-	// 发送通知
-	if err := s.app.Send(c.Request.Context(), appConfig, &rawData); err != nil {
-		logger.Error("发送通知失败", "error", err)
-		c.JSON(http.StatusOK, NewErrorRes(NOTIFICATION_SEND_FAILED, err.Error()))
-		return
+// readMultipartFiles 读取上传的文件，转换为 base64 形式的附件
+func readMultipartFiles(files map[string][]*multipart.FileHeader) ([]interface{}, error) {
+	fields := make([]string, 0, len(files))
+	for field := range files {
+		fields = append(fields, field)
 	}
+	sort.Strings(fields)
 
-	// 返回成功响应
-	c.JSON(http.StatusOK, NewSuccessRes(map[string]interface{}{
-		"appName": appConfig.Name,
-		"method":  "POST",
-	}))
+	var attachments []interface{}
+	for _, field := range fields {
+		for _, header := range files[field] {
+			file, err := header.Open()
+			if err != nil {
+				return nil, fmt.Errorf("读取上传文件 %s 失败: %w", header.Filename, err)
+			}
+			data, err := io.ReadAll(file)
+			file.Close()
+			if err != nil {
+				return nil, fmt.Errorf("读取上传文件 %s 失败: %w", header.Filename, err)
+			}
+
+			contentType := header.Header.Get("Content-Type")
+			if contentType == "" || contentType == "application/octet-stream" {
+				contentType = http.DetectContentType(data)
+			}
+			attachments = append(attachments, map[string]interface{}{
+				"field":       field,
+				"name":        header.Filename,
+				"contentType": contentType,
+				"size":        len(data),
+				"base64":      base64.StdEncoding.EncodeToString(data),
+			})
+		}
+	}
+	return attachments, nil
 }
 
 // handleSendNotificationByQuery 发送通知 (GET /notify/:appname) - 从query参数获取
@@ -162,16 +264,173 @@ func (s *HTTPServer) handleSendNotificationByQuery(c *gin.Context) {
 			rawData[key] = values[0] // 取第一个值
 		}
 	}
+	delete(rawData, "_source") // 指定数据源的参数不作为通知数据
 	logger.Debug("发送通知原始参数", "data", rawData)
-	// 发送通知
-	if err := s.app.Send(c.Request.Context(), appConfig, &rawData); err != nil {
-		logger.Error("发送通知失败", "error", err)
-		c.JSON(http.StatusOK, NewErrorRes(NOTIFICATION_SEND_FAILED, err.Error()))
+
+	s.dispatchNotification(c, appConfig, &source.Request{
+		Method:     c.Request.Method,
+		Headers:    c.Request.Header,
+		Query:      c.Request.URL.Query(),
+		RemoteAddr: c.ClientIP(),
+		Data:       rawData,
+	})
+}
+
+// maxIdempotencyKeyLength 幂等键的最大长度
+const maxIdempotencyKeyLength = 255
+
+// dispatchNotification 经过数据源适配后发送通知并返回响应
+// 请求带有 Idempotency-Key 请求头或 idempotency_key 字段时，有效期内相同幂等键的请求直接返回第一次的响应
+func (s *HTTPServer) dispatchNotification(c *gin.Context, appConfig config.NotificationApp, req *source.Request) {
+	key := strings.TrimSpace(c.GetHeader("Idempotency-Key"))
+	if key == "" {
+		key = strings.TrimSpace(utils.GetString(req.Data, "idempotency_key"))
+	}
+	delete(req.Data, "idempotency_key")
+	if key == "" {
+		if rejection := s.app.AllowRequest(appConfig, c.ClientIP()); rejection != nil {
+			s.respondRateLimited(c, appConfig, rejection)
+			return
+		}
+		c.JSON(s.sendNotification(c.Request.Context(), c, appConfig, req))
+		return
+	}
+	if len(key) > maxIdempotencyKeyLength {
+		c.JSON(http.StatusBadRequest, NewErrorRes(PARAM_ERROR, fmt.Sprintf("幂等键长度不能超过 %d", maxIdempotencyKeyLength)))
 		return
 	}
+
+	// 已处理过的幂等键直接返回保存的结果，只有第一次执行时才计入请求限制
+	// 客户端超时断开后继续发送，重试的请求可以拿到这次的结果
+	ctx := context.WithoutCancel(c.Request.Context())
+	fingerprint := app.RequestFingerprint(req.Method, c.Request.URL.RawQuery, string(req.Body))
+	result, replayed, err := s.app.Idempotent(c.Request.Context(), appConfig.AppID, key, fingerprint, func() (int, []byte, error) {
+		if rejection := s.app.AllowRequest(appConfig, c.ClientIP()); rejection != nil {
+			return 0, nil, rejection
+		}
+		status, res := s.sendNotification(ctx, c, appConfig, req)
+		body, err := json.Marshal(res)
+		if err != nil {
+			return http.StatusInternalServerError, []byte(fmt.Sprintf(`{"code":%d,"msg":"生成响应失败","data":null}`, NOTIFICATION_SEND_FAILED)), nil
+		}
+		return status, body, nil
+	})
+	var rejection *app.RequestRejection
+	switch {
+	case errors.As(err, &rejection):
+		s.respondRateLimited(c, appConfig, rejection)
+		return
+	case errors.Is(err, app.ErrIdempotencyKeyReused):
+		c.JSON(http.StatusUnprocessableEntity, NewErrorRes(IDEMPOTENCY_KEY_REUSED, err.Error()))
+		return
+	case errors.Is(err, context.Canceled), errors.Is(err, context.DeadlineExceeded):
+		// 等待相同幂等键的请求时客户端已断开
+		c.JSON(http.StatusRequestTimeout, NewErrorRes(NOTIFICATION_SEND_FAILED, err.Error()))
+		return
+	case err != nil:
+		c.JSON(http.StatusInternalServerError, NewErrorRes(NOTIFICATION_SEND_FAILED, err.Error()))
+		return
+	}
+	if replayed {
+		logger.Info("重复的幂等请求，返回第一次的结果", "app", appConfig.AppID, "key", key)
+		c.Header("Idempotent-Replayed", "true")
+	}
+	c.Data(result.Status, "application/json; charset=utf-8", result.Body)
+}
+
+// requestedSource 返回请求参数指定的数据源，优先使用 ?_source=，也支持 ?source=
+// source 可能是 GET 请求中普通的通知数据，只有值是已注册的数据源时才作为数据源
+func requestedSource(c *gin.Context) string {
+	if name := c.Query("_source"); name != "" {
+		return name
+	}
+	if name := c.Query("source"); name != "" {
+		if _, ok := source.Get(name); ok {
+			return name
+		}
+	}
+	return ""
+}
+
+// sendNotification 经过数据源适配后发送通知，返回 HTTP 状态码和响应
+// 数据源可以在应用中配置，也可以通过 ?source= 或 ?_source= 参数指定
+func (s *HTTPServer) sendNotification(ctx context.Context, c *gin.Context, appConfig config.NotificationApp, req *source.Request) (int, *BaseRes) {
+	items, err := s.app.ApplySource(appConfig, requestedSource(c), req)
+	if err != nil {
+		logger.Error("解析数据源失败", "error", err)
+		if errors.Is(err, source.ErrUnauthorized) {
+			return http.StatusForbidden, NewErrorRes(AUTH_ERROR, err.Error())
+		}
+		return http.StatusBadRequest, NewErrorRes(PARAM_ERROR, err.Error())
+	}
+
+	// 发送通知，一个请求可能拆分为多条通知
+	response := NotificationSendResponseData{
+		AppName: appConfig.Name,
+		Method:  req.Method,
+		Count:   len(items),
+	}
+
+	// 指定了 send_at 或 delay 时保存为定时通知，到时间后再发送，时间已过时立即发送
+	// 经过数据源适配的请求数据来自第三方系统，只从 URL 参数读取，避免原始数据中的同名字段被当作定时参数
+	sendAtValue, delayValue := c.Query("send_at"), c.Query("delay")
+	if requestedSource(c) == "" && (appConfig.Source == nil || appConfig.Source.Type == "") {
+		sendAtValue = utils.FirstNonEmpty(utils.GetString(req.Data, "send_at"), sendAtValue)
+		delayValue = utils.FirstNonEmpty(utils.GetString(req.Data, "delay"), delayValue)
+	}
+	sendAt, scheduled, err := app.ParseSendTime(sendAtValue, delayValue, time.Now())
+	if err != nil {
+		return http.StatusBadRequest, NewErrorRes(PARAM_ERROR, err.Error())
+	}
+	if scheduled && sendAt.After(time.Now()) {
+		for _, item := range items {
+			delete(item, "send_at")
+			delete(item, "delay")
+			source.StripCredentials(item)
+			entry := s.app.Scheduler().Schedule(appConfig.AppID, item, sendAt)
+			response.ScheduledIDs = append(response.ScheduledIDs, entry.ID)
+		}
+		response.SendAt = &sendAt
+		return http.StatusOK, NewSuccessRes(response)
+	}
+
+	var errorMsgs []string
+	for i := range items {
+		result, err := s.app.Send(ctx, appConfig, &items[i])
+		if err != nil {
+			logger.Error("发送通知失败", "error", err)
+			errorMsgs = append(errorMsgs, err.Error())
+		}
+		if result == nil {
+			continue
+		}
+		response.Skipped += result.Skipped
+		response.Suppressed += result.Suppressed
+		response.Grouped += result.Grouped
+		response.Digested += result.Digested
+		for _, message := range result.Messages {
+			response.MessageIDs = append(response.MessageIDs, message.ID)
+		}
+		response.Results = append(response.Results, result.Results...)
+		response.RuleErrors = append(response.RuleErrors, result.RuleErrors...)
+		// 响应中返回第一条已发送的消息，未指定级别时为 info
+		if len(result.Messages) > 0 && response.Level == "" {
+			message := result.Messages[0]
+			response.Title = message.Title
+			response.Content = message.Content
+			response.Level = message.Severity
+			if response.Level == "" {
+				response.Level = notifier.SeverityInfo
+			}
+			response.Image = message.Image
+			response.URL = message.URL
+		}
+	}
+	if len(errorMsgs) > 0 {
+		// 失败时同样返回每个通知服务的投递结果
+		return http.StatusOK, NewBaseRes(NOTIFICATION_SEND_FAILED, strings.Join(errorMsgs, "\n"), response)
+	}
+
 	// 返回成功响应
-	c.JSON(http.StatusOK, NewSuccessRes(map[string]interface{}{
-		"appName": appConfig.Name,
-		"method":  "GET",
-	}))
+	return http.StatusOK, NewSuccessRes(response)
 }
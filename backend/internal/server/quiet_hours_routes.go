@@ -0,0 +1,137 @@
+package server
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+
+	"notify/internal/config"
+	"notify/internal/notifier"
+
+	"github.com/gin-gonic/gin"
+)
+
+// setupQuietHoursRoutes 设置免打扰策略和暂存消息路由
+func (s *HTTPServer) setupQuietHoursRoutes(admin *gin.RouterGroup) {
+	quietHours := admin.Group("/quiet-hours")
+	{
+		quietHours.GET("", s.handleGetQuietHoursList)         // 获取所有免打扰策略
+		quietHours.GET("/:id", s.handleGetQuietHours)         // 获取单个免打扰策略
+		quietHours.GET("/:id/status", s.handleGetQuietStatus) // 查询指定时间是否处于免打扰
+		quietHours.PUT("/:id", s.handleSaveQuietHours)        // 创建或更新免打扰策略
+		quietHours.DELETE("/:id", s.handleDeleteQuietHours)   // 删除免打扰策略
+	}
+
+	// 免打扰期间暂存的消息
+	admin.GET("/deferred", s.handleGetDeferredMessages)
+}
+
+// handleGetQuietHoursList 获取所有免打扰策略
+func (s *HTTPServer) handleGetQuietHoursList(c *gin.Context) {
+	quietHours := s.config.QuietHours
+	if quietHours == nil {
+		quietHours = map[string]config.QuietHours{}
+	}
+	c.JSON(http.StatusOK, NewSuccessRes(quietHours))
+}
+
+// handleGetQuietHours 获取单个免打扰策略
+func (s *HTTPServer) handleGetQuietHours(c *gin.Context) {
+	id := c.Param("id")
+	quietHours, exists := s.config.QuietHours[id]
+	if !exists {
+		c.JSON(http.StatusOK, NewErrorRes(QUIET_HOURS_NOT_FOUND, fmt.Sprintf("免打扰策略 %s 不存在", id)))
+		return
+	}
+	c.JSON(http.StatusOK, NewSuccessRes(quietHours))
+}
+
+// handleGetQuietStatus 查询指定时间是否处于免打扰，at 为 RFC3339 时间，默认当前时间
+func (s *HTTPServer) handleGetQuietStatus(c *gin.Context) {
+	id := c.Param("id")
+	quietHours, exists := s.config.QuietHours[id]
+	if !exists {
+		c.JSON(http.StatusOK, NewErrorRes(QUIET_HOURS_NOT_FOUND, fmt.Sprintf("免打扰策略 %s 不存在", id)))
+		return
+	}
+
+	at := time.Now()
+	if value := c.Query("at"); value != "" {
+		parsed, err := time.Parse(time.RFC3339, value)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, NewErrorRes(PARAM_ERROR, "at 需要 RFC3339 格式的时间"))
+			return
+		}
+		at = parsed
+	}
+
+	until, quiet := quietHours.QuietUntil(at)
+	status := map[string]any{
+		"at":    at,
+		"quiet": quiet,
+		"mode":  quietHours.EffectiveMode(),
+	}
+	if quiet {
+		status["until"] = until
+	}
+	c.JSON(http.StatusOK, NewSuccessRes(status))
+}
+
+// handleSaveQuietHours 创建或更新免打扰策略
+func (s *HTTPServer) handleSaveQuietHours(c *gin.Context) {
+	id := c.Param("id")
+	if !contactIDPattern.MatchString(id) {
+		c.JSON(http.StatusBadRequest, NewErrorRes(PARAM_ERROR, "免打扰策略 ID 只能包含字母、数字、下划线、点和连字符"))
+		return
+	}
+
+	var quietHours config.QuietHours
+	if err := c.ShouldBindJSON(&quietHours); err != nil {
+		c.JSON(http.StatusBadRequest, NewErrorRes(PARAM_ERROR, "解析请求失败"))
+		return
+	}
+	if quietHours.Name == "" {
+		quietHours.Name = id
+	}
+	if quietHours.BypassSeverity != "" && !notifier.IsSeverity(quietHours.BypassSeverity) {
+		c.JSON(http.StatusBadRequest, NewErrorRes(PARAM_ERROR, fmt.Sprintf("无效的级别 %s，可选 info、notice、warning、error、critical", quietHours.BypassSeverity)))
+		return
+	}
+
+	if err := s.configManager.SaveQuietHours(id, quietHours); err != nil {
+		c.JSON(http.StatusOK, NewErrorRes(QUIET_HOURS_CONFIG_ERROR, err.Error()))
+		return
+	}
+
+	// 更新内存中的配置
+	s.config = s.configManager.GetConfig()
+	c.JSON(http.StatusOK, NewSuccessRes(quietHours))
+}
+
+// handleDeleteQuietHours 删除免打扰策略，被应用或通知服务引用时不能删除
+func (s *HTTPServer) handleDeleteQuietHours(c *gin.Context) {
+	id := c.Param("id")
+	if _, exists := s.config.QuietHours[id]; !exists {
+		c.JSON(http.StatusOK, NewErrorRes(QUIET_HOURS_NOT_FOUND, fmt.Sprintf("免打扰策略 %s 不存在", id)))
+		return
+	}
+
+	if users := s.configManager.GetQuietHoursUsers(id); len(users) > 0 {
+		c.JSON(http.StatusOK, NewErrorRes(QUIET_HOURS_IN_USE, fmt.Sprintf("免打扰策略 %s 正在被引用，不能删除: %v", id, users)))
+		return
+	}
+
+	if err := s.configManager.DeleteQuietHours(id); err != nil {
+		c.JSON(http.StatusOK, NewErrorRes(QUIET_HOURS_CONFIG_ERROR, err.Error()))
+		return
+	}
+
+	// 更新内存中的配置
+	s.config = s.configManager.GetConfig()
+	c.JSON(http.StatusOK, NewSuccessRes(fmt.Sprintf("免打扰策略 %s 删除成功", id)))
+}
+
+// handleGetDeferredMessages 获取免打扰期间暂存的消息，可按应用过滤
+func (s *HTTPServer) handleGetDeferredMessages(c *gin.Context) {
+	c.JSON(http.StatusOK, NewSuccessRes(s.app.DeferredMessages(c.Query("app"))))
+}
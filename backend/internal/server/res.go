@@ -39,11 +39,13 @@ const (
 	APP_DISABLED       = 2002 // 应用未启用
 	APP_ALREADY_EXISTS = 2003 // 应用已存在
 	APP_CONFIG_ERROR   = 2004 // 应用配置错误
+	APP_RATE_LIMITED   = 2005 // 请求超过应用的频率限制或每日配额
 
 	// 模板相关错误码 (3000-3999)
 	TEMPLATE_NOT_FOUND      = 3001 // 模板不存在
 	TEMPLATE_ALREADY_EXISTS = 3002 // 模板已存在
 	TEMPLATE_CONFIG_ERROR   = 3003 // 模板配置错误
+	TEMPLATE_VERSION_ERROR  = 3004 // 模板版本不存在或无效
 
 	// 通知服务相关错误码 (4000-4999)
 	NOTIFIER_NOT_FOUND      = 4001 // 通知服务不存在
@@ -54,6 +56,35 @@ const (
 
 	// 通知发送相关错误码 (5000-5999)
 	NOTIFICATION_SEND_FAILED = 5001 // 通知发送失败
+	MESSAGE_NOT_FOUND        = 5002 // 消息不存在
+	MESSAGE_ACK_FAILED       = 5003 // 确认消息失败
+	SCHEDULED_NOT_FOUND      = 5004 // 定时通知不存在
+	JOB_NOT_FOUND            = 5005 // 定时任务不存在
+	JOB_CONFIG_ERROR         = 5006 // 定时任务配置错误
+	JOB_RUN_FAILED           = 5007 // 定时任务执行失败
+	HEARTBEAT_NOT_FOUND      = 5008 // 心跳监控不存在
+	HEARTBEAT_CONFIG_ERROR   = 5009 // 心跳监控配置错误
+	IDEMPOTENCY_KEY_REUSED   = 5010 // 幂等键已用于内容不同的请求
+
+	// 联系人相关错误码 (6000-6999)
+	CONTACT_NOT_FOUND     = 6001 // 联系人或联系人组不存在
+	CONTACT_CONFIG_ERROR  = 6002 // 联系人配置错误
+	CONTACT_IN_USE        = 6003 // 联系人正在被引用
+	CONTACT_DANGLING_REFS = 6004 // 存在引用了不存在联系人的配置
+
+	// 值班和升级相关错误码 (7000-7999)
+	SCHEDULE_NOT_FOUND       = 7001 // 值班表不存在
+	SCHEDULE_CONFIG_ERROR    = 7002 // 值班表配置错误
+	SCHEDULE_IN_USE          = 7003 // 值班表正在被升级策略引用
+	ESCALATION_NOT_FOUND     = 7004 // 升级策略或升级不存在
+	ESCALATION_CONFIG_ERROR  = 7005 // 升级策略配置错误
+	ESCALATION_POLICY_IN_USE = 7006 // 升级策略正在被应用使用
+	ESCALATION_ACK_FAILED    = 7007 // 确认升级失败
+
+	// 免打扰相关错误码 (8000-8999)
+	QUIET_HOURS_NOT_FOUND    = 8001 // 免打扰策略不存在
+	QUIET_HOURS_CONFIG_ERROR = 8002 // 免打扰策略配置错误
+	QUIET_HOURS_IN_USE       = 8003 // 免打扰策略正在被引用
 
 	// 系统错误码 (9000-9999)
 	SYSTEM_ERROR        = 9001  // 系统错误
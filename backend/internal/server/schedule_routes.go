@@ -0,0 +1,129 @@
+package server
+
+import (
+	"fmt"
+	"net/http"
+
+	"notify/internal/config"
+
+	"github.com/gin-gonic/gin"
+)
+
+// setupScheduleRoutes 设置定时通知和定时任务路由
+func (s *HTTPServer) setupScheduleRoutes(admin *gin.RouterGroup) {
+	scheduled := admin.Group("/scheduled")
+	{
+		scheduled.GET("", s.handleGetScheduled)           // 获取等待发送的定时通知
+		scheduled.DELETE("/:id", s.handleCancelScheduled) // 取消定时通知
+	}
+
+	jobs := admin.Group("/jobs")
+	{
+		jobs.GET("", s.handleGetJobs)          // 获取所有定时任务及执行状态
+		jobs.GET("/:id", s.handleGetJob)       // 获取单个定时任务
+		jobs.PUT("/:id", s.handleSaveJob)      // 创建或更新定时任务
+		jobs.DELETE("/:id", s.handleDeleteJob) // 删除定时任务
+		jobs.POST("/:id/run", s.handleRunJob)  // 立即执行一次定时任务
+	}
+}
+
+// handleGetScheduled 获取等待发送的定时通知，可按应用过滤
+func (s *HTTPServer) handleGetScheduled(c *gin.Context) {
+	c.JSON(http.StatusOK, NewSuccessRes(s.app.Scheduler().Pending(c.Query("app"))))
+}
+
+// handleCancelScheduled 管理员取消定时通知
+func (s *HTTPServer) handleCancelScheduled(c *gin.Context) {
+	id := c.Param("id")
+	if err := s.app.Scheduler().Cancel(id, ""); err != nil {
+		c.JSON(http.StatusOK, NewErrorRes(SCHEDULED_NOT_FOUND, err.Error()))
+		return
+	}
+	c.JSON(http.StatusOK, NewSuccessRes(fmt.Sprintf("定时通知 %s 已取消", id)))
+}
+
+// handleCancelAppScheduled 应用取消自己的定时通知
+func (s *HTTPServer) handleCancelAppScheduled(c *gin.Context) {
+	id := c.Param("id")
+	if err := s.app.Scheduler().Cancel(id, c.GetString("appID")); err != nil {
+		c.JSON(http.StatusOK, NewErrorRes(SCHEDULED_NOT_FOUND, err.Error()))
+		return
+	}
+	c.JSON(http.StatusOK, NewSuccessRes(fmt.Sprintf("定时通知 %s 已取消", id)))
+}
+
+// handleGetJobs 获取所有定时任务及执行状态
+func (s *HTTPServer) handleGetJobs(c *gin.Context) {
+	c.JSON(http.StatusOK, NewSuccessRes(s.app.Scheduler().Jobs()))
+}
+
+// handleGetJob 获取单个定时任务及执行状态
+func (s *HTTPServer) handleGetJob(c *gin.Context) {
+	id := c.Param("id")
+	for _, job := range s.app.Scheduler().Jobs() {
+		if job.ID == id {
+			c.JSON(http.StatusOK, NewSuccessRes(job))
+			return
+		}
+	}
+	c.JSON(http.StatusOK, NewErrorRes(JOB_NOT_FOUND, fmt.Sprintf("定时任务 %s 不存在", id)))
+}
+
+// handleSaveJob 创建或更新定时任务
+func (s *HTTPServer) handleSaveJob(c *gin.Context) {
+	id := c.Param("id")
+	if !contactIDPattern.MatchString(id) {
+		c.JSON(http.StatusBadRequest, NewErrorRes(PARAM_ERROR, "定时任务 ID 只能包含字母、数字、下划线、点和连字符"))
+		return
+	}
+
+	var job config.ScheduledJob
+	if err := c.ShouldBindJSON(&job); err != nil {
+		c.JSON(http.StatusBadRequest, NewErrorRes(PARAM_ERROR, "解析请求失败"))
+		return
+	}
+	if job.Name == "" {
+		job.Name = id
+	}
+
+	if err := s.configManager.SaveScheduledJob(id, job); err != nil {
+		c.JSON(http.StatusOK, NewErrorRes(JOB_CONFIG_ERROR, err.Error()))
+		return
+	}
+
+	// 更新内存中的配置
+	s.config = s.configManager.GetConfig()
+	c.JSON(http.StatusOK, NewSuccessRes(job))
+}
+
+// handleDeleteJob 删除定时任务
+func (s *HTTPServer) handleDeleteJob(c *gin.Context) {
+	id := c.Param("id")
+	if _, exists := s.configManager.ScheduledJobs()[id]; !exists {
+		c.JSON(http.StatusOK, NewErrorRes(JOB_NOT_FOUND, fmt.Sprintf("定时任务 %s 不存在", id)))
+		return
+	}
+
+	if err := s.configManager.DeleteScheduledJob(id); err != nil {
+		c.JSON(http.StatusOK, NewErrorRes(JOB_CONFIG_ERROR, err.Error()))
+		return
+	}
+
+	// 更新内存中的配置
+	s.config = s.configManager.GetConfig()
+	c.JSON(http.StatusOK, NewSuccessRes(fmt.Sprintf("定时任务 %s 删除成功", id)))
+}
+
+// handleRunJob 立即执行一次定时任务
+func (s *HTTPServer) handleRunJob(c *gin.Context) {
+	id := c.Param("id")
+	if _, exists := s.configManager.ScheduledJobs()[id]; !exists {
+		c.JSON(http.StatusOK, NewErrorRes(JOB_NOT_FOUND, fmt.Sprintf("定时任务 %s 不存在", id)))
+		return
+	}
+	if err := s.app.Scheduler().RunJob(id); err != nil {
+		c.JSON(http.StatusOK, NewErrorRes(JOB_RUN_FAILED, err.Error()))
+		return
+	}
+	c.JSON(http.StatusOK, NewSuccessRes(fmt.Sprintf("定时任务 %s 已执行", id)))
+}
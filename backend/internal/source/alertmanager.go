@@ -0,0 +1,196 @@
+package source
+
+import (
+	"fmt"
+	"strings"
+
+	"notify/internal/config"
+	"notify/internal/notifier"
+	"notify/internal/utils"
+)
+
+func init() {
+	register(&alertmanagerAdapter{}, "prometheus")
+	register(&grafanaAdapter{})
+}
+
+// alertmanagerAdapter Prometheus Alertmanager webhook 适配器
+// 文档: https://prometheus.io/docs/alerting/latest/configuration/#webhook_config
+type alertmanagerAdapter struct{}
+
+// Name 返回适配器名称
+func (a *alertmanagerAdapter) Name() string {
+	return "alertmanager"
+}
+
+// DefaultTemplate 返回默认模板
+func (a *alertmanagerAdapter) DefaultTemplate() config.MessageTemplate {
+	return alertTemplate("Alertmanager")
+}
+
+// Parse 解析 Alertmanager webhook
+func (a *alertmanagerAdapter) Parse(req *Request, cfg *config.SourceConfig) ([]map[string]any, error) {
+	alerts := utils.GetSlice(req.Data, "alerts")
+	if alerts == nil {
+		return nil, fmt.Errorf("不是有效的 Alertmanager 数据：缺少 alerts 字段")
+	}
+
+	if cfg != nil && cfg.Group {
+		return []map[string]any{groupAlerts(req.Data, alerts, "").Map(a.Name())}, nil
+	}
+
+	items := make([]map[string]any, 0, len(alerts))
+	for _, item := range alerts {
+		alert, ok := item.(map[string]any)
+		if !ok {
+			continue
+		}
+		items = append(items, alertEnvelope(req.Data, alert).Map(a.Name()))
+	}
+	return items, nil
+}
+
+// grafanaAdapter Grafana 统一告警 webhook 适配器，格式与 Alertmanager 兼容并增加了 title、message 等字段
+// 文档: https://grafana.com/docs/grafana/latest/alerting/configure-notifications/manage-contact-points/integrations/webhook-notifier/
+type grafanaAdapter struct{}
+
+// Name 返回适配器名称
+func (g *grafanaAdapter) Name() string {
+	return "grafana"
+}
+
+// DefaultTemplate 返回默认模板
+func (g *grafanaAdapter) DefaultTemplate() config.MessageTemplate {
+	return alertTemplate("Grafana")
+}
+
+// Parse 解析 Grafana 告警 webhook
+func (g *grafanaAdapter) Parse(req *Request, cfg *config.SourceConfig) ([]map[string]any, error) {
+	alerts := utils.GetSlice(req.Data, "alerts")
+	if alerts == nil {
+		return nil, fmt.Errorf("不是有效的 Grafana 告警数据：缺少 alerts 字段")
+	}
+
+	if cfg != nil && cfg.Group {
+		envelope := groupAlerts(req.Data, alerts, utils.GetString(req.Data, "message"))
+		envelope.Title = utils.FirstNonEmpty(utils.GetString(req.Data, "title"), envelope.Title)
+		return []map[string]any{envelope.Map(g.Name())}, nil
+	}
+
+	items := make([]map[string]any, 0, len(alerts))
+	for _, item := range alerts {
+		alert, ok := item.(map[string]any)
+		if !ok {
+			continue
+		}
+		envelope := alertEnvelope(req.Data, alert)
+		envelope.URL = utils.FirstNonEmpty(
+			utils.GetString(alert, "panelURL"),
+			utils.GetString(alert, "dashboardURL"),
+			envelope.URL,
+		)
+		envelope.Image = utils.GetString(alert, "imageURL")
+		if value := utils.GetString(alert, "valueString"); value != "" {
+			envelope.Extra["value"] = value
+		}
+		items = append(items, envelope.Map(g.Name()))
+	}
+	return items, nil
+}
+
+// alertEnvelope 把单条 Alertmanager 格式的告警转换为标准数据
+func alertEnvelope(payload map[string]any, alert map[string]any) Envelope {
+	labels := stringMap(utils.GetMap(alert, "labels"))
+	annotations := stringMap(utils.GetMap(alert, "annotations"))
+
+	title := utils.FirstNonEmpty(annotations["summary"], annotations["title"], labels["alertname"], "告警")
+	content := utils.FirstNonEmpty(annotations["description"], annotations["message"], annotations["summary"])
+	if instance := labels["instance"]; instance != "" && !strings.Contains(content, instance) {
+		content = strings.TrimSpace(content + "\n实例: " + instance)
+	}
+
+	return Envelope{
+		Title:    title,
+		Content:  content,
+		Severity: NormalizeSeverity(labels["severity"]),
+		Status:   alertStatus(utils.FirstNonEmpty(utils.GetString(alert, "status"), utils.GetString(payload, "status"))),
+		Labels:   labels,
+		URL:      utils.FirstNonEmpty(utils.GetString(alert, "generatorURL"), utils.GetString(payload, "externalURL")),
+		Raw:      payload,
+		Extra: map[string]any{
+			"annotations": annotations,
+			"fingerprint": utils.GetString(alert, "fingerprint"),
+			"startsAt":    utils.GetString(alert, "startsAt"),
+			"endsAt":      utils.GetString(alert, "endsAt"),
+			"alert":       alert,
+		},
+	}
+}
+
+// groupAlerts 把一组告警合并为一条标准数据
+func groupAlerts(payload map[string]any, alerts []any, message string) Envelope {
+	commonLabels := stringMap(utils.GetMap(payload, "commonLabels"))
+	commonAnnotations := stringMap(utils.GetMap(payload, "commonAnnotations"))
+
+	firing := 0
+	severity := ""
+	lines := make([]string, 0, len(alerts))
+	for _, item := range alerts {
+		alert, ok := item.(map[string]any)
+		if !ok {
+			continue
+		}
+		envelope := alertEnvelope(payload, alert)
+		icon := "✅"
+		if envelope.Status == "firing" {
+			firing++
+			icon = "🔥"
+		}
+		if notifier.SeverityRank(envelope.Severity) > notifier.SeverityRank(severity) {
+			severity = envelope.Severity
+		}
+		line := fmt.Sprintf("%s %s", icon, envelope.Title)
+		if envelope.Content != "" && envelope.Content != envelope.Title {
+			line += ": " + envelope.Content
+		}
+		lines = append(lines, line)
+	}
+
+	status := alertStatus(utils.GetString(payload, "status"))
+	alertname := utils.FirstNonEmpty(commonLabels["alertname"], utils.GetString(payload, "groupLabels.alertname"), "告警")
+	title := fmt.Sprintf("[%s:%d] %s", strings.ToUpper(status), len(alerts), alertname)
+	if status == "firing" {
+		title = fmt.Sprintf("[FIRING:%d] %s", firing, alertname)
+	}
+
+	content := strings.Join(lines, "\n")
+	if message != "" {
+		content = message
+	}
+
+	return Envelope{
+		Title:    title,
+		Content:  content,
+		Severity: severity,
+		Status:   status,
+		Labels:   commonLabels,
+		URL:      utils.GetString(payload, "externalURL"),
+		Raw:      payload,
+		Extra: map[string]any{
+			"annotations": commonAnnotations,
+			"groupKey":    utils.GetString(payload, "groupKey"),
+			"alerts":      alerts,
+			"firingCount": firing,
+		},
+	}
+}
+
+// alertStatus 统一告警状态为 firing 或 resolved
+func alertStatus(status string) string {
+	switch strings.ToLower(status) {
+	case "resolved", "ok", "normal", "up":
+		return "resolved"
+	default:
+		return "firing"
+	}
+}
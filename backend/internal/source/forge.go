@@ -0,0 +1,434 @@
+package source
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"fmt"
+	"strings"
+
+	"notify/internal/config"
+	"notify/internal/utils"
+)
+
+func init() {
+	register(&forgeAdapter{name: "github", header: "X-GitHub-Event", parse: parseGitHubEvent, verify: verifyGitHubSignature})
+	register(&forgeAdapter{name: "gitea", header: "X-Gitea-Event", parse: parseGitHubEvent, verify: verifyGiteaSignature}, "forgejo")
+	register(&forgeAdapter{name: "gitlab", header: "X-Gitlab-Event", parse: parseGitLabEvent, verify: verifyGitLabToken})
+	register(&gitAutoAdapter{})
+}
+
+// ForgeEvent 代码托管平台事件的标准化模型
+type ForgeEvent struct {
+	Event   string // 标准化的事件类型: push、tag_push、pull_request、issues、issue_comment、release、pipeline
+	Action  string // 事件动作，如 opened、closed、merged
+	Repo    map[string]any
+	Actor   string
+	Ref     string
+	Branch  string
+	Tag     string
+	Title   string // PR/Issue/Release 的标题
+	Number  string // PR/Issue 编号
+	Body    string // 评论或描述内容
+	Status  string // 流水线状态
+	Commits []map[string]any
+	URL     string
+}
+
+// forgeAdapter GitHub、Gitea、GitLab webhook 适配器，事件类型来自各自的请求头
+type forgeAdapter struct {
+	name   string
+	header string
+	parse  func(event string, data map[string]any) (*ForgeEvent, error)
+	verify func(req *Request, secret string) bool
+}
+
+// Name 返回适配器名称
+func (f *forgeAdapter) Name() string {
+	return f.name
+}
+
+// DefaultTemplate 返回默认模板
+func (f *forgeAdapter) DefaultTemplate() config.MessageTemplate {
+	return forgeTemplate(f.name)
+}
+
+// Parse 校验签名、识别事件并转换为标准数据
+func (f *forgeAdapter) Parse(req *Request, cfg *config.SourceConfig) ([]map[string]any, error) {
+	if cfg != nil && cfg.Secret != "" && !f.verify(req, cfg.Secret) {
+		return nil, ErrUnauthorized
+	}
+
+	rawEvent := req.Headers.Get(f.header)
+	if rawEvent == "" {
+		return nil, fmt.Errorf("缺少 %s 请求头", f.header)
+	}
+
+	event, err := f.parse(rawEvent, req.Data)
+	if err != nil {
+		return nil, err
+	}
+	// 不支持的事件和 ping 事件直接忽略
+	if event == nil {
+		return nil, nil
+	}
+	if !eventAllowed(cfg, event.Event, rawEvent) {
+		return nil, nil
+	}
+
+	return []map[string]any{event.envelope(req.Data).Map(f.name)}, nil
+}
+
+// gitAutoAdapter 根据请求头自动识别 GitHub、Gitea、GitLab
+type gitAutoAdapter struct{}
+
+// Name 返回适配器名称
+func (g *gitAutoAdapter) Name() string {
+	return "git"
+}
+
+// DefaultTemplate 返回默认模板
+func (g *gitAutoAdapter) DefaultTemplate() config.MessageTemplate {
+	return forgeTemplate("Git")
+}
+
+// Parse 按请求头选择具体的适配器，Gitea 同时会发送 X-GitHub-Event，所以优先判断 Gitea
+func (g *gitAutoAdapter) Parse(req *Request, cfg *config.SourceConfig) ([]map[string]any, error) {
+	for _, name := range []string{"gitea", "gitlab", "github"} {
+		adapter := adapters[name].(*forgeAdapter)
+		if req.Headers.Get(adapter.header) != "" {
+			return adapter.Parse(req, cfg)
+		}
+	}
+	return nil, fmt.Errorf("无法识别代码托管平台，缺少 X-GitHub-Event、X-Gitlab-Event 或 X-Gitea-Event 请求头")
+}
+
+// eventAllowed 检查事件是否在应用配置的事件过滤列表中，支持标准化事件名和原始事件名
+func eventAllowed(cfg *config.SourceConfig, event, rawEvent string) bool {
+	if cfg == nil || len(cfg.Events) == 0 {
+		return true
+	}
+	for _, allowed := range cfg.Events {
+		if strings.EqualFold(allowed, event) || strings.EqualFold(allowed, rawEvent) || allowed == "*" {
+			return true
+		}
+	}
+	return false
+}
+
+// verifyGitHubSignature 校验 X-Hub-Signature-256: sha256=<hex>
+func verifyGitHubSignature(req *Request, secret string) bool {
+	signature := strings.TrimPrefix(req.Headers.Get("X-Hub-Signature-256"), "sha256=")
+	return verifyHMAC(req.Body, secret, signature)
+}
+
+// verifyGiteaSignature 校验 X-Gitea-Signature: <hex>，兼容 Forgejo 和 GitHub 格式的签名头
+func verifyGiteaSignature(req *Request, secret string) bool {
+	signature := utils.FirstNonEmpty(
+		req.Headers.Get("X-Gitea-Signature"),
+		req.Headers.Get("X-Forgejo-Signature"),
+		strings.TrimPrefix(req.Headers.Get("X-Hub-Signature-256"), "sha256="),
+	)
+	return verifyHMAC(req.Body, secret, signature)
+}
+
+// verifyGitLabToken 校验 X-Gitlab-Token 与配置的密钥一致
+func verifyGitLabToken(req *Request, secret string) bool {
+	token := req.Headers.Get("X-Gitlab-Token")
+	return subtle.ConstantTimeCompare([]byte(token), []byte(secret)) == 1
+}
+
+// verifyHMAC 校验请求体的 HMAC-SHA256 十六进制签名
+func verifyHMAC(body []byte, secret, signature string) bool {
+	if signature == "" {
+		return false
+	}
+	expected, err := hex.DecodeString(signature)
+	if err != nil {
+		return false
+	}
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return hmac.Equal(mac.Sum(nil), expected)
+}
+
+// parseGitHubEvent 解析 GitHub 事件，Gitea 的事件格式与 GitHub 兼容
+func parseGitHubEvent(event string, data map[string]any) (*ForgeEvent, error) {
+	repo := utils.GetMap(data, "repository")
+	e := &ForgeEvent{
+		Action: utils.GetString(data, "action"),
+		Repo: map[string]any{
+			"name":     utils.GetString(repo, "name"),
+			"fullName": utils.GetString(repo, "full_name"),
+			"url":      utils.GetString(repo, "html_url"),
+		},
+		Actor: utils.FirstNonEmpty(
+			utils.GetString(data, "sender.login"),
+			utils.GetString(data, "pusher.login"),
+			utils.GetString(data, "pusher.name"),
+		),
+	}
+
+	switch strings.ToLower(event) {
+	case "push":
+		e.Ref = utils.GetString(data, "ref")
+		e.Event = "push"
+		if strings.HasPrefix(e.Ref, "refs/tags/") {
+			e.Event = "tag_push"
+		}
+		e.URL = utils.FirstNonEmpty(utils.GetString(data, "compare"), utils.GetString(data, "compare_url"), utils.GetString(repo, "html_url"))
+		for _, item := range utils.GetSlice(data, "commits") {
+			commit, _ := item.(map[string]any)
+			e.Commits = append(e.Commits, map[string]any{
+				"id":      utils.GetString(commit, "id"),
+				"message": utils.GetString(commit, "message"),
+				"author":  utils.FirstNonEmpty(utils.GetString(commit, "author.name"), utils.GetString(commit, "author.username")),
+				"url":     utils.GetString(commit, "url"),
+			})
+		}
+	case "pull_request":
+		e.Event = "pull_request"
+		e.Title = utils.GetString(data, "pull_request.title")
+		e.Number = utils.FirstNonEmpty(utils.GetString(data, "number"), utils.GetString(data, "pull_request.number"))
+		e.URL = utils.GetString(data, "pull_request.html_url")
+		e.Body = utils.GetString(data, "pull_request.body")
+		e.Branch = utils.GetString(data, "pull_request.base.ref")
+		e.Ref = utils.GetString(data, "pull_request.head.ref")
+		if e.Action == "closed" && utils.GetString(data, "pull_request.merged") == "true" {
+			e.Action = "merged"
+		}
+	case "issues":
+		e.Event = "issues"
+		e.Title = utils.GetString(data, "issue.title")
+		e.Number = utils.GetString(data, "issue.number")
+		e.URL = utils.GetString(data, "issue.html_url")
+		e.Body = utils.GetString(data, "issue.body")
+	case "issue_comment", "pull_request_comment":
+		e.Event = "issue_comment"
+		e.Title = utils.GetString(data, "issue.title")
+		e.Number = utils.GetString(data, "issue.number")
+		e.URL = utils.FirstNonEmpty(utils.GetString(data, "comment.html_url"), utils.GetString(data, "issue.html_url"))
+		e.Body = utils.GetString(data, "comment.body")
+	case "release":
+		e.Event = "release"
+		e.Tag = utils.GetString(data, "release.tag_name")
+		e.Title = utils.FirstNonEmpty(utils.GetString(data, "release.name"), e.Tag)
+		e.URL = utils.GetString(data, "release.html_url")
+		e.Body = utils.GetString(data, "release.body")
+	case "workflow_run":
+		e.Event = "pipeline"
+		e.Title = utils.GetString(data, "workflow_run.name")
+		e.Branch = utils.GetString(data, "workflow_run.head_branch")
+		e.URL = utils.GetString(data, "workflow_run.html_url")
+		e.Status = utils.FirstNonEmpty(utils.GetString(data, "workflow_run.conclusion"), utils.GetString(data, "workflow_run.status"))
+		// 只在运行结束时通知，避免 requested/in_progress 刷屏
+		if e.Action != "" && e.Action != "completed" {
+			return nil, nil
+		}
+	default:
+		return nil, nil
+	}
+
+	fillRefNames(e)
+	return e, nil
+}
+
+// parseGitLabEvent 解析 GitLab 事件
+func parseGitLabEvent(event string, data map[string]any) (*ForgeEvent, error) {
+	project := utils.GetMap(data, "project")
+	attrs := utils.GetMap(data, "object_attributes")
+	e := &ForgeEvent{
+		Repo: map[string]any{
+			"name":     utils.GetString(project, "name"),
+			"fullName": utils.GetString(project, "path_with_namespace"),
+			"url":      utils.GetString(project, "web_url"),
+		},
+		Actor: utils.FirstNonEmpty(
+			utils.GetString(data, "user.username"),
+			utils.GetString(data, "user_username"),
+			utils.GetString(data, "user.name"),
+			utils.GetString(data, "user_name"),
+		),
+	}
+
+	switch utils.GetString(data, "object_kind") {
+	case "push", "tag_push":
+		e.Event = utils.GetString(data, "object_kind")
+		e.Ref = utils.GetString(data, "ref")
+		e.URL = utils.GetString(project, "web_url")
+		for _, item := range utils.GetSlice(data, "commits") {
+			commit, _ := item.(map[string]any)
+			e.Commits = append(e.Commits, map[string]any{
+				"id":      utils.GetString(commit, "id"),
+				"message": utils.GetString(commit, "message"),
+				"author":  utils.GetString(commit, "author.name"),
+				"url":     utils.GetString(commit, "url"),
+			})
+		}
+	case "merge_request":
+		e.Event = "pull_request"
+		e.Action = gitlabAction(utils.GetString(attrs, "action"))
+		e.Title = utils.GetString(attrs, "title")
+		e.Number = utils.GetString(attrs, "iid")
+		e.URL = utils.GetString(attrs, "url")
+		e.Body = utils.GetString(attrs, "description")
+		e.Branch = utils.GetString(attrs, "target_branch")
+		e.Ref = utils.GetString(attrs, "source_branch")
+	case "issue":
+		e.Event = "issues"
+		e.Action = gitlabAction(utils.GetString(attrs, "action"))
+		e.Title = utils.GetString(attrs, "title")
+		e.Number = utils.GetString(attrs, "iid")
+		e.URL = utils.GetString(attrs, "url")
+		e.Body = utils.GetString(attrs, "description")
+	case "note":
+		e.Event = "issue_comment"
+		e.Action = "created"
+		e.Title = utils.FirstNonEmpty(utils.GetString(data, "issue.title"), utils.GetString(data, "merge_request.title"))
+		e.Number = utils.FirstNonEmpty(utils.GetString(data, "issue.iid"), utils.GetString(data, "merge_request.iid"))
+		e.URL = utils.GetString(attrs, "url")
+		e.Body = utils.GetString(attrs, "note")
+	case "release":
+		e.Event = "release"
+		e.Action = utils.GetString(data, "action")
+		e.Tag = utils.GetString(data, "tag")
+		e.Title = utils.FirstNonEmpty(utils.GetString(data, "name"), e.Tag)
+		e.URL = utils.GetString(data, "url")
+		e.Body = utils.GetString(data, "description")
+	case "pipeline":
+		e.Event = "pipeline"
+		e.Status = utils.GetString(attrs, "status")
+		e.Ref = utils.GetString(attrs, "ref")
+		e.Title = utils.FirstNonEmpty(utils.GetString(attrs, "name"), "Pipeline #"+utils.GetString(attrs, "id"))
+		e.URL = utils.FirstNonEmpty(utils.GetString(attrs, "url"), utils.GetString(project, "web_url")+"/-/pipelines/"+utils.GetString(attrs, "id"))
+		// 只在流水线结束时通知
+		switch e.Status {
+		case "success", "failed", "canceled", "skipped":
+		default:
+			return nil, nil
+		}
+	default:
+		return nil, nil
+	}
+
+	fillRefNames(e)
+	return e, nil
+}
+
+// gitlabAction 把 GitLab 的动作统一为 GitHub 风格
+func gitlabAction(action string) string {
+	switch action {
+	case "open":
+		return "opened"
+	case "close":
+		return "closed"
+	case "reopen":
+		return "reopened"
+	case "merge":
+		return "merged"
+	case "update":
+		return "updated"
+	}
+	return action
+}
+
+// fillRefNames 从 ref 中解析分支名和标签名
+func fillRefNames(e *ForgeEvent) {
+	switch {
+	case strings.HasPrefix(e.Ref, "refs/heads/"):
+		e.Branch = strings.TrimPrefix(e.Ref, "refs/heads/")
+	case strings.HasPrefix(e.Ref, "refs/tags/"):
+		e.Tag = strings.TrimPrefix(e.Ref, "refs/tags/")
+	case e.Branch == "" && e.Ref != "" && e.Event != "pull_request":
+		e.Branch = e.Ref
+	}
+}
+
+// envelope 转换为标准通知数据
+func (e *ForgeEvent) envelope(raw map[string]any) Envelope {
+	repoName := utils.FirstNonEmpty(fmt.Sprint(e.Repo["fullName"]), fmt.Sprint(e.Repo["name"]))
+	title, content := e.summary()
+
+	severity := "info"
+	status := ""
+	if e.Event == "pipeline" {
+		status = "resolved"
+		if e.Status == "failed" || e.Status == "failure" {
+			severity = "error"
+			status = "firing"
+		}
+	}
+
+	commits := make([]any, 0, len(e.Commits))
+	for _, c := range e.Commits {
+		commits = append(commits, c)
+	}
+
+	return Envelope{
+		Title:    fmt.Sprintf("[%s] %s", repoName, title),
+		Content:  content,
+		Severity: severity,
+		Status:   status,
+		Labels: map[string]string{
+			"repo":  repoName,
+			"event": e.Event,
+		},
+		URL: e.URL,
+		Raw: raw,
+		Extra: map[string]any{
+			"event":   e.Event,
+			"action":  e.Action,
+			"repo":    e.Repo,
+			"actor":   e.Actor,
+			"ref":     e.Ref,
+			"branch":  e.Branch,
+			"tag":     e.Tag,
+			"number":  e.Number,
+			"body":    e.Body,
+			"commits": commits,
+		},
+	}
+}
+
+// summary 生成事件的标题和内容
+func (e *ForgeEvent) summary() (string, string) {
+	switch e.Event {
+	case "push":
+		lines := make([]string, 0, len(e.Commits))
+		for _, c := range e.Commits {
+			id := fmt.Sprint(c["id"])
+			if len(id) > 8 {
+				id = id[:8]
+			}
+			message := strings.SplitN(fmt.Sprint(c["message"]), "\n", 2)[0]
+			lines = append(lines, fmt.Sprintf("- %s %s (%s)", id, message, c["author"]))
+		}
+		return fmt.Sprintf("%s 推送了 %d 个提交到 %s", e.Actor, len(e.Commits), e.Branch), strings.Join(lines, "\n")
+	case "tag_push":
+		return fmt.Sprintf("%s 推送了标签 %s", e.Actor, e.Tag), ""
+	case "pull_request":
+		return fmt.Sprintf("%s %s 合并请求 #%s: %s", e.Actor, e.Action, e.Number, e.Title),
+			fmt.Sprintf("%s → %s", e.Ref, e.Branch)
+	case "issues":
+		return fmt.Sprintf("%s %s Issue #%s: %s", e.Actor, e.Action, e.Number, e.Title), e.Body
+	case "issue_comment":
+		return fmt.Sprintf("%s 评论了 #%s: %s", e.Actor, e.Number, e.Title), e.Body
+	case "release":
+		return fmt.Sprintf("%s %s 版本 %s", e.Actor, e.Action, e.Title), e.Body
+	case "pipeline":
+		return fmt.Sprintf("流水线 %s %s", e.Title, e.Status), fmt.Sprintf("分支: %s", utils.FirstNonEmpty(e.Branch, e.Ref))
+	}
+	return e.Event, ""
+}
+
+// forgeTemplate 代码托管平台事件的默认模板
+func forgeTemplate(name string) config.MessageTemplate {
+	return config.MessageTemplate{
+		Name:    name,
+		Title:   "{{.title}}",
+		Content: "{{.content}}",
+		URL:     "{{.url}}",
+		Targets: "{{.targets}}",
+	}
+}
@@ -0,0 +1,502 @@
+package source
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"notify/internal/config"
+	"notify/internal/utils"
+)
+
+func init() {
+	register(&embyAdapter{})
+	register(&jellyfinAdapter{})
+	register(&arrAdapter{name: "sonarr"})
+	register(&arrAdapter{name: "radarr"})
+	register(&qbittorrentAdapter{}, "qbit")
+}
+
+// mediaEventNames 标准化媒体事件的中文名称
+var mediaEventNames = map[string]string{
+	"playback.start":               "开始播放",
+	"playback.stop":                "停止播放",
+	"playback.pause":               "暂停播放",
+	"playback.unpause":             "继续播放",
+	"library.new":                  "新增媒体",
+	"library.deleted":              "删除媒体",
+	"user.authenticated":           "用户登录",
+	"download.grab":                "开始下载",
+	"download.finished":            "下载完成",
+	"download.upgrade":             "升级完成",
+	"rename":                       "重命名",
+	"health":                       "健康检查",
+	"health.restored":              "健康恢复",
+	"application.update":           "程序更新",
+	"manual.interaction":           "需要手动处理",
+	"test":                         "测试通知",
+	"item.rate":                    "评分",
+	"item.markplayed":              "标记已播放",
+	"item.markunplayed":            "标记未播放",
+	"system.updateavail":           "有可用更新",
+	"system.serverrestartrequired": "需要重启服务",
+}
+
+// MediaItem 媒体服务器和下载工具事件的标准化模型
+type MediaItem struct {
+	Event         string // 标准化事件，如 playback.start、library.new、download.finished
+	User          string
+	ItemID        string
+	ItemName      string
+	ItemType      string // Movie、Episode、Series、Audio 等
+	SeriesName    string
+	SeasonNumber  int
+	EpisodeNumber int
+	Year          string
+	Overview      string
+	Poster        string // 海报图片地址
+	Device        string
+	Client        string
+	IP            string
+	Quality       string
+	Server        string
+	Message       string // 健康检查等事件的附加信息
+	Severity      string
+	Progress      string // 播放进度百分比
+}
+
+// episodeLabel 返回 S01E02 形式的剧集编号
+func (m *MediaItem) episodeLabel() string {
+	if m.SeasonNumber == 0 && m.EpisodeNumber == 0 {
+		return ""
+	}
+	if m.EpisodeNumber == 0 {
+		return fmt.Sprintf("S%02d", m.SeasonNumber)
+	}
+	return fmt.Sprintf("S%02dE%02d", m.SeasonNumber, m.EpisodeNumber)
+}
+
+// displayName 返回用于标题的媒体名称
+func (m *MediaItem) displayName() string {
+	if m.SeriesName != "" {
+		name := m.SeriesName
+		if label := m.episodeLabel(); label != "" {
+			name += " " + label
+		}
+		if m.ItemName != "" && m.ItemName != m.SeriesName {
+			name += " " + m.ItemName
+		}
+		return name
+	}
+	if m.ItemName != "" && m.Year != "" && m.Year != "0" {
+		return fmt.Sprintf("%s (%s)", m.ItemName, m.Year)
+	}
+	return m.ItemName
+}
+
+// envelope 转换为标准通知数据
+func (m *MediaItem) envelope(raw any) Envelope {
+	eventName := mediaEventNames[m.Event]
+	if eventName == "" {
+		eventName = m.Event
+	}
+	title := eventName
+	if name := m.displayName(); name != "" {
+		title = fmt.Sprintf("%s · %s", eventName, name)
+	}
+
+	severity := m.Severity
+	if severity == "" {
+		severity = "info"
+	}
+
+	return Envelope{
+		Title:    title,
+		Content:  utils.FirstNonEmpty(m.Message, m.Overview),
+		Severity: severity,
+		Labels: map[string]string{
+			"event":    m.Event,
+			"itemType": m.ItemType,
+		},
+		Image: m.Poster,
+		Raw:   raw,
+		Extra: map[string]any{
+			"event":         m.Event,
+			"eventName":     eventName,
+			"user":          m.User,
+			"itemId":        m.ItemID,
+			"itemName":      m.ItemName,
+			"itemType":      m.ItemType,
+			"seriesName":    m.SeriesName,
+			"seasonNumber":  m.SeasonNumber,
+			"episodeNumber": m.EpisodeNumber,
+			"episodeLabel":  m.episodeLabel(),
+			"year":          m.Year,
+			"overview":      m.Overview,
+			"poster":        m.Poster,
+			"device":        m.Device,
+			"client":        m.Client,
+			"ip":            m.IP,
+			"quality":       m.Quality,
+			"server":        m.Server,
+			"progress":      m.Progress,
+		},
+	}
+}
+
+// mediaTemplate 媒体类数据源的默认模板
+func mediaTemplate(name string) config.MessageTemplate {
+	return config.MessageTemplate{
+		Name:  name,
+		Title: "{{.title}}",
+		Content: "{{- if .seriesName}}📺 剧集: {{.seriesName}} {{.episodeLabel}}{{if ne .itemName .seriesName}} {{.itemName}}{{end}}\n" +
+			"{{- else if .itemName}}🎬 {{.itemName}}{{if and .year (ne .year \"0\")}} ({{.year}}){{end}}\n" +
+			"{{- else}}{{.content}}{{end}}" +
+			"{{- if .user}}\n👤 用户: {{.user}}{{end}}" +
+			"{{- if .device}}\n📱 设备: {{.device}}{{if .client}} ({{.client}}){{end}}{{end}}" +
+			"{{- if .ip}}\n🌐 IP: {{.ip}}{{end}}" +
+			"{{- if .progress}}\n⏱️ 播放进度: {{.progress}}{{end}}" +
+			"{{- if .quality}}\n🎞️ 质量: {{.quality}}{{end}}" +
+			"{{- if and .content (or .seriesName .itemName)}}\n{{.content}}{{end}}",
+		Image:   "{{.image}}",
+		URL:     "{{.url}}",
+		Targets: "{{.targets}}",
+	}
+}
+
+// parseMedia 按事件过滤后转换为通知数据
+func parseMedia(name string, item *MediaItem, raw any, cfg *config.SourceConfig) []map[string]any {
+	if item == nil || !eventAllowed(cfg, item.Event, item.Event) {
+		return nil
+	}
+	return []map[string]any{item.envelope(raw).Map(name)}
+}
+
+// baseURL 返回配置的媒体服务器地址，去掉末尾的斜杠
+func baseURL(cfg *config.SourceConfig, fallback string) string {
+	url := fallback
+	if cfg != nil && cfg.BaseURL != "" {
+		url = cfg.BaseURL
+	}
+	return strings.TrimSuffix(url, "/")
+}
+
+// atoi 把 JSON 中的数字或字符串转换为整数
+func atoi(s string) int {
+	n, _ := strconv.Atoi(strings.TrimSpace(s))
+	return n
+}
+
+// embyAdapter Emby webhook 适配器，支持 JSON 和带 data 字段的 multipart 表单
+type embyAdapter struct{}
+
+// Name 返回适配器名称
+func (e *embyAdapter) Name() string {
+	return "emby"
+}
+
+// DefaultTemplate 返回默认模板
+func (e *embyAdapter) DefaultTemplate() config.MessageTemplate {
+	return mediaTemplate("Emby")
+}
+
+// Parse 解析 Emby webhook
+func (e *embyAdapter) Parse(req *Request, cfg *config.SourceConfig) ([]map[string]any, error) {
+	data := req.Data
+	// multipart 表单中 data 字段是 JSON 字符串，配置了 form_json_fields 时已被解析
+	if decoded, ok := data["data"].(map[string]any); ok {
+		data = decoded
+	} else if raw, ok := data["data"].(string); ok {
+		decoded := make(map[string]any)
+		if err := json.Unmarshal([]byte(raw), &decoded); err != nil {
+			return nil, fmt.Errorf("解析 Emby data 字段失败: %w", err)
+		}
+		data = decoded
+	}
+	if utils.GetString(data, "Event") == "" {
+		return nil, fmt.Errorf("不是有效的 Emby 数据：缺少 Event 字段")
+	}
+
+	item := &MediaItem{
+		Event:         utils.GetString(data, "Event"),
+		User:          utils.GetString(data, "User.Name"),
+		ItemID:        utils.GetString(data, "Item.Id"),
+		ItemName:      utils.GetString(data, "Item.Name"),
+		ItemType:      utils.GetString(data, "Item.Type"),
+		SeriesName:    utils.GetString(data, "Item.SeriesName"),
+		SeasonNumber:  atoi(utils.GetString(data, "Item.ParentIndexNumber")),
+		EpisodeNumber: atoi(utils.GetString(data, "Item.IndexNumber")),
+		Year:          utils.GetString(data, "Item.ProductionYear"),
+		Overview:      utils.GetString(data, "Item.Overview"),
+		Device:        utils.GetString(data, "Session.DeviceName"),
+		Client:        utils.GetString(data, "Session.Client"),
+		IP:            utils.GetString(data, "Session.RemoteEndPoint"),
+		Server:        utils.GetString(data, "Server.Name"),
+	}
+	if item.ItemType != "Episode" {
+		item.SeasonNumber, item.EpisodeNumber = 0, 0
+	}
+	if item.Event == "system.notificationtest" {
+		item.Event = "test"
+		item.Message = utils.GetString(data, "Title")
+	}
+
+	// 播放进度
+	position, _ := strconv.ParseFloat(utils.GetString(data, "PlaybackInfo.PositionTicks"), 64)
+	runtime, _ := strconv.ParseFloat(utils.GetString(data, "Item.RunTimeTicks"), 64)
+	if position > 0 && runtime > 0 {
+		item.Progress = fmt.Sprintf("%.1f%%", position/runtime*100)
+	}
+
+	// 海报图片，优先使用背景图，其次使用封面图
+	if base := baseURL(cfg, utils.GetString(data, "Server.Url")); base != "" && item.ItemID != "" {
+		if tag := utils.GetString(data, "Item.BackdropImageTags.0"); tag != "" {
+			item.Poster = fmt.Sprintf("%s/emby/Items/%s/Images/Backdrop/0?tag=%s&quality=90", base, item.ItemID, tag)
+		} else if tag := utils.GetString(data, "Item.ImageTags.Primary"); tag != "" {
+			item.Poster = fmt.Sprintf("%s/emby/Items/%s/Images/Primary?tag=%s&quality=90", base, item.ItemID, tag)
+		} else if seriesID := utils.GetString(data, "Item.SeriesId"); seriesID != "" {
+			item.Poster = fmt.Sprintf("%s/emby/Items/%s/Images/Primary?quality=90", base, seriesID)
+		}
+	}
+
+	return parseMedia(e.Name(), item, data, cfg), nil
+}
+
+// jellyfinEvents Jellyfin webhook 插件的 NotificationType 与标准事件的对应关系
+var jellyfinEvents = map[string]string{
+	"PlaybackStart":         "playback.start",
+	"PlaybackStop":          "playback.stop",
+	"ItemAdded":             "library.new",
+	"ItemDeleted":           "library.deleted",
+	"AuthenticationSuccess": "user.authenticated",
+	"UserDataSaved":         "item.rate",
+	"Generic":               "test",
+}
+
+// jellyfinAdapter Jellyfin webhook 插件适配器（使用插件默认的 JSON 字段）
+type jellyfinAdapter struct{}
+
+// Name 返回适配器名称
+func (j *jellyfinAdapter) Name() string {
+	return "jellyfin"
+}
+
+// DefaultTemplate 返回默认模板
+func (j *jellyfinAdapter) DefaultTemplate() config.MessageTemplate {
+	return mediaTemplate("Jellyfin")
+}
+
+// Parse 解析 Jellyfin webhook
+func (j *jellyfinAdapter) Parse(req *Request, cfg *config.SourceConfig) ([]map[string]any, error) {
+	data := req.Data
+	notificationType := utils.GetString(data, "NotificationType")
+	if notificationType == "" {
+		return nil, fmt.Errorf("不是有效的 Jellyfin 数据：缺少 NotificationType 字段")
+	}
+
+	event, ok := jellyfinEvents[notificationType]
+	if !ok {
+		event = strings.ToLower(notificationType)
+	}
+	// 播放进度事件只在暂停状态变化时有意义
+	if notificationType == "PlaybackProgress" {
+		if utils.GetString(data, "IsPaused") != "true" {
+			return nil, nil
+		}
+		event = "playback.pause"
+	}
+
+	item := &MediaItem{
+		Event:         event,
+		User:          utils.GetString(data, "NotificationUsername"),
+		ItemID:        utils.GetString(data, "ItemId"),
+		ItemName:      utils.GetString(data, "Name"),
+		ItemType:      utils.GetString(data, "ItemType"),
+		SeriesName:    utils.GetString(data, "SeriesName"),
+		SeasonNumber:  atoi(utils.GetString(data, "SeasonNumber")),
+		EpisodeNumber: atoi(utils.GetString(data, "EpisodeNumber")),
+		Year:          utils.GetString(data, "Year"),
+		Overview:      utils.GetString(data, "Overview"),
+		Device:        utils.GetString(data, "DeviceName"),
+		Client:        utils.GetString(data, "ClientName"),
+		IP:            utils.GetString(data, "RemoteEndPoint"),
+		Server:        utils.GetString(data, "ServerName"),
+	}
+	if event == "test" {
+		item.Message = utils.FirstNonEmpty(utils.GetString(data, "Name"), utils.GetString(data, "Description"))
+		item.ItemName = ""
+	}
+
+	position, _ := strconv.ParseFloat(utils.GetString(data, "PlaybackPositionTicks"), 64)
+	runtime, _ := strconv.ParseFloat(utils.GetString(data, "RunTimeTicks"), 64)
+	if position > 0 && runtime > 0 {
+		item.Progress = fmt.Sprintf("%.1f%%", position/runtime*100)
+	}
+
+	if base := baseURL(cfg, utils.GetString(data, "ServerUrl")); base != "" && item.ItemID != "" {
+		item.Poster = fmt.Sprintf("%s/Items/%s/Images/Primary?quality=90", base, item.ItemID)
+	}
+
+	return parseMedia(j.Name(), item, data, cfg), nil
+}
+
+// arrEvents Sonarr/Radarr 的 eventType 与标准事件的对应关系
+var arrEvents = map[string]string{
+	"Grab":                      "download.grab",
+	"Download":                  "download.finished",
+	"Rename":                    "rename",
+	"SeriesAdd":                 "library.new",
+	"MovieAdded":                "library.new",
+	"SeriesDelete":              "library.deleted",
+	"MovieDelete":               "library.deleted",
+	"EpisodeFileDelete":         "library.deleted",
+	"MovieFileDelete":           "library.deleted",
+	"Health":                    "health",
+	"HealthRestored":            "health.restored",
+	"ApplicationUpdate":         "application.update",
+	"ManualInteractionRequired": "manual.interaction",
+	"Test":                      "test",
+}
+
+// arrAdapter Sonarr 和 Radarr webhook 适配器
+type arrAdapter struct {
+	name string
+}
+
+// Name 返回适配器名称
+func (a *arrAdapter) Name() string {
+	return a.name
+}
+
+// DefaultTemplate 返回默认模板
+func (a *arrAdapter) DefaultTemplate() config.MessageTemplate {
+	return mediaTemplate(strings.ToUpper(a.name[:1]) + a.name[1:])
+}
+
+// Parse 解析 Sonarr/Radarr webhook
+func (a *arrAdapter) Parse(req *Request, cfg *config.SourceConfig) ([]map[string]any, error) {
+	data := req.Data
+	eventType := utils.GetString(data, "eventType")
+	if eventType == "" {
+		return nil, fmt.Errorf("不是有效的 %s 数据：缺少 eventType 字段", a.name)
+	}
+
+	event, ok := arrEvents[eventType]
+	if !ok {
+		event = strings.ToLower(eventType)
+	}
+	if event == "download.finished" && utils.GetString(data, "isUpgrade") == "true" {
+		event = "download.upgrade"
+	}
+
+	item := &MediaItem{
+		Event:   event,
+		Server:  utils.GetString(data, "instanceName"),
+		Quality: utils.FirstNonEmpty(utils.GetString(data, "release.quality"), utils.GetString(data, "episodeFile.quality"), utils.GetString(data, "movieFile.quality")),
+		Message: utils.GetString(data, "message"),
+	}
+
+	var images []any
+	if a.name == "sonarr" {
+		item.ItemType = "Episode"
+		item.SeriesName = utils.GetString(data, "series.title")
+		item.Year = utils.GetString(data, "series.year")
+		item.ItemID = utils.GetString(data, "series.id")
+		item.Overview = utils.GetString(data, "series.overview")
+		item.SeasonNumber = atoi(utils.GetString(data, "episodes.0.seasonNumber"))
+		item.EpisodeNumber = atoi(utils.GetString(data, "episodes.0.episodeNumber"))
+		item.ItemName = utils.GetString(data, "episodes.0.title")
+		if item.ItemName == "" {
+			item.ItemType = "Series"
+		}
+		images = utils.GetSlice(data, "series.images")
+	} else {
+		item.ItemType = "Movie"
+		item.ItemName = utils.FirstNonEmpty(utils.GetString(data, "movie.title"), utils.GetString(data, "remoteMovie.title"))
+		item.Year = utils.FirstNonEmpty(utils.GetString(data, "movie.year"), utils.GetString(data, "remoteMovie.year"))
+		item.ItemID = utils.GetString(data, "movie.id")
+		item.Overview = utils.GetString(data, "movie.overview")
+		images = utils.GetSlice(data, "movie.images")
+	}
+
+	// 海报图片，优先使用远程地址
+	for _, image := range images {
+		img, _ := image.(map[string]any)
+		if utils.GetString(img, "coverType") == "poster" {
+			item.Poster = utils.FirstNonEmpty(utils.GetString(img, "remoteUrl"), utils.GetString(img, "url"))
+			break
+		}
+	}
+
+	if event == "health" {
+		switch strings.ToLower(utils.GetString(data, "level")) {
+		case "error":
+			item.Severity = "error"
+		default:
+			item.Severity = "warning"
+		}
+	}
+
+	return parseMedia(a.name, item, data, cfg), nil
+}
+
+// qbittorrentAdapter qBittorrent 下载完成通知适配器
+// qBittorrent 没有原生 webhook，需要在“Torrent 完成时运行外部程序”中用 curl 提交表单，参数名见 README
+type qbittorrentAdapter struct{}
+
+// Name 返回适配器名称
+func (q *qbittorrentAdapter) Name() string {
+	return "qbittorrent"
+}
+
+// DefaultTemplate 返回默认模板
+func (q *qbittorrentAdapter) DefaultTemplate() config.MessageTemplate {
+	tpl := mediaTemplate("qBittorrent")
+	tpl.Content += "{{- if .category}}\n📂 分类: {{.category}}{{end}}" +
+		"{{- if .size}}\n💾 大小: {{.size}}{{end}}" +
+		"{{- if .path}}\n📁 路径: {{.path}}{{end}}"
+	return tpl
+}
+
+// Parse 解析 qBittorrent 提交的表单或 JSON
+func (q *qbittorrentAdapter) Parse(req *Request, cfg *config.SourceConfig) ([]map[string]any, error) {
+	data := req.Data
+	name := utils.GetString(data, "name")
+	if name == "" {
+		return nil, fmt.Errorf("不是有效的 qBittorrent 数据：缺少 name 字段")
+	}
+
+	item := &MediaItem{
+		Event:    utils.FirstNonEmpty(utils.GetString(data, "event"), "download.finished"),
+		ItemName: name,
+		ItemType: "Torrent",
+	}
+	if !eventAllowed(cfg, item.Event, item.Event) {
+		return nil, nil
+	}
+
+	envelope := item.envelope(data)
+	envelope.Extra["category"] = utils.GetString(data, "category")
+	envelope.Extra["tags"] = utils.GetString(data, "tags")
+	envelope.Extra["path"] = utils.FirstNonEmpty(utils.GetString(data, "content_path"), utils.GetString(data, "path"), utils.GetString(data, "save_path"))
+	envelope.Extra["tracker"] = utils.GetString(data, "tracker")
+	envelope.Extra["hash"] = utils.GetString(data, "hash")
+	envelope.Extra["size"] = formatBytes(utils.GetString(data, "size"))
+	return []map[string]any{envelope.Map(q.Name())}, nil
+}
+
+// formatBytes 把字节数格式化为易读的大小，无法解析时原样返回
+func formatBytes(s string) string {
+	size, err := strconv.ParseFloat(s, 64)
+	if err != nil || size <= 0 {
+		return s
+	}
+	units := []string{"B", "KB", "MB", "GB", "TB"}
+	i := 0
+	for size >= 1024 && i < len(units)-1 {
+		size /= 1024
+		i++
+	}
+	return fmt.Sprintf("%.2f %s", size, units[i])
+}
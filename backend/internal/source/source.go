@@ -0,0 +1,237 @@
+package source
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+
+	"notify/internal/config"
+)
+
+// BuiltinTemplatePrefix 内置模板ID前缀，如 builtin:alertmanager
+const BuiltinTemplatePrefix = "builtin:"
+
+// ErrUnauthorized webhook 签名或令牌校验失败
+var ErrUnauthorized = errors.New("webhook 签名校验失败")
+
+// Request 入站通知请求
+type Request struct {
+	Method     string
+	Headers    http.Header
+	Query      url.Values
+	RemoteAddr string         // 客户端地址
+	Body       []byte         // 原始请求体
+	Data       map[string]any // 已解析的请求数据
+}
+
+// credentialHeaders 携带应用令牌或 webhook 密钥的请求头，不放入模板数据
+var credentialHeaders = []string{"Authorization", "Proxy-Authorization", "Cookie", "X-Gitlab-Token", "X-Hub-Signature", "X-Gitea-Signature"}
+
+// credentialQuery 携带应用令牌的查询参数
+const credentialQuery = "token"
+
+// IsCredentialHeader 判断请求头是否携带凭证，X-Hub-Signature-256 等带后缀的签名头同样视为凭证
+func IsCredentialHeader(name string) bool {
+	for _, header := range credentialHeaders {
+		if strings.EqualFold(name, header) || strings.HasPrefix(strings.ToLower(name), strings.ToLower(header)+"-") {
+			return true
+		}
+	}
+	return false
+}
+
+// IsCredentialQuery 判断查询参数是否携带凭证
+func IsCredentialQuery(name string) bool {
+	return strings.EqualFold(name, credentialQuery)
+}
+
+// StripCredentials 删除通知数据中的凭证，用于保存到数据目录之前
+// GET 请求的 ?token= 同时出现在请求数据中；request 下的请求头和查询参数替换为过滤后的副本，不修改共享的原数据
+func StripCredentials(data map[string]any) {
+	for key, value := range data {
+		if token, ok := value.(string); ok && IsCredentialQuery(key) && strings.HasPrefix(token, "Bearer ") {
+			delete(data, key)
+		}
+	}
+	request, ok := data["request"].(map[string]any)
+	if !ok {
+		return
+	}
+	stripped := make(map[string]any, len(request))
+	for key, value := range request {
+		stripped[key] = value
+	}
+	if headers, ok := request["headers"].(map[string]any); ok {
+		stripped["headers"] = filterKeys(headers, IsCredentialHeader)
+	}
+	if query, ok := request["query"].(map[string]any); ok {
+		stripped["query"] = filterKeys(query, IsCredentialQuery)
+	}
+	data["request"] = stripped
+}
+
+// filterKeys 返回删除了 drop 为 true 的键之后的副本
+func filterKeys(m map[string]any, drop func(string) bool) map[string]any {
+	result := make(map[string]any, len(m))
+	for key, value := range m {
+		if !drop(key) {
+			result[key] = value
+		}
+	}
+	return result
+}
+
+// Context 返回模板中 .request 的内容，请求头和查询参数只取第一个值，不包含令牌和签名等凭证
+func (r *Request) Context() map[string]any {
+	headers := make(map[string]any, len(r.Headers))
+	for key, values := range r.Headers {
+		if len(values) > 0 && !IsCredentialHeader(key) {
+			headers[key] = values[0]
+		}
+	}
+	query := make(map[string]any, len(r.Query))
+	for key, values := range r.Query {
+		if len(values) > 0 && !IsCredentialQuery(key) {
+			query[key] = values[0]
+		}
+	}
+	return map[string]any{
+		"method":      r.Method,
+		"headers":     headers,
+		"query":       query,
+		"remoteAddr":  r.RemoteAddr,
+		"contentType": r.Headers.Get("Content-Type"),
+		"body":        string(r.Body),
+	}
+}
+
+// Adapter 入站数据适配器，把各平台的原始 webhook 转换为标准化的通知数据
+type Adapter interface {
+	// Name 返回适配器名称
+	Name() string
+
+	// Parse 解析请求，返回一条或多条待发送的通知数据
+	Parse(req *Request, cfg *config.SourceConfig) ([]map[string]any, error)
+
+	// DefaultTemplate 返回适配器的默认模板
+	DefaultTemplate() config.MessageTemplate
+}
+
+// adapters 已注册的适配器
+var adapters = map[string]Adapter{}
+
+// register 注册适配器
+func register(adapter Adapter, aliases ...string) {
+	adapters[adapter.Name()] = adapter
+	for _, alias := range aliases {
+		adapters[alias] = adapter
+	}
+}
+
+// Get 根据名称获取适配器，名称不区分大小写
+func Get(name string) (Adapter, bool) {
+	adapter, ok := adapters[strings.ToLower(strings.TrimSpace(name))]
+	return adapter, ok
+}
+
+// Names 返回所有已注册的适配器名称（含别名）
+func Names() []string {
+	names := make([]string, 0, len(adapters))
+	for name := range adapters {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// BuiltinTemplate 根据内置模板ID获取适配器的默认模板
+func BuiltinTemplate(templateID string) (config.MessageTemplate, error) {
+	name := strings.TrimPrefix(templateID, BuiltinTemplatePrefix)
+	adapter, ok := Get(name)
+	if !ok {
+		return config.MessageTemplate{}, fmt.Errorf("内置模板 '%s' 不存在", templateID)
+	}
+	tpl := adapter.DefaultTemplate()
+	tpl.ID = BuiltinTemplatePrefix + adapter.Name()
+	return tpl, nil
+}
+
+// Envelope 标准化的通知数据
+type Envelope struct {
+	Title    string
+	Content  string
+	Severity string            // info, notice, warning, error, critical
+	Status   string            // firing, resolved
+	Labels   map[string]string // 标签
+	URL      string
+	Image    string
+	Raw      any            // 原始请求数据
+	Extra    map[string]any // 适配器特有的字段
+}
+
+// Map 转换为模板数据，标准字段放在顶层，原始数据放在 raw 下
+func (e Envelope) Map(source string) map[string]any {
+	labels := make(map[string]any, len(e.Labels))
+	for k, v := range e.Labels {
+		labels[k] = v
+	}
+
+	data := make(map[string]any, len(e.Extra)+9)
+	for k, v := range e.Extra {
+		data[k] = v
+	}
+	data["source"] = source
+	data["title"] = e.Title
+	data["content"] = e.Content
+	data["severity"] = e.Severity
+	data["status"] = e.Status
+	data["labels"] = labels
+	data["url"] = e.URL
+	data["image"] = e.Image
+	data["raw"] = e.Raw
+	return data
+}
+
+// alertTemplate 告警类数据源通用的默认模板
+func alertTemplate(name string) config.MessageTemplate {
+	return config.MessageTemplate{
+		Name:    name,
+		Title:   `{{if eq .status "resolved"}}✅ [已恢复]{{else}}🔥 [告警]{{end}} {{.title}}`,
+		Content: "{{.content}}\n{{- if .severity}}\n级别: {{.severity}}{{end}}",
+		Image:   "{{.image}}",
+		URL:     "{{.url}}",
+		Targets: "{{.targets}}",
+	}
+}
+
+// NormalizeSeverity 把各平台的级别统一为 info、notice、warning、error、critical
+func NormalizeSeverity(severity string) string {
+	switch strings.ToLower(strings.TrimSpace(severity)) {
+	case "":
+		return ""
+	case "critical", "crit", "disaster", "fatal", "emergency", "emerg", "alert", "p1":
+		return "critical"
+	case "error", "err", "high", "major", "p2":
+		return "error"
+	case "warning", "warn", "average", "minor", "p3":
+		return "warning"
+	case "notice":
+		return "notice"
+	default:
+		return "info"
+	}
+}
+
+// stringMap 把 map[string]any 转换为 map[string]string
+func stringMap(m map[string]any) map[string]string {
+	result := make(map[string]string, len(m))
+	for k, v := range m {
+		if v != nil {
+			result[k] = fmt.Sprint(v)
+		}
+	}
+	return result
+}
@@ -0,0 +1,78 @@
+package source
+
+import (
+	"fmt"
+
+	"notify/internal/config"
+	"notify/internal/utils"
+)
+
+func init() {
+	register(&uptimeKumaAdapter{}, "uptime-kuma", "kuma")
+}
+
+// uptimeKumaAdapter Uptime Kuma webhook 适配器
+// heartbeat.status: 0 宕机, 1 正常, 2 等待中, 3 维护中
+type uptimeKumaAdapter struct{}
+
+// Name 返回适配器名称
+func (u *uptimeKumaAdapter) Name() string {
+	return "uptimekuma"
+}
+
+// DefaultTemplate 返回默认模板
+func (u *uptimeKumaAdapter) DefaultTemplate() config.MessageTemplate {
+	return alertTemplate("Uptime Kuma")
+}
+
+// Parse 解析 Uptime Kuma webhook
+func (u *uptimeKumaAdapter) Parse(req *Request, cfg *config.SourceConfig) ([]map[string]any, error) {
+	msg := utils.GetString(req.Data, "msg")
+	monitor := utils.GetMap(req.Data, "monitor")
+	heartbeat := utils.GetMap(req.Data, "heartbeat")
+	if monitor == nil && heartbeat == nil && msg == "" {
+		return nil, fmt.Errorf("不是有效的 Uptime Kuma 数据：缺少 monitor、heartbeat 和 msg 字段")
+	}
+
+	name := utils.FirstNonEmpty(utils.GetString(monitor, "name"), "Uptime Kuma")
+	envelope := Envelope{
+		Title:    name,
+		Content:  utils.FirstNonEmpty(utils.GetString(heartbeat, "msg"), msg),
+		Severity: "info",
+		Status:   "resolved",
+		Labels: map[string]string{
+			"monitor": name,
+			"type":    utils.GetString(monitor, "type"),
+		},
+		URL: utils.FirstNonEmpty(utils.GetString(monitor, "url"), utils.GetString(monitor, "hostname")),
+		Raw: req.Data,
+		Extra: map[string]any{
+			"monitor":   monitor,
+			"heartbeat": heartbeat,
+			"ping":      utils.GetString(heartbeat, "ping"),
+			"time":      utils.GetString(heartbeat, "time"),
+		},
+	}
+
+	switch utils.GetString(heartbeat, "status") {
+	case "0":
+		envelope.Status = "firing"
+		envelope.Severity = "error"
+		envelope.Title = name + " 宕机"
+	case "1":
+		envelope.Title = name + " 恢复正常"
+	case "2":
+		envelope.Status = "firing"
+		envelope.Severity = "warning"
+		envelope.Title = name + " 等待重试"
+	case "3":
+		envelope.Severity = "notice"
+		envelope.Title = name + " 维护中"
+	default:
+		// 测试通知等没有心跳信息的消息
+		envelope.Title = utils.FirstNonEmpty(msg, name)
+		envelope.Content = msg
+	}
+
+	return []map[string]any{envelope.Map(u.Name())}, nil
+}
@@ -0,0 +1,87 @@
+package source
+
+import (
+	"fmt"
+	"strings"
+
+	"notify/internal/config"
+	"notify/internal/utils"
+)
+
+func init() {
+	register(&zabbixAdapter{})
+}
+
+// zabbixAdapter Zabbix webhook 媒介适配器
+// Zabbix 的 webhook 参数由用户在媒介类型中自定义，这里兼容常用的参数名：
+// subject/title、message、severity、status、host、trigger、event_id、event_value、url
+type zabbixAdapter struct{}
+
+// Name 返回适配器名称
+func (z *zabbixAdapter) Name() string {
+	return "zabbix"
+}
+
+// DefaultTemplate 返回默认模板
+func (z *zabbixAdapter) DefaultTemplate() config.MessageTemplate {
+	return alertTemplate("Zabbix")
+}
+
+// Parse 解析 Zabbix webhook
+func (z *zabbixAdapter) Parse(req *Request, cfg *config.SourceConfig) ([]map[string]any, error) {
+	data := req.Data
+	title := utils.FirstNonEmpty(
+		utils.GetString(data, "subject"),
+		utils.GetString(data, "title"),
+		utils.GetString(data, "trigger"),
+		utils.GetString(data, "trigger_name"),
+	)
+	content := utils.FirstNonEmpty(utils.GetString(data, "message"), utils.GetString(data, "content"))
+	if title == "" && content == "" {
+		return nil, fmt.Errorf("不是有效的 Zabbix 数据：缺少 subject 和 message 字段")
+	}
+
+	// event_value: 1 问题, 0 恢复；status: PROBLEM, RESOLVED, OK
+	status := "firing"
+	switch strings.ToUpper(utils.FirstNonEmpty(utils.GetString(data, "status"), utils.GetString(data, "event_status"))) {
+	case "RESOLVED", "OK":
+		status = "resolved"
+	}
+	if utils.GetString(data, "event_value") == "0" {
+		status = "resolved"
+	}
+
+	host := utils.FirstNonEmpty(utils.GetString(data, "host"), utils.GetString(data, "host_name"))
+	envelope := Envelope{
+		Title:    utils.FirstNonEmpty(title, content),
+		Content:  content,
+		Severity: zabbixSeverity(utils.FirstNonEmpty(utils.GetString(data, "severity"), utils.GetString(data, "event_severity"))),
+		Status:   status,
+		Labels: map[string]string{
+			"host":    host,
+			"trigger": utils.FirstNonEmpty(utils.GetString(data, "trigger"), utils.GetString(data, "trigger_name")),
+		},
+		URL: utils.FirstNonEmpty(utils.GetString(data, "url"), utils.GetString(data, "event_url")),
+		Raw: data,
+		Extra: map[string]any{
+			"host":    host,
+			"eventId": utils.FirstNonEmpty(utils.GetString(data, "event_id"), utils.GetString(data, "eventid")),
+		},
+	}
+	return []map[string]any{envelope.Map(z.Name())}, nil
+}
+
+// zabbixSeverity 转换 Zabbix 的级别名称或数字级别 (0-5)
+func zabbixSeverity(severity string) string {
+	switch strings.ToLower(strings.TrimSpace(severity)) {
+	case "0", "not classified", "1", "information":
+		return "info"
+	case "2", "warning":
+		return "warning"
+	case "3", "average", "4", "high":
+		return "error"
+	case "5", "disaster":
+		return "critical"
+	}
+	return NormalizeSeverity(severity)
+}
@@ -0,0 +1,74 @@
+package store
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+// Store 基于JSON文件的持久化存储，每个名称对应数据目录下的一个文件
+type Store struct {
+	dir string
+	mu  sync.Mutex
+}
+
+// New 创建持久化存储，数据目录不存在时自动创建
+func New(dir string) (*Store, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, fmt.Errorf("创建数据目录失败: %w", err)
+	}
+	return &Store{dir: dir}, nil
+}
+
+// Dir 返回数据目录
+func (s *Store) Dir() string {
+	return s.dir
+}
+
+// path 返回名称对应的文件路径
+func (s *Store) path(name string) string {
+	return filepath.Join(s.dir, name+".json")
+}
+
+// Load 读取数据到 v，文件不存在时保持 v 不变
+func (s *Store) Load(name string, v interface{}) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	data, err := os.ReadFile(s.path(name))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return fmt.Errorf("读取数据文件 %s 失败: %w", name, err)
+	}
+	if len(data) == 0 {
+		return nil
+	}
+	if err := json.Unmarshal(data, v); err != nil {
+		return fmt.Errorf("解析数据文件 %s 失败: %w", name, err)
+	}
+	return nil
+}
+
+// Save 保存数据，先写临时文件再重命名，避免写到一半时进程退出导致文件损坏
+func (s *Store) Save(name string, v interface{}) error {
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return fmt.Errorf("序列化数据 %s 失败: %w", name, err)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	tmpFile := s.path(name) + ".tmp"
+	if err := os.WriteFile(tmpFile, data, 0644); err != nil {
+		return fmt.Errorf("写入数据文件 %s 失败: %w", name, err)
+	}
+	if err := os.Rename(tmpFile, s.path(name)); err != nil {
+		return fmt.Errorf("写入数据文件 %s 失败: %w", name, err)
+	}
+	return nil
+}
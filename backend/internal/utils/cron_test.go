@@ -0,0 +1,97 @@
+package utils
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestCronNext(t *testing.T) {
+	shanghai, err := time.LoadLocation("Asia/Shanghai")
+	if err != nil {
+		t.Skip("缺少时区数据:", err)
+	}
+
+	at := func(year int, month time.Month, day, hour, minute int) time.Time {
+		return time.Date(year, month, day, hour, minute, 0, 0, time.UTC)
+	}
+	tests := []struct {
+		name  string
+		expr  string
+		after time.Time
+		want  time.Time
+	}{
+		{name: "每分钟", expr: "* * * * *", after: at(2026, 10, 19, 10, 7), want: at(2026, 10, 19, 10, 8)},
+		{name: "秒数向后取整", expr: "* * * * *", after: at(2026, 10, 19, 10, 7).Add(30 * time.Second), want: at(2026, 10, 19, 10, 8)},
+		{name: "不包含当前时间", expr: "0 10 * * *", after: at(2026, 10, 19, 10, 0), want: at(2026, 10, 20, 10, 0)},
+		{name: "步长", expr: "*/15 * * * *", after: at(2026, 10, 19, 10, 7), want: at(2026, 10, 19, 10, 15)},
+		{name: "步长跨小时", expr: "*/15 * * * *", after: at(2026, 10, 19, 10, 50), want: at(2026, 10, 19, 11, 0)},
+		{name: "起始值加步长", expr: "5/20 * * * *", after: at(2026, 10, 19, 10, 30), want: at(2026, 10, 19, 10, 45)},
+		{name: "范围加步长", expr: "0 9-17/2 * * *", after: at(2026, 10, 19, 10, 0), want: at(2026, 10, 19, 11, 0)},
+		{name: "范围加步长到第二天", expr: "0 9-17/2 * * *", after: at(2026, 10, 19, 17, 0), want: at(2026, 10, 20, 9, 0)},
+		{name: "列表", expr: "0 8,12,18 * * *", after: at(2026, 10, 19, 12, 30), want: at(2026, 10, 19, 18, 0)},
+		{name: "每月第一天", expr: "0 0 1 * *", after: at(2026, 10, 19, 0, 0), want: at(2026, 11, 1, 0, 0)},
+		{name: "跳过没有该日期的月份", expr: "0 0 31 * *", after: at(2026, 10, 31, 0, 0), want: at(2026, 12, 31, 0, 0)},
+		{name: "闰年", expr: "0 0 29 2 *", after: at(2026, 3, 1, 0, 0), want: at(2028, 2, 29, 0, 0)},
+		{name: "工作日", expr: "0 8 * * mon-fri", after: at(2026, 10, 23, 9, 0), want: at(2026, 10, 26, 8, 0)},
+		{name: "星期 7 表示星期日", expr: "0 0 * * 7", after: at(2026, 10, 19, 0, 0), want: at(2026, 10, 25, 0, 0)},
+		{name: "星期名称和数字混用", expr: "0 0 * * SAT,0", after: at(2026, 10, 24, 0, 0), want: at(2026, 10, 25, 0, 0)},
+		{name: "日和周同时限制时先满足星期", expr: "0 0 13 * fri", after: at(2026, 10, 19, 0, 0), want: at(2026, 10, 23, 0, 0)},
+		{name: "日和周同时限制时先满足日期", expr: "0 0 1 * mon", after: at(2026, 10, 27, 0, 0), want: at(2026, 11, 1, 0, 0)},
+		{name: "日期限制且星期为问号", expr: "0 0 15 * ?", after: at(2026, 10, 19, 0, 0), want: at(2026, 11, 15, 0, 0)},
+		{name: "星期限制且日期为问号", expr: "0 0 ? * wed", after: at(2026, 10, 19, 0, 0), want: at(2026, 10, 21, 0, 0)},
+		{name: "月份名称", expr: "0 0 1 jan,jul *", after: at(2026, 10, 19, 0, 0), want: at(2027, 1, 1, 0, 0)},
+		{name: "@daily", expr: "@daily", after: at(2026, 10, 19, 10, 0), want: at(2026, 10, 20, 0, 0)},
+		{name: "@hourly", expr: "@hourly", after: at(2026, 10, 19, 10, 0), want: at(2026, 10, 19, 11, 0)},
+		{name: "@weekly", expr: "@WEEKLY", after: at(2026, 10, 19, 10, 0), want: at(2026, 10, 25, 0, 0)},
+		{name: "指定时区", expr: "CRON_TZ=Asia/Shanghai 0 9 * * *", after: at(2026, 10, 19, 0, 0), want: time.Date(2026, 10, 19, 9, 0, 0, 0, shanghai)},
+		{name: "指定时区的星期", expr: "TZ=Asia/Shanghai 0 1 * * tue", after: at(2026, 10, 19, 12, 0), want: time.Date(2026, 10, 20, 1, 0, 0, 0, shanghai)},
+		{name: "指定时区的预定义表达式", expr: "CRON_TZ=Asia/Shanghai @daily", after: at(2026, 10, 19, 17, 0), want: time.Date(2026, 10, 21, 0, 0, 0, 0, shanghai)},
+		{name: "永远不会触发", expr: "0 0 30 2 *", after: at(2026, 10, 19, 0, 0)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			expr := tt.expr
+			if !strings.Contains(expr, "TZ=") {
+				expr = "CRON_TZ=UTC " + expr
+			}
+			schedule, err := ParseCron(expr)
+			if err != nil {
+				t.Fatalf("ParseCron(%q) error = %v", expr, err)
+			}
+			got := schedule.Next(tt.after)
+			if !got.Equal(tt.want) {
+				t.Errorf("Next(%v) = %v, want %v", tt.after, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseCronInvalid(t *testing.T) {
+	tests := []string{
+		"",
+		"* * * *",
+		"* * * * * *",
+		"60 * * * *",
+		"* 24 * * *",
+		"0 0 0 * *",
+		"0 0 * 13 *",
+		"0 0 * * 8",
+		"*/0 * * * *",
+		"*/x * * * *",
+		"5-1 * * * *",
+		"0 0 * * funday",
+		"0 0 1 foo *",
+		"@every 5m",
+		"CRON_TZ=Nowhere/Invalid * * * * *",
+	}
+
+	for _, expr := range tests {
+		t.Run(expr, func(t *testing.T) {
+			if _, err := ParseCron(expr); err == nil {
+				t.Errorf("ParseCron(%q) 应该返回错误", expr)
+			}
+		})
+	}
+}
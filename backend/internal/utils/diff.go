@@ -0,0 +1,159 @@
+package utils
+
+import (
+	"fmt"
+	"strings"
+)
+
+// diffContextLines 统一diff格式中每个变更块前后保留的上下文行数
+const diffContextLines = 3
+
+// diffOp 行级差异操作
+type diffOp struct {
+	kind byte // ' ' 相同, '-' 删除, '+' 新增
+	line string
+	aIdx int // 在旧文本中的行号（从0开始）
+	bIdx int // 在新文本中的行号（从0开始）
+}
+
+// UnifiedDiff 生成两段文本的统一diff格式差异，文本相同时返回空字符串
+func UnifiedDiff(fromName, toName, from, to string) string {
+	a := splitLines(from)
+	b := splitLines(to)
+	ops := diffLines(a, b)
+
+	changed := false
+	for _, op := range ops {
+		if op.kind != ' ' {
+			changed = true
+			break
+		}
+	}
+	if !changed {
+		return ""
+	}
+
+	var out strings.Builder
+	out.WriteString(fmt.Sprintf("--- %s\n+++ %s\n", fromName, toName))
+
+	// 按上下文行数把变更聚合成块
+	for i := 0; i < len(ops); {
+		if ops[i].kind == ' ' {
+			i++
+			continue
+		}
+		start := i - diffContextLines
+		if start < 0 {
+			start = 0
+		}
+		end := i
+		for end < len(ops) {
+			if ops[end].kind != ' ' {
+				end++
+				continue
+			}
+			// 向后查找，若在两倍上下文范围内还有变更，则并入同一块
+			next := end
+			for next < len(ops) && ops[next].kind == ' ' && next-end < 2*diffContextLines {
+				next++
+			}
+			if next < len(ops) && ops[next].kind != ' ' {
+				end = next
+				continue
+			}
+			break
+		}
+		stop := end + diffContextLines
+		if stop > len(ops) {
+			stop = len(ops)
+		}
+		writeHunk(&out, ops[start:stop])
+		i = stop
+	}
+	return out.String()
+}
+
+// writeHunk 输出一个差异块
+func writeHunk(out *strings.Builder, ops []diffOp) {
+	aStart, bStart := -1, -1
+	aCount, bCount := 0, 0
+	for _, op := range ops {
+		if op.kind != '+' {
+			if aStart < 0 {
+				aStart = op.aIdx
+			}
+			aCount++
+		}
+		if op.kind != '-' {
+			if bStart < 0 {
+				bStart = op.bIdx
+			}
+			bCount++
+		}
+	}
+	// 统一diff中行号从1开始，空范围时使用前一行的行号
+	if aStart < 0 {
+		aStart = ops[0].aIdx - 1
+	}
+	if bStart < 0 {
+		bStart = ops[0].bIdx - 1
+	}
+	out.WriteString(fmt.Sprintf("@@ -%d,%d +%d,%d @@\n", aStart+1, aCount, bStart+1, bCount))
+	for _, op := range ops {
+		out.WriteByte(op.kind)
+		out.WriteString(op.line)
+		out.WriteByte('\n')
+	}
+}
+
+// diffLines 基于最长公共子序列计算行级差异
+func diffLines(a, b []string) []diffOp {
+	n, m := len(a), len(b)
+	lcs := make([][]int, n+1)
+	for i := range lcs {
+		lcs[i] = make([]int, m+1)
+	}
+	for i := n - 1; i >= 0; i-- {
+		for j := m - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else if lcs[i+1][j] >= lcs[i][j+1] {
+				lcs[i][j] = lcs[i+1][j]
+			} else {
+				lcs[i][j] = lcs[i][j+1]
+			}
+		}
+	}
+
+	ops := make([]diffOp, 0, n+m)
+	i, j := 0, 0
+	for i < n && j < m {
+		switch {
+		case a[i] == b[j]:
+			ops = append(ops, diffOp{kind: ' ', line: a[i], aIdx: i, bIdx: j})
+			i++
+			j++
+		case lcs[i+1][j] >= lcs[i][j+1]:
+			ops = append(ops, diffOp{kind: '-', line: a[i], aIdx: i, bIdx: j})
+			i++
+		default:
+			ops = append(ops, diffOp{kind: '+', line: b[j], aIdx: i, bIdx: j})
+			j++
+		}
+	}
+	for ; i < n; i++ {
+		ops = append(ops, diffOp{kind: '-', line: a[i], aIdx: i, bIdx: j})
+	}
+	for ; j < m; j++ {
+		ops = append(ops, diffOp{kind: '+', line: b[j], aIdx: i, bIdx: j})
+	}
+	return ops
+}
+
+// splitLines 按行拆分文本，忽略末尾换行
+func splitLines(s string) []string {
+	if s == "" {
+		return nil
+	}
+	return strings.Split(strings.TrimSuffix(s, "\n"), "\n")
+}
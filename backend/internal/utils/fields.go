@@ -0,0 +1,72 @@
+package utils
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// LookupField 按点分路径读取嵌套字段，支持 map 和数组下标，如 fields.hostname、alerts.0.status
+func LookupField(data map[string]any, path string) (any, bool) {
+	var current any = data
+	for _, key := range strings.Split(path, ".") {
+		switch node := current.(type) {
+		case map[string]any:
+			v, ok := node[key]
+			if !ok {
+				return nil, false
+			}
+			current = v
+		case []any:
+			index, err := strconv.Atoi(key)
+			if err != nil || index < 0 || index >= len(node) {
+				return nil, false
+			}
+			current = node[index]
+		default:
+			return nil, false
+		}
+	}
+	return current, true
+}
+
+// GetString 按点分路径读取字段并转换为字符串，字段不存在时返回空字符串
+func GetString(data map[string]any, path string) string {
+	v, ok := LookupField(data, path)
+	if !ok || v == nil {
+		return ""
+	}
+	switch value := v.(type) {
+	case string:
+		return value
+	case float64:
+		// JSON 数字默认解析为 float64，整数时去掉小数部分
+		return strconv.FormatFloat(value, 'f', -1, 64)
+	default:
+		return fmt.Sprint(value)
+	}
+}
+
+// FirstNonEmpty 返回第一个非空字符串，常用于从多个候选字段中取值
+func FirstNonEmpty(values ...string) string {
+	for _, v := range values {
+		if v != "" {
+			return v
+		}
+	}
+	return ""
+}
+
+// GetMap 按点分路径读取对象字段，字段不存在或类型不匹配时返回 nil
+func GetMap(data map[string]any, path string) map[string]any {
+	v, _ := LookupField(data, path)
+	m, _ := v.(map[string]any)
+	return m
+}
+
+// GetSlice 按点分路径读取数组字段，字段不存在或类型不匹配时返回 nil
+func GetSlice(data map[string]any, path string) []any {
+	v, _ := LookupField(data, path)
+	s, _ := v.([]any)
+	return s
+}
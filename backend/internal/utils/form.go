@@ -0,0 +1,154 @@
+package utils
+
+import (
+	"encoding/json"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// DecodeForm 把表单字段解析为嵌套结构
+// 支持 a[b][c]=v 和 a.b.c=v 形式的嵌套字段，a[]=v 追加到列表，a[0]=v 按下标组成列表，
+// 重复的字段保留为列表，jsonFields 中的顶层字段按 JSON 解析（解析失败时保留原字符串）
+func DecodeForm(values map[string][]string, jsonFields []string) map[string]any {
+	keys := make([]string, 0, len(values))
+	for key := range values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	root := make(map[string]any)
+	for _, key := range keys {
+		vals := values[key]
+		if len(vals) == 0 {
+			continue
+		}
+		path := splitFormKey(key)
+		if strings.Contains(key, "[]") {
+			// 带 [] 的字段每个值单独追加
+			for _, v := range vals {
+				setFormValue(root, path, []string{v})
+			}
+			continue
+		}
+		setFormValue(root, path, vals)
+	}
+
+	for key, value := range root {
+		root[key] = normalizeFormArrays(value)
+	}
+	for _, field := range jsonFields {
+		str, ok := root[field].(string)
+		if !ok {
+			continue
+		}
+		var decoded any
+		if err := json.Unmarshal([]byte(str), &decoded); err == nil {
+			root[field] = decoded
+		}
+	}
+	return root
+}
+
+// splitFormKey 把字段名拆分为路径，无法识别的写法按原字段名处理
+func splitFormKey(key string) []string {
+	var (
+		path    []string
+		current strings.Builder
+	)
+	for i := 0; i < len(key); i++ {
+		switch key[i] {
+		case '.':
+			if current.Len() == 0 {
+				return []string{key}
+			}
+			path = append(path, current.String())
+			current.Reset()
+		case '[':
+			end := strings.IndexByte(key[i:], ']')
+			if end < 0 || (current.Len() == 0 && len(path) == 0) {
+				return []string{key}
+			}
+			if current.Len() > 0 {
+				path = append(path, current.String())
+				current.Reset()
+			}
+			path = append(path, key[i+1:i+end])
+			i += end
+			// 右括号后只能是 [、. 或结束
+			if i+1 < len(key) && key[i+1] != '[' && key[i+1] != '.' {
+				return []string{key}
+			}
+			if i+1 < len(key) && key[i+1] == '.' {
+				i++
+				if i+1 >= len(key) {
+					return []string{key}
+				}
+			}
+		default:
+			current.WriteByte(key[i])
+		}
+	}
+	if current.Len() > 0 {
+		path = append(path, current.String())
+	} else if len(path) == 0 || strings.HasSuffix(key, ".") {
+		return []string{key}
+	}
+	return path
+}
+
+// setFormValue 按路径设置值，空路径段表示追加新元素
+func setFormValue(m map[string]any, path []string, vals []string) {
+	for _, seg := range path[:len(path)-1] {
+		if seg == "" {
+			seg = strconv.Itoa(len(m))
+		}
+		child, ok := m[seg].(map[string]any)
+		if !ok {
+			child = make(map[string]any)
+			m[seg] = child
+		}
+		m = child
+	}
+
+	last := path[len(path)-1]
+	if last == "" {
+		for _, v := range vals {
+			m[strconv.Itoa(len(m))] = v
+		}
+		return
+	}
+	if len(vals) == 1 {
+		m[last] = vals[0]
+		return
+	}
+	list := make([]any, len(vals))
+	for i, v := range vals {
+		list[i] = v
+	}
+	m[last] = list
+}
+
+// normalizeFormArrays 把键为连续下标（从 0 开始）的 map 转换为列表
+func normalizeFormArrays(v any) any {
+	m, ok := v.(map[string]any)
+	if !ok {
+		return v
+	}
+	for k, child := range m {
+		m[k] = normalizeFormArrays(child)
+	}
+
+	if len(m) == 0 {
+		return m
+	}
+	list := make([]any, len(m))
+	for k, child := range m {
+		i, err := strconv.Atoi(k)
+		if err != nil || i < 0 || i >= len(m) || strconv.Itoa(i) != k {
+			return m
+		}
+		list[i] = child
+	}
+	return list
+}
@@ -0,0 +1,96 @@
+package utils
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// xmlNode 解析过程中的 XML 元素
+type xmlNode struct {
+	fields map[string]any
+	text   strings.Builder
+}
+
+// ParseXML 把 XML 文档解析为 map，根元素的子元素放在顶层
+// 同名子元素合并为列表，属性以 @ 为前缀，同时包含子元素和文本的元素文本放在 #text 下
+func ParseXML(data []byte) (map[string]any, error) {
+	decoder := xml.NewDecoder(bytes.NewReader(data))
+	// 忽略编码声明，按 UTF-8 处理
+	decoder.CharsetReader = func(charset string, input io.Reader) (io.Reader, error) {
+		return input, nil
+	}
+
+	var (
+		stack []*xmlNode
+		names []string
+		root  any
+	)
+	for {
+		token, err := decoder.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("解析 XML 失败: %w", err)
+		}
+
+		switch t := token.(type) {
+		case xml.StartElement:
+			node := &xmlNode{fields: make(map[string]any)}
+			for _, attr := range t.Attr {
+				node.fields["@"+attr.Name.Local] = attr.Value
+			}
+			stack = append(stack, node)
+			names = append(names, t.Name.Local)
+		case xml.CharData:
+			if len(stack) > 0 {
+				stack[len(stack)-1].text.Write(t)
+			}
+		case xml.EndElement:
+			if len(stack) == 0 {
+				return nil, fmt.Errorf("解析 XML 失败: 元素 %s 未闭合", t.Name.Local)
+			}
+			node := stack[len(stack)-1]
+			name := names[len(names)-1]
+			stack, names = stack[:len(stack)-1], names[:len(names)-1]
+
+			value := node.value()
+			if len(stack) == 0 {
+				root = value
+				continue
+			}
+			parent := stack[len(stack)-1].fields
+			switch existing := parent[name].(type) {
+			case nil:
+				parent[name] = value
+			case []any:
+				parent[name] = append(existing, value)
+			default:
+				parent[name] = []any{existing, value}
+			}
+		}
+	}
+
+	switch r := root.(type) {
+	case map[string]any:
+		return r, nil
+	case string:
+		return map[string]any{"#text": r}, nil
+	}
+	return nil, fmt.Errorf("解析 XML 失败: 缺少根元素")
+}
+
+// value 返回元素的值，只有文本的元素返回字符串
+func (n *xmlNode) value() any {
+	text := strings.TrimSpace(n.text.String())
+	if len(n.fields) == 0 {
+		return text
+	}
+	if text != "" {
+		n.fields["#text"] = text
+	}
+	return n.fields
+}